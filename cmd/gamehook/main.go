@@ -7,9 +7,12 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -34,10 +37,24 @@ var (
 type EnhancedGameHook struct {
 	config        *config.Config
 	driver        drivers.Driver
+	supervisor    *drivers.Supervisor
 	memory        *memory.Manager
 	mappers       *mappers.Loader
 	currentMapper *mappers.Mapper
+	mapperName    string // Loader name of currentMapper
+	router        *drivers.DomainRouter
 	server        *server.Server
+	recorder      atomic.Pointer[drivers.SessionRecorder] // nil when not recording
+	snapshots     *memory.SnapshotStore
+	readPlan      *mappers.ReadPlan // nil when reading whole memory blocks
+	appliedPlan   *mappers.ReadPlan // Plan whose ranges the memory manager holds, nil for whole blocks
+	planMu        sync.RWMutex
+	wholeUntil    atomic.Int64      // Unix nanoseconds until which whole blocks are read despite the plan
+	wholeLoaded   atomic.Bool       // The memory manager holds whole blocks
+	detector      *mappers.Detector // nil when detection is disabled
+	detection     *mappers.DetectionResult
+	detectionMu   sync.RWMutex
+	history       *memory.HistoryStore // nil when history is disabled
 	ctx           context.Context
 	cancel        context.CancelFunc
 
@@ -153,6 +170,15 @@ Features:
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "config file path")
 	rootCmd.Flags().String("host", "0.0.0.0", "server host")
 	rootCmd.Flags().Int("port", 8080, "server port")
+	rootCmd.Flags().String("driver", "retroarch", "emulator driver (retroarch, bizhawk, gdb, nwa, usb2snes, process, file, replay)")
+	rootCmd.Flags().String("dump", "", "memory dump file or directory for the file driver")
+	rootCmd.Flags().Bool("write-back", false, "persist file driver writes to the dump")
+	rootCmd.Flags().Int("pid", 0, "attach the process driver to this PID")
+	rootCmd.Flags().String("process", "", "attach the process driver to the process with this name")
+	rootCmd.Flags().Bool("record", false, "record every memory read to a session file")
+	rootCmd.Flags().String("replay", "", "replay a recorded session instead of connecting to an emulator")
+	rootCmd.Flags().Float64("replay-speed", 1.0, "replay speed multiplier (0 = one frame per update)")
+	rootCmd.Flags().Bool("replay-step", false, "advance the replay only via /api/replay/step")
 	rootCmd.Flags().String("retroarch-host", "127.0.0.1", "RetroArch host")
 	rootCmd.Flags().Int("retroarch-port", 55355, "RetroArch port")
 	rootCmd.Flags().Duration("update-interval", 16*time.Millisecond, "property update interval (60fps)")
@@ -448,7 +474,28 @@ func runEnhancedGameHook(cmd *cobra.Command, args []string) error {
 
 	log.Printf("🎮 Starting Enhanced GameHook v%s", Version)
 	log.Printf("🌐 Web server: http://localhost:%d", cfg.Server.Port)
-	log.Printf("🎯 RetroArch: %s:%d", cfg.RetroArch.Host, cfg.RetroArch.Port)
+	switch cfg.Driver {
+	case "bizhawk":
+		log.Printf("🎯 BizHawk: %s", drivers.SharedMemoryPath(cfg.BizHawk.DataMapName))
+	case "gdb":
+		log.Printf("🎯 GDB stub: %s:%d", cfg.GDB.Host, cfg.GDB.Port)
+	case "nwa":
+		log.Printf("🎯 NWA emulator: %s:%d", cfg.NWA.Host, cfg.NWA.Port)
+	case "usb2snes":
+		log.Printf("🎯 usb2snes: %s", cfg.Usb2snes.URL)
+	case "process":
+		if cfg.Process.PID != 0 {
+			log.Printf("🎯 Process: pid %d", cfg.Process.PID)
+		} else {
+			log.Printf("🎯 Process: %s", cfg.Process.Name)
+		}
+	case "file":
+		log.Printf("🎯 Memory dump: %s (offline)", cfg.File.DumpPath)
+	case "replay":
+		log.Printf("🎯 Replay: %s (speed %.1fx, stepwise: %v)", cfg.Replay.Path, cfg.Replay.Speed, cfg.Replay.Stepwise)
+	default:
+		log.Printf("🎯 RetroArch: %s:%d", cfg.RetroArch.Host, cfg.RetroArch.Port)
+	}
 	log.Printf("📁 Mappers: %s", cfg.Paths.MappersDir)
 	log.Printf("🎨 UIs: %s", cfg.Paths.UIsDir)
 	log.Printf("⚡ Update rate: %v (%.1f fps)", cfg.Performance.UpdateInterval,
@@ -475,6 +522,39 @@ func runEnhancedGameHook(cmd *cobra.Command, args []string) error {
 }
 
 func overrideConfigFromFlags(cmd *cobra.Command, cfg *config.Config) {
+	if cmd.Flags().Changed("driver") {
+		cfg.Driver, _ = cmd.Flags().GetString("driver")
+	}
+	if cmd.Flags().Changed("dump") {
+		cfg.File.DumpPath, _ = cmd.Flags().GetString("dump")
+	}
+	if cmd.Flags().Changed("write-back") {
+		cfg.File.WriteBack, _ = cmd.Flags().GetBool("write-back")
+	}
+	if cmd.Flags().Changed("pid") {
+		cfg.Process.PID, _ = cmd.Flags().GetInt("pid")
+	}
+	if cmd.Flags().Changed("process") {
+		cfg.Process.Name, _ = cmd.Flags().GetString("process")
+	}
+	if (cmd.Flags().Changed("pid") || cmd.Flags().Changed("process")) && !cmd.Flags().Changed("driver") {
+		cfg.Driver = "process"
+	}
+	if cmd.Flags().Changed("record") {
+		cfg.Recording.Enabled, _ = cmd.Flags().GetBool("record")
+	}
+	if cmd.Flags().Changed("replay") {
+		cfg.Replay.Path, _ = cmd.Flags().GetString("replay")
+		if !cmd.Flags().Changed("driver") {
+			cfg.Driver = "replay"
+		}
+	}
+	if cmd.Flags().Changed("replay-speed") {
+		cfg.Replay.Speed, _ = cmd.Flags().GetFloat64("replay-speed")
+	}
+	if cmd.Flags().Changed("replay-step") {
+		cfg.Replay.Stepwise, _ = cmd.Flags().GetBool("replay-step")
+	}
 	if cmd.Flags().Changed("port") {
 		cfg.Server.Port, _ = cmd.Flags().GetInt("port")
 	}
@@ -509,11 +589,11 @@ func NewEnhancedGameHook(cfg *config.Config) (*EnhancedGameHook, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Create enhanced driver
-	driver := drivers.NewAdaptiveRetroArchDriver(
-		cfg.RetroArch.Host,
-		cfg.RetroArch.Port,
-		cfg.RetroArch.RequestTimeout,
-	)
+	driver, err := createDriver(cfg)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 
 	// Create enhanced memory manager
 	memoryManager := memory.NewManager()
@@ -521,9 +601,11 @@ func NewEnhancedGameHook(cfg *config.Config) (*EnhancedGameHook, error) {
 	// Create enhanced mappers loader
 	mappersLoader := mappers.NewLoader(cfg.Paths.MappersDir)
 
+	maxRetries, retryDelay := cfg.ReconnectPolicy()
 	gameHook := &EnhancedGameHook{
 		config:           cfg,
 		driver:           driver,
+		supervisor:       drivers.NewSupervisor(driver, cfg.Driver, maxRetries, retryDelay),
 		memory:           memoryManager,
 		mappers:          mappersLoader,
 		ctx:              ctx,
@@ -538,15 +620,145 @@ func NewEnhancedGameHook(cfg *config.Config) (*EnhancedGameHook, error) {
 		eventTriggerChan: make(chan EventTrigger, 50),
 	}
 
+	snapshots, err := memory.NewSnapshotStore(filepath.Join(cfg.Paths.DataDir, "snapshots"))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	gameHook.snapshots = snapshots
+
+	if cfg.History.Enabled {
+		history, err := memory.NewHistoryStore(filepath.Join(cfg.Paths.DataDir, "history"), memory.HistoryRetention{
+			MaxAge:   cfg.History.Retention,
+			MaxBytes: int64(cfg.History.MaxSizeMB) << 20,
+		})
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		gameHook.history = history
+	}
+
+	// Start session recording if enabled
+	if cfg.Recording.Enabled {
+		if err := os.MkdirAll(cfg.Recording.Dir, 0755); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to create recording directory: %w", err)
+		}
+		path := filepath.Join(cfg.Recording.Dir, fmt.Sprintf("session-%s.ghrec", time.Now().Format("20060102-150405")))
+		recorder, err := drivers.NewSessionRecorder(path, cfg.Recording.KeyframeInterval)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		gameHook.recorder.Store(recorder)
+		log.Printf("📼 Recording session to %s", path)
+	}
+
+	// Detect the running game and load its mapper automatically. A replay holds
+	// only the recorded blocks and no ROM header, so its mapper is chosen by hand.
+	if cfg.Detection.Enabled && cfg.Driver != "replay" {
+		gameHook.detector = mappers.NewDetector(mappersLoader)
+	} else if cfg.Detection.Enabled {
+		log.Printf("🔎 Game detection is disabled for replays; load the recorded game's mapper explicitly")
+	}
+
 	// Create enhanced server
 	gameHook.server = server.New(gameHook, cfg.Paths.UIsDir, cfg.Server.Port)
 
 	// Setup enhanced memory change listener
 	memoryManager.AddChangeListener(gameHook.onMemoryChange)
 
+	// Report driver connection transitions
+	gameHook.supervisor.OnStatusChange(gameHook.onDriverStatusChange)
+
 	return gameHook, nil
 }
 
+// createDriver creates the emulator driver selected by the driver config key
+func createDriver(cfg *config.Config) (drivers.Driver, error) {
+	switch cfg.Driver {
+	case "", "retroarch":
+		driver := drivers.NewAdaptiveRetroArchDriver(
+			cfg.RetroArch.Host,
+			cfg.RetroArch.Port,
+			cfg.RetroArch.RequestTimeout,
+		)
+		driver.SetPipelineDepth(cfg.RetroArch.PipelineDepth)
+		return driver, nil
+	case "bizhawk":
+		return drivers.NewBizHawkDriver(
+			cfg.BizHawk.MemoryMapName,
+			cfg.BizHawk.DataMapName,
+			cfg.BizHawk.Timeout,
+		), nil
+	case "gdb":
+		return drivers.NewGDBDriver(
+			cfg.GDB.Host,
+			cfg.GDB.Port,
+			cfg.GDB.RequestTimeout,
+		), nil
+	case "nwa":
+		var domains []drivers.NWADomain
+		for _, domain := range cfg.NWA.Domains {
+			domains = append(domains, drivers.NWADomain{
+				Name:   domain.Domain,
+				Start:  domain.Start,
+				End:    domain.End,
+				Offset: domain.Offset,
+			})
+		}
+		return drivers.NewNWADriver(
+			cfg.NWA.Host,
+			cfg.NWA.Port,
+			cfg.NWA.RequestTimeout,
+			domains,
+		), nil
+	case "usb2snes":
+		var mappings []drivers.Usb2snesMapping
+		for _, mapping := range cfg.Usb2snes.Mappings {
+			mappings = append(mappings, drivers.Usb2snesMapping{
+				Start:  mapping.Start,
+				End:    mapping.End,
+				Target: mapping.Target,
+			})
+		}
+		return drivers.NewUsb2snesDriver(
+			cfg.Usb2snes.URL,
+			cfg.Usb2snes.Device,
+			cfg.Usb2snes.RequestTimeout,
+			mappings,
+		), nil
+	case "process":
+		return drivers.NewProcessDriver(
+			cfg.Process.PID,
+			cfg.Process.Name,
+			cfg.Process.BaseAddress,
+		), nil
+	case "file":
+		if cfg.File.DumpPath == "" {
+			return nil, fmt.Errorf("file driver requires a dump path (--dump)")
+		}
+		return drivers.NewFileDriver(
+			cfg.File.DumpPath,
+			cfg.File.BaseAddress,
+			cfg.File.WriteBack,
+		), nil
+	case "replay":
+		if cfg.Replay.Path == "" {
+			return nil, fmt.Errorf("replay driver requires a recording path (--replay)")
+		}
+		return drivers.NewReplayDriver(
+			cfg.Replay.Path,
+			cfg.Replay.Speed,
+			cfg.Replay.Stepwise,
+			cfg.Replay.Loop,
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown driver: %s", cfg.Driver)
+	}
+}
+
 // Run starts the enhanced GameHook application
 func (gh *EnhancedGameHook) Run() error {
 	// Start enhanced update loop
@@ -555,6 +767,16 @@ func (gh *EnhancedGameHook) Run() error {
 	// Start batch operations processor
 	go gh.processBatchOperations()
 
+	// Start change pattern analysis if enabled
+	if gh.config.PropertyMonitoring.PatternDetection {
+		go gh.analyzePatterns()
+	}
+
+	// Start writing property history if enabled
+	if gh.history != nil {
+		go gh.flushHistory()
+	}
+
 	// Start event system if enabled
 	if gh.config.Events.Enabled {
 		go gh.processEvents()
@@ -592,10 +814,24 @@ func (gh *EnhancedGameHook) Run() error {
 		log.Printf("⚠️  Server shutdown error: %v", err)
 	}
 
-	if err := gh.driver.Close(); err != nil {
+	if err := gh.supervisor.Close(); err != nil {
 		log.Printf("⚠️  Driver close error: %v", err)
 	}
 
+	if gh.history != nil {
+		if err := gh.history.Close(); err != nil {
+			log.Printf("⚠️  History close error: %v", err)
+		}
+	}
+
+	if recorder := gh.recorder.Swap(nil); recorder != nil {
+		if err := recorder.Close(); err != nil {
+			log.Printf("⚠️  Recording close error: %v", err)
+		} else {
+			log.Printf("📼 Recorded %d frames to %s", recorder.FrameCount(), recorder.Path())
+		}
+	}
+
 	log.Println("✅ Enhanced shutdown complete")
 	return nil
 }
@@ -606,11 +842,9 @@ func (gh *EnhancedGameHook) enhancedUpdateLoop() {
 	defer ticker.Stop()
 
 	lastErrorLog := time.Time{}
-	consecutiveErrors := 0
-	lastFrozenApply := time.Now()
 	lastSuccessfulRead := time.Time{}
-	connectionTestInterval := 30 * time.Second
-	lastConnectionTest := time.Time{}
+	lastDetection := time.Time{}
+	wasConnected := false
 
 	log.Printf("🔄 Starting enhanced update loop with %v interval", gh.config.Performance.UpdateInterval)
 
@@ -620,42 +854,29 @@ func (gh *EnhancedGameHook) enhancedUpdateLoop() {
 			log.Printf("🛑 Enhanced update loop stopping...")
 			return
 		case <-ticker.C:
-			if gh.currentMapper != nil {
-				// Test RetroArch connection periodically
-				if time.Since(lastConnectionTest) > connectionTestInterval {
-					if err := gh.TestRetroArchConnection(); err != nil {
-						log.Printf("🔧 RetroArch connection test failed: %v", err)
-					}
-					lastConnectionTest = time.Now()
-				}
+			// Check for content changes and load the matching mapper
+			if gh.detector != nil && time.Since(lastDetection) >= gh.config.Detection.Interval {
+				gh.detectGame()
+				lastDetection = time.Now()
+			}
 
+			if gh.currentMapper != nil {
 				if err := gh.updateMemoryWithEnhancements(); err != nil {
-					consecutiveErrors++
-
-					// More detailed error logging
-					if consecutiveErrors == 1 {
-						log.Printf("⚠️  First memory update error: %v", err)
-						log.Printf("🔍 This might indicate RetroArch is not running or memory API is disabled")
-					} else if consecutiveErrors == 10 {
-						log.Printf("⚠️  10 consecutive memory errors - checking connection...")
-						gh.TestRetroArchConnection()
-					} else if consecutiveErrors%50 == 0 {
-						log.Printf("⚠️  %d consecutive memory errors", consecutiveErrors)
-					}
-
-					if consecutiveErrors == 1 || time.Since(lastErrorLog) > 30*time.Second {
+					wasConnected = false
+					if time.Since(lastErrorLog) > 30*time.Second {
 						log.Printf("⚠️  Enhanced memory update error: %v", err)
-						log.Printf("💡 Make sure RetroArch is running with network commands enabled")
-						log.Printf("💡 Check Settings > Network > Network Commands: ON")
+						if gh.config.Driver == "retroarch" {
+							log.Printf("💡 Make sure RetroArch is running with network commands enabled")
+							log.Printf("💡 Check Settings > Network > Network Commands: ON")
+						}
 						lastErrorLog = time.Now()
 					}
 				} else {
-					if consecutiveErrors > 0 {
-						log.Printf("✅ Enhanced RetroArch connection restored after %d errors", consecutiveErrors)
-						consecutiveErrors = 0
+					if !wasConnected {
+						wasConnected = true
 						lastSuccessfulRead = time.Now()
 
-						// Test property reading after successful connection restore
+						// Test property reading after the connection is (re)established
 						gh.TestPropertyReading()
 					} else if time.Since(lastSuccessfulRead) > 10*time.Second {
 						// Periodically test property reading
@@ -664,12 +885,6 @@ func (gh *EnhancedGameHook) enhancedUpdateLoop() {
 					}
 				}
 
-				// Apply frozen properties more frequently
-				if time.Since(lastFrozenApply) > 100*time.Millisecond {
-					gh.applyFrozenProperties()
-					lastFrozenApply = time.Now()
-				}
-
 				// Update property states and detect changes
 				gh.updatePropertyStates()
 			} else {
@@ -689,23 +904,136 @@ func (gh *EnhancedGameHook) updateMemoryWithEnhancements() error {
 		return nil
 	}
 
-	// Read memory blocks
-	memoryData, err := gh.driver.ReadMemoryBlocks(gh.currentMapper.Platform.MemoryBlocks)
-	if err != nil {
-		return fmt.Errorf("memory read failed: %w", err)
+	// Only read the ranges the mapper's properties touch when a plan exists
+	gh.planMu.RLock()
+	plan := gh.readPlan
+	gh.planMu.RUnlock()
+
+	// The heatmap and captures need every byte, so reading whole blocks suspends the plan while they are in use
+	blocks := gh.currentMapper.Platform.MemoryBlocks
+	wholeBlocks := plan == nil || time.Now().UnixNano() < gh.wholeUntil.Load()
+	if !wholeBlocks {
+		blocks = plan.Blocks()
+	}
+
+	// A replay releases at most one recorded frame per tick, shared by every domain read
+	if replay, ok := gh.driver.(*drivers.ReplayDriver); ok {
+		if err := replay.Tick(); err != nil {
+			return fmt.Errorf("replay failed: %w", err)
+		}
+	}
+
+	// Read each memory domain separately, system bus first
+	recorder := gh.recorder.Load()
+	frame := make(drivers.FrameBlocks)
+	domains, blocksByDomain := groupBlocksByDomain(blocks)
+	for _, domain := range domains {
+		requested, offset, err := gh.router.Resolve(domain, blocksByDomain[domain])
+		if err != nil {
+			return fmt.Errorf("memory domain %s: %w", domain, err)
+		}
+
+		// Read memory blocks through the supervisor so failures drive reconnects
+		memoryData, err := gh.supervisor.ReadMemoryBlocks(requested)
+		if err != nil {
+			return fmt.Errorf("memory read failed: %w", err)
+		}
+
+		if domain != "" {
+			memoryData = drivers.Rekey(memoryData, offset)
+			gh.memory.UpdateDomain(domain, memoryData)
+		} else {
+			gh.memory.Update(memoryData)
+		}
+		frame[domain] = memoryData
+	}
+
+	// Record the raw reads of every domain as one frame, before frozen values are applied
+	if recorder != nil {
+		// A record failing because shutdown already took the recorder is not reported
+		if err := recorder.Record(frame); err != nil && gh.recorder.CompareAndSwap(recorder, nil) {
+			log.Printf("⚠️  Session recording failed, stopping recorder: %v", err)
+			if err := recorder.Close(); err != nil {
+				log.Printf("⚠️  Recording close error: %v", err)
+			}
+		}
+	}
+
+	// Record watched changes before freeze corrections write over them
+	gh.checkWatchpoints()
+
+	// Correct frozen properties that drifted in this read before anything evaluates them
+	gh.enforceFreezes()
+
+	if plan != nil {
+		// Drop blocks from the previous layout so they cannot shadow the new ranges
+		applied := plan
+		if wholeBlocks {
+			applied = nil
+		}
+		if applied != gh.appliedPlan {
+			for _, domain := range plan.Domains() {
+				if applied != nil {
+					gh.memory.RetainBlocks(domain, applied.Lengths(domain))
+				} else {
+					gh.memory.RetainBlocks(domain, blockLengths(blocks, domain))
+				}
+			}
+			gh.appliedPlan = applied
+		}
+	}
+	gh.wholeLoaded.Store(wholeBlocks)
+
+	if plan != nil {
+		// Follow pointers so their targets are read on the next tick
+		if refreshed := gh.currentMapper.RefreshReadPlan(plan, gh.memory); refreshed != plan {
+			gh.planMu.Lock()
+			if gh.readPlan == plan {
+				gh.readPlan = refreshed
+			}
+			gh.planMu.Unlock()
+		}
 	}
 
-	// Update memory manager (this will trigger frozen property application)
-	gh.memory.Update(memoryData)
 	return nil
 }
 
+// blockLengths returns the blocks in domain as start address → length
+func blockLengths(blocks []types.MemoryBlock, domain string) map[uint32]uint32 {
+	lengths := make(map[uint32]uint32, len(blocks))
+	for _, block := range blocks {
+		if block.Domain == domain {
+			lengths[block.Start] = block.End - block.Start + 1
+		}
+	}
+	return lengths
+}
+
+// groupBlocksByDomain splits blocks by memory domain, keeping the system bus first
+func groupBlocksByDomain(blocks []types.MemoryBlock) ([]string, map[string][]types.MemoryBlock) {
+	var domains []string
+	byDomain := make(map[string][]types.MemoryBlock)
+	for _, block := range blocks {
+		if _, seen := byDomain[block.Domain]; !seen {
+			domains = append(domains, block.Domain)
+		}
+		byDomain[block.Domain] = append(byDomain[block.Domain], block)
+	}
+	sort.SliceStable(domains, func(i, j int) bool {
+		return domains[i] == "" && domains[j] != ""
+	})
+	return domains, byDomain
+}
+
 // updatePropertyStates with controlled concurrency
 func (gh *EnhancedGameHook) updatePropertyStates() {
 	if gh.currentMapper == nil {
 		return
 	}
 
+	// Every property in a tick is evaluated against the same snapshot
+	snap := gh.memory.Snapshot()
+
 	// Use a worker pool to read properties concurrently but controlled
 	const maxWorkers = 5
 	propertyNames := make([]string, 0, len(gh.currentMapper.Properties))
@@ -733,7 +1061,7 @@ func (gh *EnhancedGameHook) updatePropertyStates() {
 		go func() {
 			defer wg.Done()
 			for name := range propChan {
-				if value, err := gh.currentMapper.GetProperty(name, gh.memory); err == nil {
+				if value, err := gh.currentMapper.GetPropertyAt(name, gh.memory, snap); err == nil {
 					select {
 					case resultChan <- struct {
 						name  string
@@ -759,6 +1087,9 @@ func (gh *EnhancedGameHook) updatePropertyStates() {
 		if lastValue, exists := gh.lastSnapshot[result.name]; !exists || !gh.deepEqual(lastValue, result.value) {
 			changes[result.name] = result.value
 			gh.lastSnapshot[result.name] = result.value
+			if gh.history != nil {
+				gh.history.Record(gh.mapperName, result.name, snap.CreatedAt, result.value)
+			}
 		}
 	}
 
@@ -790,6 +1121,49 @@ func (gh *EnhancedGameHook) processEvents() {
 	}
 }
 
+// analyzePatterns periodically detects change patterns in property value
+// histories and announces newly confirmed ones
+func (gh *EnhancedGameHook) analyzePatterns() {
+	ticker := time.NewTicker(gh.config.PropertyMonitoring.PatternInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-gh.ctx.Done():
+			return
+		case <-ticker.C:
+			for _, pattern := range gh.memory.AnalyzePatterns(gh.config.PropertyMonitoring.PatternConfidence) {
+				log.Printf("🔍 Pattern confirmed for %s: %s (%.0f%%)", pattern.PropertyName, pattern.Description, pattern.Confidence*100)
+				if gh.server != nil {
+					gh.server.Broadcast(map[string]interface{}{
+						"type":      "property_pattern_detected",
+						"property":  pattern.PropertyName,
+						"pattern":   pattern,
+						"timestamp": time.Now(),
+					})
+				}
+			}
+		}
+	}
+}
+
+// flushHistory periodically writes recorded property values to disk
+func (gh *EnhancedGameHook) flushHistory() {
+	ticker := time.NewTicker(gh.config.History.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-gh.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := gh.history.Flush(); err != nil {
+				log.Printf("⚠️  Property history flush failed: %v", err)
+			}
+		}
+	}
+}
+
 func (gh *EnhancedGameHook) processEventTriggers() {
 	for {
 		select {
@@ -1053,20 +1427,52 @@ func createRetroArchTestCommand() *cobra.Command {
 	}
 }
 
-// applyFrozenProperties applies frozen property values
-func (gh *EnhancedGameHook) applyFrozenProperties() {
+// enforceFreezes ends expired freezes, then writes frozen values back to the
+// emulator for every frozen property that drifted in the latest read,
+// coalescing adjacent corrections
+func (gh *EnhancedGameHook) enforceFreezes() {
 	if gh.currentMapper == nil {
 		return
 	}
 
-	for name, prop := range gh.currentMapper.Properties {
-		if prop.Frozen && len(prop.FrozenData) > 0 {
-			// Write frozen data back to emulator
-			if err := gh.driver.WriteBytes(prop.Address, prop.FrozenData); err != nil {
-				log.Printf("⚠️  Failed to apply frozen property %s: %v", name, err)
-			}
+	snap := gh.memory.Snapshot()
+	for _, name := range gh.currentMapper.ExpireFreezes(gh.memory, snap) {
+		log.Printf("⏰ Freeze on %s expired", name)
+		if gh.server != nil {
+			gh.server.Broadcast(map[string]interface{}{
+				"type":      "property_freeze_expired",
+				"property":  name,
+				"frozen":    false,
+				"frame":     snap.Frame,
+				"timestamp": time.Now(),
+			})
 		}
 	}
+
+	for name, err := range gh.currentMapper.ApplyFreezeCorrections(gh.memory, snap, gh.router.WriteBytes) {
+		log.Printf("⚠️  Failed to enforce frozen property %s: %v", name, err)
+	}
+}
+
+// checkWatchpoints records the watched ranges that changed in the latest read
+// and streams the hits
+func (gh *EnhancedGameHook) checkWatchpoints() {
+	mapper := gh.currentMapper
+	snap := gh.memory.Snapshot()
+
+	hits := gh.memory.CheckWatchpoints(snap, func(condition string) (bool, error) {
+		return mapper.EvaluateCondition(condition, gh.memory, snap)
+	})
+
+	if gh.server == nil {
+		return
+	}
+	for _, hit := range hits {
+		gh.server.Broadcast(map[string]interface{}{
+			"type": "watchpoint_hit",
+			"hit":  hit,
+		})
+	}
 }
 
 // onMemoryChange handles memory change events
@@ -1194,11 +1600,22 @@ func (gh *EnhancedGameHook) LoadMapper(name string) error {
 		return err
 	}
 
+	gh.router = drivers.NewDomainRouter(gh.driver, mapper.DomainBusAddresses())
+	gh.memory.SetBankedWindows(mapper.BankedWindows)
 	gh.currentMapper = mapper
+	gh.mapperName = name
+
+	// The mapper files may have changed, so detection inspects them again
+	if gh.detector != nil {
+		gh.detector.Reset()
+	}
 	log.Printf("📍 Loaded enhanced mapper: %s (%s) v%s", mapper.Name, mapper.Game, mapper.Version)
 	log.Printf("🎮 Platform: %s (%s endian)", mapper.Platform.Name, mapper.Platform.Endian)
 	log.Printf("📊 Properties: %d defined, %d groups, %d computed",
 		len(mapper.Properties), len(mapper.Groups), len(mapper.Computed))
+	if len(mapper.BankedWindows) > 0 {
+		log.Printf("🏦 Tracking %d bank-switched windows", len(mapper.BankedWindows))
+	}
 
 	// Apply default frozen states
 	frozenCount := 0
@@ -1216,12 +1633,46 @@ func (gh *EnhancedGameHook) LoadMapper(name string) error {
 		log.Printf("🧊 Applied default freeze to %d properties", frozenCount)
 	}
 
+	// Plan the minimal set of ranges to read for this mapper
+	var plan *mappers.ReadPlan
+	if gh.config.Performance.ReadPlanning {
+		plan = mapper.BuildReadPlan(gh.config.Performance.ReadPlanGap)
+		log.Printf("📐 Read plan: %d ranges, %d of %d bytes (gap %d)",
+			len(plan.Ranges), plan.PlannedBytes, plan.FullBytes, plan.Gap)
+		if len(plan.Unplanned) > 0 {
+			log.Printf("⚠️  Properties outside all memory blocks: %v", plan.Unplanned)
+		}
+
+		// Keep reading the ranges of watchpoints set before this mapper loaded
+		plan = plan.WithWatchpoints(gh.memory.ListWatchpoints())
+	}
+	gh.planMu.Lock()
+	gh.readPlan = plan
+	gh.planMu.Unlock()
+	gh.wholeLoaded.Store(false)
+
 	// Configure the adaptive driver for this platform
 	if adaptiveDriver, ok := gh.driver.(*drivers.AdaptiveRetroArchDriver); ok {
 		adaptiveDriver.SetPlatform(mapper.Platform.Name)
 		log.Printf("🔧 Configured driver for platform: %s", mapper.Platform.Name)
 	}
 
+	// Tell the process driver where emulated RAM lives for this platform
+	if processDriver, ok := gh.driver.(*drivers.ProcessDriver); ok {
+		if layout := mapper.Platform.ProcessMemory; layout != nil {
+			processDriver.SetLocator(&drivers.RAMLocator{
+				Module:          layout.Module,
+				Signature:       layout.Signature,
+				SignatureOffset: layout.SignatureOffset,
+				Offset:          layout.Offset,
+				EmulatedStart:   layout.EmulatedStart,
+			})
+		} else {
+			processDriver.SetLocator(nil)
+			log.Printf("⚠️  Mapper %s does not declare platform.processMemory", mapper.Name)
+		}
+	}
+
 	return nil
 }
 
@@ -1280,13 +1731,17 @@ func (gh *EnhancedGameHook) SetPropertyBytes(name string, data []byte) error {
 		return fmt.Errorf("property %s is read-only", name)
 	}
 
+	if err := gh.currentMapper.CheckBankMapped(prop, gh.memory); err != nil {
+		return err
+	}
+
 	// Write bytes directly
-	if err := gh.driver.WriteBytes(prop.Address, data); err != nil {
+	if err := gh.router.WriteBytes(prop.Domain, prop.Address, data); err != nil {
 		return err
 	}
 
 	// Update internal memory
-	gh.memory.WriteBytes(prop.Address, data)
+	gh.memory.WriteDomainBytes(prop.Domain, prop.Address, data)
 
 	return nil
 }
@@ -1303,10 +1758,27 @@ func (gh *EnhancedGameHook) FreezeProperty(name string, freeze bool) error {
 	}
 }
 
+// FreezePropertyWithOptions freezes a property that only holds while a
+// condition is true or that expires after a duration or number of frames
+func (gh *EnhancedGameHook) FreezePropertyWithOptions(name string, options *mappers.FreezeOptions) error {
+	if gh.currentMapper == nil {
+		return fmt.Errorf("no mapper loaded")
+	}
+
+	return gh.currentMapper.FreezePropertyWithOptions(name, gh.memory, options)
+}
+
 func (gh *EnhancedGameHook) ListMappers() []string {
 	return gh.mappers.List()
 }
 
+func (gh *EnhancedGameHook) GetPropertyBankStatus(name string) *mappers.BankStatus {
+	if gh.currentMapper == nil {
+		return nil
+	}
+	return gh.currentMapper.BankStatus(name, gh.memory)
+}
+
 func (gh *EnhancedGameHook) GetPropertyState(name string) interface{} {
 	return gh.memory.GetPropertyState(name)
 }
@@ -1320,13 +1792,16 @@ func (gh *EnhancedGameHook) GetAllPropertyStates() map[string]interface{} {
 	return result
 }
 
-func (gh *EnhancedGameHook) GetPropertyChanges() map[string]interface{} {
+// GetPropertyChanges returns the properties whose values changed, all read
+// from one memory snapshot, along with that snapshot's sequence number
+func (gh *EnhancedGameHook) GetPropertyChanges() (map[string]interface{}, uint64) {
 	// Return recent changes (this could be enhanced with a proper change buffer)
 	changes := make(map[string]interface{})
+	snap := gh.memory.Snapshot()
 
 	if gh.currentMapper != nil {
 		for name := range gh.currentMapper.Properties {
-			if value, err := gh.GetProperty(name); err == nil {
+			if value, err := gh.currentMapper.GetPropertyAt(name, gh.memory, snap); err == nil {
 				if lastValue, exists := gh.lastSnapshot[name]; !exists || !gh.deepEqual(lastValue, value) {
 					changes[name] = value
 				}
@@ -1334,7 +1809,12 @@ func (gh *EnhancedGameHook) GetPropertyChanges() map[string]interface{} {
 		}
 	}
 
-	return changes
+	return changes, snap.Sequence
+}
+
+// GetSnapshotSequence returns the sequence number of the latest memory snapshot
+func (gh *EnhancedGameHook) GetSnapshotSequence() uint64 {
+	return gh.memory.Snapshot().Sequence
 }
 
 func (gh *EnhancedGameHook) GetMapperMeta() interface{} {
@@ -1343,8 +1823,8 @@ func (gh *EnhancedGameHook) GetMapperMeta() interface{} {
 	}
 
 	frozenCount := 0
-	for _, prop := range gh.currentMapper.Properties {
-		if prop.Frozen {
+	for name := range gh.currentMapper.Properties {
+		if gh.currentMapper.IsFrozen(name) {
 			frozenCount++
 		}
 	}
@@ -1468,6 +1948,553 @@ func (gh *EnhancedGameHook) GetValidationErrors() map[string]interface{} {
 	return result
 }
 
+// GetRecordingStatus returns the state of the session recorder
+func (gh *EnhancedGameHook) GetRecordingStatus() map[string]interface{} {
+	recorder := gh.recorder.Load()
+	if recorder == nil {
+		return map[string]interface{}{"recording": false}
+	}
+
+	return map[string]interface{}{
+		"recording": true,
+		"path":      recorder.Path(),
+		"frames":    recorder.FrameCount(),
+	}
+}
+
+// GetDriverStatus returns the supervised driver connection state
+func (gh *EnhancedGameHook) GetDriverStatus() interface{} {
+	return gh.supervisor.Status()
+}
+
+// onDriverStatusChange logs connection transitions and pushes them to WebSocket clients
+func (gh *EnhancedGameHook) onDriverStatusChange(status drivers.DriverStatus) {
+	switch status.State {
+	case drivers.StateConnecting:
+		log.Printf("🔌 Connecting to %s driver...", status.Driver)
+	case drivers.StateConnected:
+		if status.PreviousState == drivers.StateDegraded {
+			log.Printf("✅ Driver %s recovered", status.Driver)
+		} else {
+			log.Printf("✅ Driver %s connected", status.Driver)
+		}
+	case drivers.StateDegraded:
+		log.Printf("⚠️  Driver %s degraded: %s", status.Driver, status.LastError)
+	case drivers.StateDisconnected:
+		if status.NextRetry != nil {
+			log.Printf("❌ Driver %s disconnected: %s (retry in %v)",
+				status.Driver, status.LastError, time.Until(*status.NextRetry).Round(time.Millisecond))
+		} else {
+			log.Printf("❌ Driver %s disconnected", status.Driver)
+		}
+	}
+
+	if gh.server != nil {
+		gh.server.Broadcast(map[string]interface{}{
+			"type":      "driver_status",
+			"status":    status,
+			"timestamp": time.Now(),
+		})
+	}
+}
+
+// detectGame fingerprints the running content and loads the matching mapper when the content changes
+func (gh *EnhancedGameHook) detectGame() {
+	if err := gh.supervisor.EnsureConnected(); err != nil {
+		return
+	}
+
+	result, err := gh.detector.Detect(gh.driver)
+	if err != nil {
+		// Connection problems are reported by the update loop
+		return
+	}
+
+	gh.detectionMu.Lock()
+	previous := gh.detection
+	gh.detection = result
+	gh.detectionMu.Unlock()
+
+	if previous != nil && previous.Fingerprint.Key() == result.Fingerprint.Key() {
+		return
+	}
+
+	fp := result.Fingerprint
+	if fp.Contentless {
+		log.Printf("🔎 No content running")
+		return
+	}
+
+	description := fp.Content
+	if description == "" {
+		description = fp.Title
+	}
+	if result.Mapper == "" {
+		if description != "" {
+			log.Printf("🔎 Detected %s, but no mapper declares matching identification", description)
+		}
+		return
+	}
+	if result.Mapper == gh.mapperName {
+		return
+	}
+
+	log.Printf("🔎 Detected %s (matched by %s), loading mapper %s", description, result.MatchedBy, result.Mapper)
+	if err := gh.LoadMapper(result.Mapper); err != nil {
+		log.Printf("⚠️  Failed to load detected mapper %s: %v", result.Mapper, err)
+		return
+	}
+
+	if gh.server != nil {
+		gh.server.Broadcast(map[string]interface{}{
+			"type":        "mapper_loaded",
+			"mapper":      result.Mapper,
+			"source":      "detection",
+			"matched_by":  result.MatchedBy,
+			"fingerprint": fp,
+			"timestamp":   time.Now(),
+		})
+	}
+}
+
+// GetDetection returns the last game detection result
+func (gh *EnhancedGameHook) GetDetection() interface{} {
+	gh.detectionMu.RLock()
+	defer gh.detectionMu.RUnlock()
+
+	return map[string]interface{}{
+		"enabled": gh.detector != nil,
+		"mapper":  gh.mapperName,
+		"result":  gh.detection,
+	}
+}
+
+// GetReadPlan returns the ranges read from the driver each tick
+func (gh *EnhancedGameHook) GetReadPlan() interface{} {
+	if gh.currentMapper == nil {
+		return nil
+	}
+
+	gh.planMu.RLock()
+	plan := gh.readPlan
+	gh.planMu.RUnlock()
+
+	if plan == nil {
+		return map[string]interface{}{
+			"enabled": false,
+			"blocks":  gh.currentMapper.Platform.MemoryBlocks,
+		}
+	}
+
+	return map[string]interface{}{
+		"enabled": true,
+		"plan":    plan,
+	}
+}
+
+// StartSearch starts a RAM search over the latest memory snapshot
+func (gh *EnhancedGameHook) StartSearch(options memory.SearchOptions) (interface{}, error) {
+	return gh.memory.StartSearch(options)
+}
+
+// FilterSearch narrows a RAM search's candidates
+func (gh *EnhancedGameHook) FilterSearch(id string, filter memory.SearchFilter) (interface{}, error) {
+	return gh.memory.FilterSearch(id, filter)
+}
+
+// GetSearch returns a page of a RAM search's candidates
+func (gh *EnhancedGameHook) GetSearch(id string, offset, limit int) (interface{}, error) {
+	return gh.memory.GetSearch(id, offset, limit)
+}
+
+// ListSearches returns the active RAM searches
+func (gh *EnhancedGameHook) ListSearches() interface{} {
+	return gh.memory.ListSearches()
+}
+
+// DeleteSearch ends a RAM search
+func (gh *EnhancedGameHook) DeleteSearch(id string) error {
+	return gh.memory.DeleteSearch(id)
+}
+
+// PromoteSearchCandidate returns a CUE snippet mapping a search candidate as a property
+func (gh *EnhancedGameHook) PromoteSearchCandidate(id string, address uint32, name string) (string, error) {
+	options, err := gh.memory.SearchCandidateOptions(id, address)
+	if err != nil {
+		return "", err
+	}
+
+	if gh.currentMapper != nil {
+		if _, exists := gh.currentMapper.Properties[name]; exists {
+			return "", fmt.Errorf("property %s already exists in mapper %s", name, gh.currentMapper.Name)
+		}
+	}
+
+	return mappers.FormatPropertySnippet(mappers.SearchCandidateProperty(name, address, options))
+}
+
+// captureLive captures the latest memory snapshot with every property value
+// decoded from it. Under a read plan it first waits for whole blocks, so the
+// capture holds unmapped memory too.
+func (gh *EnhancedGameHook) captureLive(name string) (*memory.SavedSnapshot, error) {
+	if err := gh.awaitWholeBlocks(); err != nil {
+		return nil, err
+	}
+
+	snap := gh.memory.Snapshot()
+	saved := snap.Capture(name)
+	saved.Mapper = gh.mapperName
+
+	if gh.currentMapper != nil {
+		saved.Properties = make(map[string]interface{}, len(gh.currentMapper.Properties))
+		for propName := range gh.currentMapper.Properties {
+			if value, err := gh.currentMapper.GetPropertyAt(propName, gh.memory, snap); err == nil {
+				saved.Properties[propName] = value
+			}
+		}
+	}
+	return saved, nil
+}
+
+// CaptureSnapshot saves loaded memory and property values under name. An
+// empty name is replaced with one based on the current time.
+func (gh *EnhancedGameHook) CaptureSnapshot(name string) (interface{}, error) {
+	if name == "" {
+		name = "snapshot-" + time.Now().Format("20060102-150405")
+	}
+
+	saved, err := gh.captureLive(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := gh.snapshots.Save(saved); err != nil {
+		return nil, err
+	}
+	return saved.Info(), nil
+}
+
+// ListSnapshots describes the saved snapshots
+func (gh *EnhancedGameHook) ListSnapshots() (interface{}, error) {
+	return gh.snapshots.List()
+}
+
+// GetSnapshot returns a saved snapshot with its memory and property values
+func (gh *EnhancedGameHook) GetSnapshot(name string) (interface{}, error) {
+	return gh.snapshots.Load(name)
+}
+
+// DeleteSnapshot removes a saved snapshot
+func (gh *EnhancedGameHook) DeleteSnapshot(name string) error {
+	return gh.snapshots.Delete(name)
+}
+
+// DiffSnapshots compares saved snapshot from with saved snapshot to, or with
+// live memory when to is empty
+func (gh *EnhancedGameHook) DiffSnapshots(from, to string) (interface{}, error) {
+	fromSnapshot, err := gh.snapshots.Load(from)
+	if err != nil {
+		return nil, err
+	}
+
+	var toSnapshot *memory.SavedSnapshot
+	if to == "" {
+		if toSnapshot, err = gh.captureLive("live"); err != nil {
+			return nil, err
+		}
+	} else if toSnapshot, err = gh.snapshots.Load(to); err != nil {
+		return nil, err
+	}
+
+	return memory.DiffSnapshots(fromSnapshot, toSnapshot), nil
+}
+
+// AddWatchpoint starts recording changes to an address range, or to the
+// bytes of wp.Property when it is set
+func (gh *EnhancedGameHook) AddWatchpoint(wp memory.Watchpoint) (interface{}, error) {
+	var prop *mappers.Property
+	if wp.Property != "" {
+		if gh.currentMapper == nil {
+			return nil, fmt.Errorf("no mapper loaded")
+		}
+		var exists bool
+		if prop, exists = gh.currentMapper.Properties[wp.Property]; !exists {
+			return nil, fmt.Errorf("property %s not found", wp.Property)
+		}
+		if prop.Computed != nil {
+			return nil, fmt.Errorf("computed property %s has no memory to watch", wp.Property)
+		}
+		wp.Domain, wp.Address, wp.Length = prop.Domain, prop.Address, prop.Length
+		if wp.Length == 0 {
+			wp.Length = 1
+		}
+	}
+
+	if gh.currentMapper != nil {
+		end := uint64(wp.Address) + uint64(wp.Length) - 1
+		for _, block := range gh.currentMapper.Platform.MemoryBlocks {
+			if block.Unwatchable && block.Domain == wp.Domain && uint64(block.Start) <= end && wp.Address <= block.End {
+				return nil, fmt.Errorf("memory block %s is not watchable", block.Name)
+			}
+		}
+	}
+
+	// Reject conditions that do not evaluate now rather than on the first change
+	if wp.Condition != "" {
+		if gh.currentMapper == nil {
+			return nil, fmt.Errorf("watch conditions need a loaded mapper")
+		}
+		if _, err := gh.currentMapper.EvaluateCondition(wp.Condition, gh.memory, gh.memory.Snapshot()); err != nil {
+			return nil, fmt.Errorf("invalid watch condition: %w", err)
+		}
+	}
+
+	added, err := gh.memory.AddWatchpoint(wp)
+	if err != nil {
+		return nil, err
+	}
+	if prop != nil {
+		gh.memory.SetPropertyWatch(prop.Name, prop.Address, true, wp.Condition)
+	}
+
+	gh.replanWatchpoints()
+	return added, nil
+}
+
+// RemoveWatchpoint stops a watchpoint
+func (gh *EnhancedGameHook) RemoveWatchpoint(id string) error {
+	removed, err := gh.memory.RemoveWatchpoint(id)
+	if err != nil {
+		return err
+	}
+
+	// The property stays watched while another watchpoint covers it
+	if removed.Property != "" && gh.currentMapper != nil {
+		if prop, exists := gh.currentMapper.Properties[removed.Property]; exists {
+			watched := false
+			for _, wp := range gh.memory.ListWatchpoints() {
+				watched = watched || wp.Property == removed.Property
+			}
+			if !watched {
+				gh.memory.SetPropertyWatch(prop.Name, prop.Address, false, "")
+			}
+		}
+	}
+
+	gh.replanWatchpoints()
+	return nil
+}
+
+// ListWatchpoints returns the active watchpoints
+func (gh *EnhancedGameHook) ListWatchpoints() interface{} {
+	return gh.memory.ListWatchpoints()
+}
+
+// GetWatchHits returns the most recent changes a watchpoint recorded
+func (gh *EnhancedGameHook) GetWatchHits(id string, limit int) (interface{}, error) {
+	return gh.memory.WatchHits(id, limit)
+}
+
+// replanWatchpoints updates the read plan to cover the current watchpoints
+func (gh *EnhancedGameHook) replanWatchpoints() {
+	gh.planMu.Lock()
+	defer gh.planMu.Unlock()
+
+	if gh.readPlan != nil {
+		gh.readPlan = gh.readPlan.WithWatchpoints(gh.memory.ListWatchpoints())
+	}
+}
+
+// wholeReadsPeriod is how long a heatmap request or capture suspends the read plan
+const wholeReadsPeriod = time.Minute
+
+// suspendReadPlan reads whole blocks instead of the plan's ranges for the next wholeReadsPeriod
+func (gh *EnhancedGameHook) suspendReadPlan() {
+	gh.wholeUntil.Store(time.Now().Add(wholeReadsPeriod).UnixNano())
+}
+
+// awaitWholeBlocks suspends the read plan and waits until the memory manager
+// holds whole blocks, which takes one update tick while the plan is active
+func (gh *EnhancedGameHook) awaitWholeBlocks() error {
+	gh.suspendReadPlan()
+	if gh.currentMapper == nil {
+		return nil
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !gh.wholeLoaded.Load() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for a read of whole memory blocks")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return nil
+}
+
+// GetMemoryHeatmap returns the change frequency of a domain's loaded bytes,
+// with each bucket listing the mapper properties that overlap it. Whole
+// blocks are read for a while after each request so unmapped bytes count too.
+func (gh *EnhancedGameHook) GetMemoryHeatmap(domain string, bucketSize uint32) (*memory.Heatmap, error) {
+	gh.suspendReadPlan()
+
+	heatmap, err := gh.memory.Heatmap(domain, bucketSize)
+	if err != nil || gh.currentMapper == nil {
+		return heatmap, err
+	}
+
+	buckets := heatmap.Buckets
+	for name, prop := range gh.currentMapper.Properties {
+		if prop.Computed != nil || prop.Domain != domain {
+			continue
+		}
+		end := uint64(prop.Address) + uint64(max(prop.Length, 1))
+
+		// Buckets are sorted by address, so start at the one holding the property
+		first := sort.Search(len(buckets), func(i int) bool {
+			return uint64(buckets[i].Address)+uint64(bucketSize) > uint64(prop.Address)
+		})
+		for i := first; i < len(buckets) && uint64(buckets[i].Address) < end; i++ {
+			buckets[i].Properties = append(buckets[i].Properties, name)
+		}
+	}
+	for i := range buckets {
+		sort.Strings(buckets[i].Properties)
+	}
+
+	return heatmap, nil
+}
+
+// ResetMemoryHeatmap clears the change counts behind the heatmap
+func (gh *EnhancedGameHook) ResetMemoryHeatmap() {
+	gh.memory.ResetHeatmap()
+}
+
+// GetPropertyHistory returns a property's recorded values between from and
+// to, downsampled into buckets of step when it is not zero. An empty mapper
+// queries the loaded mapper, whose property must exist.
+func (gh *EnhancedGameHook) GetPropertyHistory(mapper, name string, from, to time.Time, step time.Duration) (interface{}, error) {
+	if gh.history == nil {
+		return nil, fmt.Errorf("property history is disabled")
+	}
+
+	var prop *mappers.Property
+	if mapper == "" || mapper == gh.mapperName {
+		if gh.currentMapper == nil {
+			return nil, fmt.Errorf("no mapper loaded")
+		}
+		var exists bool
+		if prop, exists = gh.currentMapper.Properties[name]; !exists {
+			return nil, fmt.Errorf("property %s not found", name)
+		}
+		mapper = gh.mapperName
+	}
+
+	result, err := gh.history.Query(mapper, name, from, to, step)
+	if err != nil {
+		return nil, err
+	}
+	if prop != nil && prop.UIHints != nil {
+		result.ChartType = prop.UIHints.ChartType
+	}
+	return result, nil
+}
+
+// GetReplayStatus returns the playback position of the replay driver
+func (gh *EnhancedGameHook) GetReplayStatus() (map[string]interface{}, error) {
+	replay, ok := gh.driver.(*drivers.ReplayDriver)
+	if !ok {
+		return nil, fmt.Errorf("replay driver is not active")
+	}
+	return replay.Status(), nil
+}
+
+// StepReplay advances a stepwise replay by the given number of frames
+func (gh *EnhancedGameHook) StepReplay(frames int) error {
+	replay, ok := gh.driver.(*drivers.ReplayDriver)
+	if !ok {
+		return fmt.Errorf("replay driver is not active")
+	}
+	if frames < 1 {
+		frames = 1
+	}
+	return replay.Step(frames)
+}
+
+// emulatorActions lists the actions accepted by ControlEmulator
+var emulatorActions = []string{
+	"pause", "resume", "toggle-pause", "frame-advance", "reset",
+	"save-state", "load-state", "state-slot-plus", "state-slot-minus",
+}
+
+// emulatorController returns the driver's emulator controls if it has any
+func (gh *EnhancedGameHook) emulatorController() (drivers.EmulatorController, error) {
+	controller, ok := gh.driver.(drivers.EmulatorController)
+	if !ok {
+		return nil, fmt.Errorf("the %s driver does not support emulator control", gh.config.Driver)
+	}
+	return controller, nil
+}
+
+// GetEmulatorStatus returns the emulator's run state
+func (gh *EnhancedGameHook) GetEmulatorStatus() (interface{}, error) {
+	controller, err := gh.emulatorController()
+	if err != nil {
+		return nil, err
+	}
+	return controller.EmulationStatus()
+}
+
+// ControlEmulator performs an emulator action. frames is only used by frame-advance.
+func (gh *EnhancedGameHook) ControlEmulator(action string, frames int) error {
+	controller, err := gh.emulatorController()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "pause":
+		err = controller.Pause()
+	case "resume":
+		err = controller.Resume()
+	case "toggle-pause":
+		err = controller.TogglePause()
+	case "frame-advance":
+		if frames < 1 {
+			frames = 1
+		}
+		err = controller.FrameAdvance(frames)
+	case "reset":
+		err = controller.Reset()
+	case "save-state":
+		err = controller.SaveState()
+	case "load-state":
+		err = controller.LoadState()
+	case "state-slot-plus":
+		err = controller.ChangeStateSlot(1)
+	case "state-slot-minus":
+		err = controller.ChangeStateSlot(-1)
+	default:
+		return fmt.Errorf("unknown emulator action %q (valid: %s)", action, strings.Join(emulatorActions, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", action, err)
+	}
+
+	log.Printf("🕹️  Emulator %s", action)
+	if gh.server != nil {
+		message := map[string]interface{}{
+			"type":      "emulator_control",
+			"action":    action,
+			"timestamp": time.Now(),
+		}
+		if action == "frame-advance" {
+			message["frames"] = frames
+		}
+		gh.server.Broadcast(message)
+	}
+
+	return nil
+}
+
 // Utility function
 func min(a, b int) int {
 	if a < b {
@@ -13,9 +13,20 @@ import (
 // Config represents the enhanced application configuration
 type Config struct {
 	// Core configuration (existing)
+	Driver      string            `mapstructure:"driver"`
+	Reconnect   ReconnectConfig   `mapstructure:"reconnect"`
 	Server      ServerConfig      `mapstructure:"server"`
 	RetroArch   RetroArchConfig   `mapstructure:"retroarch"`
 	BizHawk     BizHawkConfig     `mapstructure:"bizhawk"`
+	File        FileDriverConfig  `mapstructure:"file"`
+	GDB         GDBConfig         `mapstructure:"gdb"`
+	NWA         NWAConfig         `mapstructure:"nwa"`
+	Usb2snes    Usb2snesConfig    `mapstructure:"usb2snes"`
+	Process     ProcessConfig     `mapstructure:"process"`
+	Replay      ReplayConfig      `mapstructure:"replay"`
+	Recording   RecordingConfig   `mapstructure:"recording"`
+	Detection   DetectionConfig   `mapstructure:"detection"`
+	History     HistoryConfig     `mapstructure:"history"`
 	Paths       PathsConfig       `mapstructure:"paths"`
 	Performance PerformanceConfig `mapstructure:"performance"`
 	Logging     LoggingConfig     `mapstructure:"logging"`
@@ -49,12 +60,20 @@ type TLSConfig struct {
 	KeyFile  string `mapstructure:"key_file"`
 }
 
+// ReconnectConfig is how the update loop retries a failing driver. The
+// RetroArch driver keeps its own retroarch.max_retries and retroarch.retry_delay.
+type ReconnectConfig struct {
+	MaxRetries int           `mapstructure:"max_retries"`
+	RetryDelay time.Duration `mapstructure:"retry_delay"`
+}
+
 type RetroArchConfig struct {
 	Host           string        `mapstructure:"host"`
 	Port           int           `mapstructure:"port"`
 	RequestTimeout time.Duration `mapstructure:"request_timeout"`
 	MaxRetries     int           `mapstructure:"max_retries"`
 	RetryDelay     time.Duration `mapstructure:"retry_delay"`
+	PipelineDepth  int           `mapstructure:"pipeline_depth"`
 }
 
 type BizHawkConfig struct {
@@ -63,6 +82,78 @@ type BizHawkConfig struct {
 	Timeout       time.Duration `mapstructure:"timeout"`
 }
 
+type GDBConfig struct {
+	Host           string        `mapstructure:"host"`
+	Port           int           `mapstructure:"port"`
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+}
+
+type NWAConfig struct {
+	Host           string            `mapstructure:"host"`
+	Port           int               `mapstructure:"port"`
+	RequestTimeout time.Duration     `mapstructure:"request_timeout"`
+	Domains        []NWADomainConfig `mapstructure:"domains"`
+}
+
+// NWADomainConfig maps a bus address range onto an NWA memory domain
+type NWADomainConfig struct {
+	Domain string `mapstructure:"domain"`
+	Start  uint32 `mapstructure:"start"`
+	End    uint32 `mapstructure:"end"`
+	Offset uint32 `mapstructure:"offset"`
+}
+
+type Usb2snesConfig struct {
+	URL            string                  `mapstructure:"url"`
+	Device         string                  `mapstructure:"device"`
+	RequestTimeout time.Duration           `mapstructure:"request_timeout"`
+	Mappings       []Usb2snesMappingConfig `mapstructure:"mappings"`
+}
+
+// Usb2snesMappingConfig maps a bus address range into usb2snes address space
+type Usb2snesMappingConfig struct {
+	Start  uint32 `mapstructure:"start"`
+	End    uint32 `mapstructure:"end"`
+	Target uint32 `mapstructure:"target"`
+}
+
+type ProcessConfig struct {
+	PID         int    `mapstructure:"pid"`
+	Name        string `mapstructure:"name"`
+	BaseAddress uint64 `mapstructure:"base_address"`
+}
+
+type FileDriverConfig struct {
+	DumpPath    string `mapstructure:"dump_path"`
+	BaseAddress uint32 `mapstructure:"base_address"`
+	WriteBack   bool   `mapstructure:"write_back"`
+}
+
+type ReplayConfig struct {
+	Path     string  `mapstructure:"path"`
+	Speed    float64 `mapstructure:"speed"`
+	Stepwise bool    `mapstructure:"stepwise"`
+	Loop     bool    `mapstructure:"loop"`
+}
+
+type RecordingConfig struct {
+	Enabled          bool   `mapstructure:"enabled"`
+	Dir              string `mapstructure:"dir"`
+	KeyframeInterval int    `mapstructure:"keyframe_interval"`
+}
+
+type DetectionConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+type HistoryConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Retention     time.Duration `mapstructure:"retention"`
+	MaxSizeMB     int           `mapstructure:"max_size_mb"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
 type PathsConfig struct {
 	MappersDir string `mapstructure:"mappers_dir"`
 	UIsDir     string `mapstructure:"uis_dir"`
@@ -78,6 +169,8 @@ type PerformanceConfig struct {
 	WebSocketBuffer  int           `mapstructure:"websocket_buffer"`
 	GCTargetPercent  int           `mapstructure:"gc_target_percent"`
 	MaxMemoryUsageMB int           `mapstructure:"max_memory_usage_mb"`
+	ReadPlanning     bool          `mapstructure:"read_planning"`
+	ReadPlanGap      uint32        `mapstructure:"read_plan_gap"`
 }
 
 type LoggingConfig struct {
@@ -141,6 +234,11 @@ type PropertyMonitoringConfig struct {
 	EnableChangeDetection bool          `mapstructure:"enable_change_detection"`
 	BatchChangeEvents     bool          `mapstructure:"batch_change_events"`
 	MaxEventsPerBatch     int           `mapstructure:"max_events_per_batch"`
+
+	// Change pattern detection over each property's value history
+	PatternDetection  bool          `mapstructure:"pattern_detection"`
+	PatternInterval   time.Duration `mapstructure:"pattern_interval"`
+	PatternConfidence float64       `mapstructure:"pattern_confidence"` // Confidence at which a pattern is announced
 }
 
 type BatchOperationsConfig struct {
@@ -199,6 +297,11 @@ type DatabaseConfig struct {
 func DefaultConfig() *Config {
 	return &Config{
 		// Core configuration defaults
+		Driver: "retroarch",
+		Reconnect: ReconnectConfig{
+			MaxRetries: 3,
+			RetryDelay: 500 * time.Millisecond,
+		},
 		Server: ServerConfig{
 			Host:         "0.0.0.0",
 			Port:         8080,
@@ -215,12 +318,61 @@ func DefaultConfig() *Config {
 			RequestTimeout: 64 * time.Millisecond,
 			MaxRetries:     3,
 			RetryDelay:     100 * time.Millisecond,
+			PipelineDepth:  4,
 		},
 		BizHawk: BizHawkConfig{
 			MemoryMapName: "GAMEHOOK_BIZHAWK.bin",
 			DataMapName:   "GAMEHOOK_BIZHAWK_DATA.bin",
 			Timeout:       1 * time.Second,
 		},
+		GDB: GDBConfig{
+			Host:           "127.0.0.1",
+			Port:           2345,
+			RequestTimeout: 500 * time.Millisecond,
+		},
+		NWA: NWAConfig{
+			Host:           "127.0.0.1",
+			Port:           0xBEEF,
+			RequestTimeout: 500 * time.Millisecond,
+			Domains:        []NWADomainConfig{}, // empty = standard SNES layout
+		},
+		Usb2snes: Usb2snesConfig{
+			URL:            "ws://127.0.0.1:23074",
+			Device:         "", // empty = first device
+			RequestTimeout: time.Second,
+			Mappings:       []Usb2snesMappingConfig{}, // empty = WRAM/SRAM defaults
+		},
+		Process: ProcessConfig{
+			PID:         0,
+			Name:        "",
+			BaseAddress: 0, // 0 = locate using the mapper's processMemory
+		},
+		File: FileDriverConfig{
+			DumpPath:    "",
+			BaseAddress: 0,
+			WriteBack:   false,
+		},
+		Replay: ReplayConfig{
+			Path:     "",
+			Speed:    1.0,
+			Stepwise: false,
+			Loop:     false,
+		},
+		Recording: RecordingConfig{
+			Enabled:          false,
+			Dir:              "./data/recordings",
+			KeyframeInterval: 600,
+		},
+		Detection: DetectionConfig{
+			Enabled:  true,
+			Interval: 2 * time.Second,
+		},
+		History: HistoryConfig{
+			Enabled:       true,
+			Retention:     7 * 24 * time.Hour,
+			MaxSizeMB:     512,
+			FlushInterval: time.Second,
+		},
 		Paths: PathsConfig{
 			MappersDir: "./mappers",
 			UIsDir:     "./uis",
@@ -235,6 +387,8 @@ func DefaultConfig() *Config {
 			WebSocketBuffer:  256,
 			GCTargetPercent:  100,
 			MaxMemoryUsageMB: 512,
+			ReadPlanning:     true,
+			ReadPlanGap:      32,
 		},
 		Logging: LoggingConfig{
 			Level:      "info",
@@ -291,6 +445,9 @@ func DefaultConfig() *Config {
 			EnableChangeDetection: true,
 			BatchChangeEvents:     true,
 			MaxEventsPerBatch:     50,
+			PatternDetection:      true,
+			PatternInterval:       2 * time.Second,
+			PatternConfidence:     0.8,
 		},
 
 		BatchOperations: BatchOperationsConfig{
@@ -398,6 +555,9 @@ func LoadConfig(configPath string) (*Config, error) {
 // setDefaults sets all default values in viper (enhanced)
 func setDefaults(v *viper.Viper, config *Config) {
 	// Core configuration defaults
+	v.SetDefault("driver", config.Driver)
+	v.SetDefault("reconnect.max_retries", config.Reconnect.MaxRetries)
+	v.SetDefault("reconnect.retry_delay", config.Reconnect.RetryDelay)
 	v.SetDefault("server.host", config.Server.Host)
 	v.SetDefault("server.port", config.Server.Port)
 	v.SetDefault("server.read_timeout", config.Server.ReadTimeout)
@@ -410,11 +570,49 @@ func setDefaults(v *viper.Viper, config *Config) {
 	v.SetDefault("retroarch.request_timeout", config.RetroArch.RequestTimeout)
 	v.SetDefault("retroarch.max_retries", config.RetroArch.MaxRetries)
 	v.SetDefault("retroarch.retry_delay", config.RetroArch.RetryDelay)
+	v.SetDefault("retroarch.pipeline_depth", config.RetroArch.PipelineDepth)
 
 	v.SetDefault("bizhawk.memory_map_name", config.BizHawk.MemoryMapName)
 	v.SetDefault("bizhawk.data_map_name", config.BizHawk.DataMapName)
 	v.SetDefault("bizhawk.timeout", config.BizHawk.Timeout)
 
+	v.SetDefault("gdb.host", config.GDB.Host)
+	v.SetDefault("gdb.port", config.GDB.Port)
+	v.SetDefault("gdb.request_timeout", config.GDB.RequestTimeout)
+
+	v.SetDefault("nwa.host", config.NWA.Host)
+	v.SetDefault("nwa.port", config.NWA.Port)
+	v.SetDefault("nwa.request_timeout", config.NWA.RequestTimeout)
+
+	v.SetDefault("usb2snes.url", config.Usb2snes.URL)
+	v.SetDefault("usb2snes.device", config.Usb2snes.Device)
+	v.SetDefault("usb2snes.request_timeout", config.Usb2snes.RequestTimeout)
+
+	v.SetDefault("process.pid", config.Process.PID)
+	v.SetDefault("process.name", config.Process.Name)
+	v.SetDefault("process.base_address", config.Process.BaseAddress)
+
+	v.SetDefault("file.dump_path", config.File.DumpPath)
+	v.SetDefault("file.base_address", config.File.BaseAddress)
+	v.SetDefault("file.write_back", config.File.WriteBack)
+
+	v.SetDefault("replay.path", config.Replay.Path)
+	v.SetDefault("replay.speed", config.Replay.Speed)
+	v.SetDefault("replay.stepwise", config.Replay.Stepwise)
+	v.SetDefault("replay.loop", config.Replay.Loop)
+
+	v.SetDefault("recording.enabled", config.Recording.Enabled)
+	v.SetDefault("recording.dir", config.Recording.Dir)
+	v.SetDefault("recording.keyframe_interval", config.Recording.KeyframeInterval)
+
+	v.SetDefault("detection.enabled", config.Detection.Enabled)
+	v.SetDefault("detection.interval", config.Detection.Interval)
+
+	v.SetDefault("history.enabled", config.History.Enabled)
+	v.SetDefault("history.retention", config.History.Retention)
+	v.SetDefault("history.max_size_mb", config.History.MaxSizeMB)
+	v.SetDefault("history.flush_interval", config.History.FlushInterval)
+
 	v.SetDefault("paths.mappers_dir", config.Paths.MappersDir)
 	v.SetDefault("paths.uis_dir", config.Paths.UIsDir)
 	v.SetDefault("paths.data_dir", config.Paths.DataDir)
@@ -427,6 +625,8 @@ func setDefaults(v *viper.Viper, config *Config) {
 	v.SetDefault("performance.websocket_buffer", config.Performance.WebSocketBuffer)
 	v.SetDefault("performance.gc_target_percent", config.Performance.GCTargetPercent)
 	v.SetDefault("performance.max_memory_usage_mb", config.Performance.MaxMemoryUsageMB)
+	v.SetDefault("performance.read_planning", config.Performance.ReadPlanning)
+	v.SetDefault("performance.read_plan_gap", config.Performance.ReadPlanGap)
 
 	v.SetDefault("logging.level", config.Logging.Level)
 	v.SetDefault("logging.format", config.Logging.Format)
@@ -474,6 +674,9 @@ func setDefaults(v *viper.Viper, config *Config) {
 	v.SetDefault("property_monitoring.enable_change_detection", config.PropertyMonitoring.EnableChangeDetection)
 	v.SetDefault("property_monitoring.batch_change_events", config.PropertyMonitoring.BatchChangeEvents)
 	v.SetDefault("property_monitoring.max_events_per_batch", config.PropertyMonitoring.MaxEventsPerBatch)
+	v.SetDefault("property_monitoring.pattern_detection", config.PropertyMonitoring.PatternDetection)
+	v.SetDefault("property_monitoring.pattern_interval", config.PropertyMonitoring.PatternInterval)
+	v.SetDefault("property_monitoring.pattern_confidence", config.PropertyMonitoring.PatternConfidence)
 
 	v.SetDefault("batch_operations.max_batch_size", config.BatchOperations.MaxBatchSize)
 	v.SetDefault("batch_operations.timeout", config.BatchOperations.Timeout)
@@ -516,6 +719,15 @@ func setDefaults(v *viper.Viper, config *Config) {
 	v.SetDefault("database.migration_enabled", config.Database.MigrationEnabled)
 }
 
+// ReconnectPolicy returns the failed reads tolerated before reconnecting and
+// the first reconnect delay for the configured driver
+func (c *Config) ReconnectPolicy() (int, time.Duration) {
+	if c.Driver == "retroarch" {
+		return c.RetroArch.MaxRetries, c.RetroArch.RetryDelay
+	}
+	return c.Reconnect.MaxRetries, c.Reconnect.RetryDelay
+}
+
 // normalizeConfig validates and normalizes enhanced configuration values
 func normalizeConfig(config *Config) error {
 	// Convert relative paths to absolute paths
@@ -550,6 +762,98 @@ func normalizeConfig(config *Config) error {
 		return fmt.Errorf("invalid RetroArch port: %d", config.RetroArch.Port)
 	}
 
+	// Validate emulator driver
+	validEmulatorDrivers := []string{"retroarch", "bizhawk", "file", "replay", "gdb", "nwa", "usb2snes", "process"}
+	if !contains(validEmulatorDrivers, config.Driver) {
+		return fmt.Errorf("invalid driver: %s", config.Driver)
+	}
+
+	if config.Driver == "bizhawk" && config.BizHawk.Timeout < 0 {
+		return fmt.Errorf("BizHawk timeout cannot be negative: %v", config.BizHawk.Timeout)
+	}
+
+	if config.Driver == "file" && config.File.DumpPath == "" {
+		return fmt.Errorf("file driver requires file.dump_path")
+	}
+
+	if config.GDB.Port < 1 || config.GDB.Port > 65535 {
+		return fmt.Errorf("invalid GDB port: %d", config.GDB.Port)
+	}
+
+	if config.GDB.RequestTimeout < time.Millisecond {
+		return fmt.Errorf("GDB request timeout too small: %v", config.GDB.RequestTimeout)
+	}
+
+	if config.NWA.Port < 1 || config.NWA.Port > 65535 {
+		return fmt.Errorf("invalid NWA port: %d", config.NWA.Port)
+	}
+
+	if config.NWA.RequestTimeout < time.Millisecond {
+		return fmt.Errorf("NWA request timeout too small: %v", config.NWA.RequestTimeout)
+	}
+
+	for _, domain := range config.NWA.Domains {
+		if domain.Domain == "" || domain.End < domain.Start {
+			return fmt.Errorf("invalid NWA domain mapping: %+v", domain)
+		}
+	}
+
+	if config.Usb2snes.RequestTimeout < time.Millisecond {
+		return fmt.Errorf("usb2snes request timeout too small: %v", config.Usb2snes.RequestTimeout)
+	}
+
+	for _, mapping := range config.Usb2snes.Mappings {
+		if mapping.End < mapping.Start {
+			return fmt.Errorf("invalid usb2snes mapping: %+v", mapping)
+		}
+	}
+
+	if config.Process.PID < 0 {
+		return fmt.Errorf("invalid process pid: %d", config.Process.PID)
+	}
+
+	if config.Driver == "process" && config.Process.PID == 0 && config.Process.Name == "" {
+		return fmt.Errorf("process driver requires process.pid or process.name")
+	}
+
+	if config.Driver == "replay" && config.Replay.Path == "" {
+		return fmt.Errorf("replay driver requires replay.path")
+	}
+
+	if config.Replay.Speed < 0 {
+		return fmt.Errorf("replay speed cannot be negative: %f", config.Replay.Speed)
+	}
+
+	if config.Recording.KeyframeInterval < 1 {
+		return fmt.Errorf("recording keyframe interval must be at least 1: %d", config.Recording.KeyframeInterval)
+	}
+
+	if config.Detection.Enabled && config.Detection.Interval <= 0 {
+		return fmt.Errorf("detection interval must be positive: %v", config.Detection.Interval)
+	}
+
+	if config.History.Retention < 0 {
+		return fmt.Errorf("history retention cannot be negative: %v", config.History.Retention)
+	}
+
+	if config.History.MaxSizeMB < 0 {
+		return fmt.Errorf("history max size cannot be negative: %d", config.History.MaxSizeMB)
+	}
+
+	if config.History.Enabled && config.History.FlushInterval <= 0 {
+		return fmt.Errorf("history flush interval must be positive: %v", config.History.FlushInterval)
+	}
+
+	if config.Recording.Dir, err = filepath.Abs(config.Recording.Dir); err != nil {
+		return fmt.Errorf("invalid recording directory: %w", err)
+	}
+
+	if config.File.DumpPath != "" {
+		if config.File.DumpPath, err = filepath.Abs(config.File.DumpPath); err != nil {
+			return fmt.Errorf("invalid dump path: %w", err)
+		}
+	}
+
 	// Validate timeouts
 	if config.Performance.UpdateInterval < time.Millisecond {
 		return fmt.Errorf("update interval too small: %v", config.Performance.UpdateInterval)
@@ -559,6 +863,10 @@ func normalizeConfig(config *Config) error {
 		return fmt.Errorf("RetroArch request timeout too small: %v", config.RetroArch.RequestTimeout)
 	}
 
+	if config.RetroArch.PipelineDepth < 1 || config.RetroArch.PipelineDepth > 64 {
+		return fmt.Errorf("RetroArch pipeline depth must be between 1 and 64: %d", config.RetroArch.PipelineDepth)
+	}
+
 	// Validate enhanced configuration
 	if config.PropertyMonitoring.UpdateInterval < time.Millisecond {
 		return fmt.Errorf("property monitoring update interval too small: %v", config.PropertyMonitoring.UpdateInterval)
@@ -621,6 +929,10 @@ func normalizeConfig(config *Config) error {
 		config.Paths.CacheDir,
 	}
 
+	if config.Recording.Enabled {
+		dirs = append(dirs, config.Recording.Dir)
+	}
+
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return fmt.Errorf("error creating directory %s: %w", dir, err)
@@ -679,6 +991,15 @@ func validateEnhancedConfig(config *Config) error {
 		return fmt.Errorf("max events per batch must be at least 1 when batch change events are enabled")
 	}
 
+	if config.PropertyMonitoring.PatternDetection {
+		if config.PropertyMonitoring.PatternInterval < 100*time.Millisecond {
+			return fmt.Errorf("pattern detection interval must be at least 100ms: %v", config.PropertyMonitoring.PatternInterval)
+		}
+		if config.PropertyMonitoring.PatternConfidence <= 0 || config.PropertyMonitoring.PatternConfidence > 1 {
+			return fmt.Errorf("pattern confidence must be above 0 and at most 1: %v", config.PropertyMonitoring.PatternConfidence)
+		}
+	}
+
 	return nil
 }
 
@@ -698,6 +1019,7 @@ func validateCrossReferences(config *Config) error {
 // GetConfigSummary returns a summary of the current configuration for logging
 func GetConfigSummary(config *Config) map[string]interface{} {
 	return map[string]interface{}{
+		"driver":              config.Driver,
 		"server_port":         config.Server.Port,
 		"retroarch_host":      fmt.Sprintf("%s:%d", config.RetroArch.Host, config.RetroArch.Port),
 		"update_interval":     config.Performance.UpdateInterval.String(),
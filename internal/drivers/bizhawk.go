@@ -0,0 +1,298 @@
+package drivers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"gamehook/internal/types"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BizHawk shared memory layout
+//
+// The BizHawk integration tool exposes two memory-mapped files. The metadata
+// file describes which parts of the system bus are mirrored into the data file:
+//
+//	0x00  uint8     integration version (0 = not ready)
+//	0x01  [31]byte  system name, NUL padded
+//	0x20  uint32    frame counter (little endian)
+//	0x24  uint32    region count
+//	0x28  regions   count * {start uint32, length uint32, offset uint32}
+//
+// Each region maps the bus range [start, start+length) to data file bytes
+// [offset, offset+length). Writes go straight into the data file and are
+// picked up by the tool on the next frame.
+const (
+	bizhawkVersionOffset     = 0x00
+	bizhawkSystemNameOffset  = 0x01
+	bizhawkSystemNameLength  = 31
+	bizhawkFrameOffset       = 0x20
+	bizhawkRegionCountOffset = 0x24
+	bizhawkRegionTableOffset = 0x28
+	bizhawkRegionEntrySize   = 12
+)
+
+// bizhawkRegion maps a range of the emulated bus into the data file
+type bizhawkRegion struct {
+	Start  uint32
+	Length uint32
+	Offset uint32
+}
+
+// BizHawkDriver reads and writes emulator memory through BizHawk's memory-mapped files
+type BizHawkDriver struct {
+	memoryMapName string
+	dataMapName   string
+	timeout       time.Duration
+
+	mu         sync.Mutex
+	metadata   sharedFile
+	data       sharedFile
+	systemName string
+	regions    []bizhawkRegion
+}
+
+// NewBizHawkDriver creates a new BizHawk memory-mapped file driver
+func NewBizHawkDriver(memoryMapName, dataMapName string, timeout time.Duration) *BizHawkDriver {
+	return &BizHawkDriver{
+		memoryMapName: memoryMapName,
+		dataMapName:   dataMapName,
+		timeout:       timeout,
+	}
+}
+
+// SharedMemoryPath resolves a memory map name to its backing file
+func SharedMemoryPath(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	if runtime.GOOS == "linux" {
+		return filepath.Join("/dev/shm", name)
+	}
+	return filepath.Join(os.TempDir(), name)
+}
+
+// Connect opens both mapped files, waiting up to the configured timeout for the
+// BizHawk tool to publish them
+func (d *BizHawkDriver) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closeLocked()
+
+	deadline := time.Now().Add(d.timeout)
+	var lastErr error
+
+	for {
+		lastErr = d.openLocked()
+		if lastErr == nil {
+			fmt.Printf("🎮 Connected to BizHawk (%s): %d memory regions\n", d.systemName, len(d.regions))
+			return nil
+		}
+
+		d.closeLocked()
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return fmt.Errorf("failed to connect to BizHawk: %w", lastErr)
+}
+
+// openLocked maps both files and parses the region table
+func (d *BizHawkDriver) openLocked() error {
+	metadata, err := openSharedFile(SharedMemoryPath(d.memoryMapName))
+	if err != nil {
+		return fmt.Errorf("failed to open metadata map: %w", err)
+	}
+	d.metadata = metadata
+
+	data, err := openSharedFile(SharedMemoryPath(d.dataMapName))
+	if err != nil {
+		return fmt.Errorf("failed to open data map: %w", err)
+	}
+	d.data = data
+
+	return d.loadMetadataLocked()
+}
+
+// loadMetadataLocked refreshes the system name and region table from the metadata file
+func (d *BizHawkDriver) loadMetadataLocked() error {
+	header := make([]byte, bizhawkRegionTableOffset)
+	if _, err := d.metadata.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("failed to read metadata header: %w", err)
+	}
+
+	if header[bizhawkVersionOffset] == 0 {
+		return fmt.Errorf("BizHawk integration is not ready")
+	}
+
+	name := header[bizhawkSystemNameOffset : bizhawkSystemNameOffset+bizhawkSystemNameLength]
+	d.systemName = strings.TrimRight(string(name), "\x00")
+
+	count := binary.LittleEndian.Uint32(header[bizhawkRegionCountOffset:])
+	maxCount := uint32((d.metadata.Size() - bizhawkRegionTableOffset) / bizhawkRegionEntrySize)
+	if count > maxCount {
+		return fmt.Errorf("region count %d exceeds metadata size", count)
+	}
+
+	table := make([]byte, count*bizhawkRegionEntrySize)
+	if _, err := d.metadata.ReadAt(table, bizhawkRegionTableOffset); err != nil {
+		return fmt.Errorf("failed to read region table: %w", err)
+	}
+
+	regions := make([]bizhawkRegion, 0, count)
+	for i := uint32(0); i < count; i++ {
+		entry := table[i*bizhawkRegionEntrySize:]
+		region := bizhawkRegion{
+			Start:  binary.LittleEndian.Uint32(entry[0:4]),
+			Length: binary.LittleEndian.Uint32(entry[4:8]),
+			Offset: binary.LittleEndian.Uint32(entry[8:12]),
+		}
+		if int64(region.Offset)+int64(region.Length) > d.data.Size() {
+			return fmt.Errorf("region at 0x%X exceeds data map size", region.Start)
+		}
+		regions = append(regions, region)
+	}
+
+	d.regions = regions
+	return nil
+}
+
+// SystemName returns the system name published by the BizHawk tool
+func (d *BizHawkDriver) SystemName() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.systemName
+}
+
+// FrameCount returns the current frame counter published by the BizHawk tool
+func (d *BizHawkDriver) FrameCount() (uint32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.metadata == nil {
+		return 0, fmt.Errorf("not connected to BizHawk")
+	}
+
+	buf := make([]byte, 4)
+	if _, err := d.metadata.ReadAt(buf, bizhawkFrameOffset); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf), nil
+}
+
+// ReadMemoryBlocks reads multiple memory blocks from the BizHawk data map
+func (d *BizHawkDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	d.mu.Lock()
+	connected := d.data != nil
+	d.mu.Unlock()
+
+	if !connected {
+		if err := d.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := make(map[uint32][]byte)
+
+	for _, block := range blocks {
+		data := make([]byte, block.End-block.Start+1)
+		if err := d.accessLocked(block.Start, data, false); err != nil {
+			return nil, fmt.Errorf("failed to read block %s: %w", block.Name, err)
+		}
+		result[block.Start] = data
+	}
+
+	return result, nil
+}
+
+// WriteBytes writes bytes into the BizHawk data map
+func (d *BizHawkDriver) WriteBytes(address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.data == nil {
+		return fmt.Errorf("not connected to BizHawk")
+	}
+
+	return d.accessLocked(address, data, true)
+}
+
+// accessLocked copies between buf and the data map, splitting across adjacent regions
+func (d *BizHawkDriver) accessLocked(address uint32, buf []byte, write bool) error {
+	done := 0
+	for done < len(buf) {
+		current := address + uint32(done)
+
+		region, ok := d.findRegion(current)
+		if !ok {
+			return fmt.Errorf("address 0x%X is not exposed by BizHawk", current)
+		}
+
+		regionOffset := current - region.Start
+		n := int(region.Length - regionOffset)
+		if n > len(buf)-done {
+			n = len(buf) - done
+		}
+
+		fileOffset := int64(region.Offset) + int64(regionOffset)
+		var err error
+		if write {
+			_, err = d.data.WriteAt(buf[done:done+n], fileOffset)
+		} else {
+			_, err = d.data.ReadAt(buf[done:done+n], fileOffset)
+		}
+		if err != nil {
+			return fmt.Errorf("data map access at 0x%X failed: %w", current, err)
+		}
+
+		done += n
+	}
+
+	return nil
+}
+
+// findRegion returns the region containing address
+func (d *BizHawkDriver) findRegion(address uint32) (bizhawkRegion, bool) {
+	for _, region := range d.regions {
+		if address >= region.Start && uint64(address) < uint64(region.Start)+uint64(region.Length) {
+			return region, true
+		}
+	}
+	return bizhawkRegion{}, false
+}
+
+// Close unmaps both files
+func (d *BizHawkDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closeLocked()
+}
+
+// closeLocked releases the mapped files
+func (d *BizHawkDriver) closeLocked() error {
+	var firstErr error
+	if d.data != nil {
+		if err := d.data.Close(); err != nil {
+			firstErr = err
+		}
+		d.data = nil
+	}
+	if d.metadata != nil {
+		if err := d.metadata.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		d.metadata = nil
+	}
+	d.regions = nil
+	return firstErr
+}
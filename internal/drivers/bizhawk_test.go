@@ -0,0 +1,271 @@
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"gamehook/internal/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bizhawkMetadata builds a metadata map the way the BizHawk tool lays it out
+func bizhawkMetadata(version byte, frame uint32, regions []bizhawkRegion) []byte {
+	metadata := make([]byte, bizhawkRegionTableOffset+len(regions)*bizhawkRegionEntrySize)
+	metadata[bizhawkVersionOffset] = version
+	copy(metadata[bizhawkSystemNameOffset:], "GBC")
+	binary.LittleEndian.PutUint32(metadata[bizhawkFrameOffset:], frame)
+	binary.LittleEndian.PutUint32(metadata[bizhawkRegionCountOffset:], uint32(len(regions)))
+	for i, region := range regions {
+		entry := metadata[bizhawkRegionTableOffset+i*bizhawkRegionEntrySize:]
+		binary.LittleEndian.PutUint32(entry[0:4], region.Start)
+		binary.LittleEndian.PutUint32(entry[4:8], region.Length)
+		binary.LittleEndian.PutUint32(entry[8:12], region.Offset)
+	}
+	return metadata
+}
+
+// TestBizHawkHelperProcess is the fake BizHawk tool run by startHelperProcess, not a real test.
+// It publishes the mapped files and changes or dumps them on commands read from stdin:
+//
+//	publish <dir> <version> <start>:<length>:<offset>...
+//	poke <offset> <hex bytes>
+//	frame <count>
+//	dump <offset> <length>
+func TestBizHawkHelperProcess(t *testing.T) {
+	if os.Getenv("GAMEHOOK_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	var metadataPath, dataPath string
+	run := func(fields []string) (string, error) {
+		switch {
+		case fields[0] == "publish" && len(fields) >= 3:
+			version, err := strconv.ParseUint(fields[2], 10, 8)
+			if err != nil {
+				return "", err
+			}
+			var regions []bizhawkRegion
+			for _, field := range fields[3:] {
+				var region bizhawkRegion
+				if _, err := fmt.Sscanf(field, "%x:%x:%x", &region.Start, &region.Length, &region.Offset); err != nil {
+					return "", err
+				}
+				regions = append(regions, region)
+			}
+			metadataPath = filepath.Join(fields[1], "GameHookBizHawkMetadata.bin")
+			dataPath = filepath.Join(fields[1], "GameHookBizHawkData.bin")
+			if err := os.WriteFile(dataPath, bizhawkTestData(), 0644); err != nil {
+				return "", err
+			}
+			return "ok", os.WriteFile(metadataPath, bizhawkMetadata(byte(version), 1234, regions), 0644)
+
+		case fields[0] == "poke" && len(fields) == 3:
+			offset, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return "", err
+			}
+			data, err := hex.DecodeString(fields[2])
+			if err != nil {
+				return "", err
+			}
+			return "ok", writeFileAt(dataPath, data, offset)
+
+		case fields[0] == "frame" && len(fields) == 2:
+			frame, err := strconv.ParseUint(fields[1], 10, 32)
+			if err != nil {
+				return "", err
+			}
+			return "ok", writeFileAt(metadataPath, binary.LittleEndian.AppendUint32(nil, uint32(frame)), bizhawkFrameOffset)
+
+		case fields[0] == "dump" && len(fields) == 3:
+			offset, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return "", err
+			}
+			length, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return "", err
+			}
+			data, err := os.ReadFile(dataPath)
+			if err != nil {
+				return "", err
+			}
+			return hex.EncodeToString(data[offset : offset+length]), nil
+		}
+		return "", fmt.Errorf("unknown command")
+	}
+
+	fmt.Println("ready")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		reply, err := run(fields)
+		if err != nil {
+			reply = "error " + err.Error()
+		}
+		fmt.Println(reply)
+	}
+	os.Exit(0)
+}
+
+// writeFileAt overwrites part of an existing file
+func writeFileAt(path string, data []byte, offset int64) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteAt(data, offset)
+	return err
+}
+
+// bizhawkCommand sends a command to the helper tool and returns its reply
+func bizhawkCommand(t *testing.T, helper *helperProcess, format string, args ...any) string {
+	t.Helper()
+	fmt.Fprintf(helper.stdin, format+"\n", args...)
+	reply := helper.readLine(t)
+	if strings.HasPrefix(reply, "error") {
+		t.Fatalf("helper tool: %s", reply)
+	}
+	return reply
+}
+
+// publishBizHawkFiles has a helper tool process publish the mapped files and
+// returns it along with their paths
+func publishBizHawkFiles(t *testing.T, version byte, regions []bizhawkRegion) (*helperProcess, string, string) {
+	helper := startHelperProcess(t, "TestBizHawkHelperProcess")
+	dir := t.TempDir()
+
+	command := fmt.Sprintf("publish %s %d", dir, version)
+	for _, region := range regions {
+		command += fmt.Sprintf(" %x:%x:%x", region.Start, region.Length, region.Offset)
+	}
+	bizhawkCommand(t, helper, "%s", command)
+
+	return helper, filepath.Join(dir, "GameHookBizHawkMetadata.bin"), filepath.Join(dir, "GameHookBizHawkData.bin")
+}
+
+// bizhawkTestRegions maps WRAM and echo RAM to adjacent bus ranges stored apart in the data file
+var bizhawkTestRegions = []bizhawkRegion{
+	{Start: 0xC000, Length: 0x2000, Offset: 0x0000},
+	{Start: 0xE000, Length: 0x0100, Offset: 0x2080},
+	{Start: 0xFF80, Length: 0x007F, Offset: 0x2000},
+}
+
+func bizhawkTestData() []byte {
+	data := make([]byte, 0x2180)
+	for i := range data {
+		data[i] = byte(i*5 + 1)
+	}
+	return data
+}
+
+func TestBizHawkDriverReadsRegions(t *testing.T) {
+	data := bizhawkTestData()
+	helper, metadataPath, dataPath := publishBizHawkFiles(t, 1, bizhawkTestRegions)
+
+	driver := NewBizHawkDriver(metadataPath, dataPath, time.Second)
+	defer driver.Close()
+
+	blocks, err := driver.ReadMemoryBlocks([]types.MemoryBlock{
+		{Name: "wram", Start: 0xC000, End: 0xC0FF},
+		{Name: "seam", Start: 0xDFF0, End: 0xE00F}, // Crosses into the next region
+		{Name: "hram", Start: 0xFF80, End: 0xFFFE},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(blocks[0xC000], data[:0x100]) {
+		t.Errorf("wram read % X", blocks[0xC000][:16])
+	}
+	if want := append(append([]byte(nil), data[0x1FF0:0x2000]...), data[0x2080:0x2090]...); !bytes.Equal(blocks[0xDFF0], want) {
+		t.Errorf("seam read % X, want % X", blocks[0xDFF0], want)
+	}
+	if !bytes.Equal(blocks[0xFF80], data[0x2000:0x207F]) {
+		t.Errorf("hram read % X", blocks[0xFF80][:16])
+	}
+
+	if name := driver.SystemName(); name != "GBC" {
+		t.Errorf("system name %q, want GBC", name)
+	}
+	if frame, err := driver.FrameCount(); err != nil || frame != 1234 {
+		t.Errorf("frame count %d (%v), want 1234", frame, err)
+	}
+
+	// Changes the tool makes show through the mapping without reconnecting
+	bizhawkCommand(t, helper, "poke %d %s", 0x2081, "aabb")
+	bizhawkCommand(t, helper, "frame %d", 1300)
+	if blocks, err = driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "echo", Start: 0xE000, End: 0xE002}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{data[0x2080], 0xAA, 0xBB}; !bytes.Equal(blocks[0xE000], want) {
+		t.Errorf("echo read % X after the tool changed it, want % X", blocks[0xE000], want)
+	}
+	if frame, err := driver.FrameCount(); err != nil || frame != 1300 {
+		t.Errorf("frame count %d (%v) after the tool advanced it, want 1300", frame, err)
+	}
+
+	if _, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "vram", Start: 0x8000, End: 0x8001}}); err == nil || !strings.Contains(err.Error(), "not exposed") {
+		t.Errorf("read outside the regions returned %v", err)
+	}
+}
+
+func TestBizHawkDriverWritesThroughMapping(t *testing.T) {
+	helper, metadataPath, dataPath := publishBizHawkFiles(t, 1, bizhawkTestRegions)
+
+	driver := NewBizHawkDriver(metadataPath, dataPath, time.Second)
+	defer driver.Close()
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte{0x11, 0x22, 0x33, 0x44}
+	if err := driver.WriteBytes(0xDFFE, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tool sees the write in the shared file
+	wram := bizhawkCommand(t, helper, "dump %d %d", 0x1FFE, 2)
+	echo := bizhawkCommand(t, helper, "dump %d %d", 0x2080, 2)
+	if wram != "1122" || echo != "3344" {
+		t.Errorf("tool sees %s and %s, want 1122 and 3344", wram, echo)
+	}
+
+	blocks, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "seam", Start: 0xDFFE, End: 0xE001}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(blocks[0xDFFE], payload) {
+		t.Errorf("read back % X", blocks[0xDFFE])
+	}
+
+	if err := driver.WriteBytes(0xFFFF, []byte{1}); err == nil {
+		t.Error("write outside the regions succeeded")
+	}
+}
+
+func TestBizHawkDriverRejectsBadMetadata(t *testing.T) {
+	_, metadataPath, dataPath := publishBizHawkFiles(t, 0, bizhawkTestRegions)
+	driver := NewBizHawkDriver(metadataPath, dataPath, 100*time.Millisecond)
+	if err := driver.Connect(); err == nil || !strings.Contains(err.Error(), "not ready") {
+		t.Errorf("connecting before the tool is ready returned %v", err)
+	}
+
+	regions := append([]bizhawkRegion(nil), bizhawkTestRegions...)
+	regions[1].Length = 0x1000
+	_, metadataPath, dataPath = publishBizHawkFiles(t, 1, regions)
+	driver = NewBizHawkDriver(metadataPath, dataPath, 0)
+	if err := driver.Connect(); err == nil || !strings.Contains(err.Error(), "exceeds data map size") {
+		t.Errorf("connecting with an oversized region returned %v", err)
+	}
+}
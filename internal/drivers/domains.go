@@ -0,0 +1,89 @@
+package drivers
+
+import (
+	"fmt"
+	"gamehook/internal/types"
+)
+
+// DomainDriver is implemented by drivers that address named memory domains
+// natively. ReadMemoryBlocks on these drivers honours MemoryBlock.Domain.
+type DomainDriver interface {
+	// HasDomain reports whether the driver can address domain directly
+	HasDomain(domain string) bool
+
+	// WriteDomainBytes writes data at an address inside domain
+	WriteDomainBytes(domain string, address uint32, data []byte) error
+}
+
+// DomainRouter sends domain-qualified reads and writes to a driver. Domains the
+// driver cannot address natively are placed on the system bus using busAddresses.
+type DomainRouter struct {
+	driver       Driver
+	busAddresses map[string]uint32 // Bus address of offset 0 in each domain
+}
+
+// NewDomainRouter creates a router for driver
+func NewDomainRouter(driver Driver, busAddresses map[string]uint32) *DomainRouter {
+	return &DomainRouter{
+		driver:       driver,
+		busAddresses: busAddresses,
+	}
+}
+
+// Resolve returns the blocks to request from the driver for one domain and the
+// offset to subtract from the keys of the driver's result
+func (r *DomainRouter) Resolve(domain string, blocks []types.MemoryBlock) ([]types.MemoryBlock, uint32, error) {
+	if domain == "" {
+		return blocks, 0, nil
+	}
+
+	if native, ok := r.driver.(DomainDriver); ok && native.HasDomain(domain) {
+		return blocks, 0, nil
+	}
+
+	base, ok := r.busAddresses[domain]
+	if !ok {
+		return nil, 0, fmt.Errorf("driver cannot address memory domain %q and it has no bus address", domain)
+	}
+
+	resolved := make([]types.MemoryBlock, len(blocks))
+	for i, block := range blocks {
+		resolved[i] = types.MemoryBlock{
+			Name:        block.Name,
+			Start:       base + block.Start,
+			End:         base + block.End,
+			Parent:      block.Parent,
+			ParentStart: base + block.ParentStart,
+		}
+	}
+	return resolved, base, nil
+}
+
+// WriteBytes writes data at an address inside domain
+func (r *DomainRouter) WriteBytes(domain string, address uint32, data []byte) error {
+	if domain == "" {
+		return r.driver.WriteBytes(address, data)
+	}
+
+	if native, ok := r.driver.(DomainDriver); ok && native.HasDomain(domain) {
+		return native.WriteDomainBytes(domain, address, data)
+	}
+
+	base, ok := r.busAddresses[domain]
+	if !ok {
+		return fmt.Errorf("driver cannot address memory domain %q and it has no bus address", domain)
+	}
+	return r.driver.WriteBytes(base+address, data)
+}
+
+// Rekey shifts result keys from bus addresses back to domain addresses
+func Rekey(data map[uint32][]byte, offset uint32) map[uint32][]byte {
+	if offset == 0 {
+		return data
+	}
+	rekeyed := make(map[uint32][]byte, len(data))
+	for address, bytes := range data {
+		rekeyed[address-offset] = bytes
+	}
+	return rekeyed
+}
@@ -16,3 +16,49 @@ type Driver interface {
 	// Close closes the connection
 	Close() error
 }
+
+// StatsReporter is implemented by drivers that expose performance counters
+type StatsReporter interface {
+	// Stats returns driver-specific counters such as read latency
+	Stats() map[string]interface{}
+}
+
+// EmulatorController is implemented by drivers that can control emulation as
+// well as access memory
+type EmulatorController interface {
+	// EmulationStatus reports the run state and loaded content
+	EmulationStatus() (EmulationStatus, error)
+
+	// Pause pauses emulation; pausing a paused emulator does nothing
+	Pause() error
+
+	// Resume resumes paused emulation
+	Resume() error
+
+	// TogglePause switches between paused and running
+	TogglePause() error
+
+	// FrameAdvance pauses emulation and advances the given number of frames
+	FrameAdvance(frames int) error
+
+	// Reset resets the running content
+	Reset() error
+
+	// SaveState saves to the current state slot
+	SaveState() error
+
+	// LoadState loads from the current state slot
+	LoadState() error
+
+	// ChangeStateSlot moves the current state slot up (positive) or down (negative)
+	ChangeStateSlot(delta int) error
+}
+
+// EmulationStatus describes what the emulator is doing
+type EmulationStatus struct {
+	State   string `json:"state"` // "playing", "paused" or "contentless"
+	Paused  bool   `json:"paused"`
+	System  string `json:"system,omitempty"`
+	Content string `json:"content,omitempty"`
+	CRC32   string `json:"crc32,omitempty"`
+}
@@ -0,0 +1,259 @@
+package drivers
+
+import (
+	"fmt"
+	"gamehook/internal/types"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileImage is an in-memory copy of a dump file mapped at a bus address
+type fileImage struct {
+	start uint32
+	data  []byte
+	path  string
+}
+
+// contains reports whether address falls inside the image
+func (img *fileImage) contains(address uint32) bool {
+	return address >= img.start && uint64(address) < uint64(img.start)+uint64(len(img.data))
+}
+
+// overlap returns the part of [from, to) that the image covers
+func (img *fileImage) overlap(from, to uint64) (uint64, uint64, bool) {
+	imgStart := uint64(img.start)
+	imgEnd := imgStart + uint64(len(img.data))
+	if from >= imgEnd || to <= imgStart {
+		return 0, 0, false
+	}
+	if from < imgStart {
+		from = imgStart
+	}
+	if to > imgEnd {
+		to = imgEnd
+	}
+	return from, to, true
+}
+
+// FileDriver serves memory from raw dump files so mappers can be developed offline.
+//
+// The dump path is either a single flat image mapped at baseAddress, or a
+// directory holding one file per memory block named "<block name>.bin" or
+// "<start address in hex>.bin" (e.g. "C000.bin").
+type FileDriver struct {
+	dumpPath    string
+	baseAddress uint32
+	writeBack   bool
+
+	mu        sync.RWMutex
+	connected bool
+	flat      bool
+	images    []*fileImage
+	missing   map[uint32]bool
+}
+
+// NewFileDriver creates a new file-backed driver
+func NewFileDriver(dumpPath string, baseAddress uint32, writeBack bool) *FileDriver {
+	return &FileDriver{
+		dumpPath:    dumpPath,
+		baseAddress: baseAddress,
+		writeBack:   writeBack,
+		missing:     make(map[uint32]bool),
+	}
+}
+
+// Connect checks the dump path and loads the flat image if one is used.
+// Already loaded images are kept so unsaved writes survive reconnect checks.
+func (d *FileDriver) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.connected {
+		return nil
+	}
+
+	info, err := os.Stat(d.dumpPath)
+	if err != nil {
+		return fmt.Errorf("failed to open dump: %w", err)
+	}
+
+	d.images = nil
+	d.missing = make(map[uint32]bool)
+	d.flat = !info.IsDir()
+
+	if d.flat {
+		data, err := os.ReadFile(d.dumpPath)
+		if err != nil {
+			return fmt.Errorf("failed to read dump: %w", err)
+		}
+		d.images = append(d.images, &fileImage{start: d.baseAddress, data: data, path: d.dumpPath})
+		fmt.Printf("📂 Loaded flat memory image %s (%d bytes at 0x%X)\n", d.dumpPath, len(data), d.baseAddress)
+	} else {
+		fmt.Printf("📂 Serving memory blocks from %s\n", d.dumpPath)
+	}
+
+	d.connected = true
+	return nil
+}
+
+// ReadMemoryBlocks reads multiple memory blocks from the dump files
+func (d *FileDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	d.mu.RLock()
+	connected := d.connected
+	d.mu.RUnlock()
+
+	if !connected {
+		if err := d.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := make(map[uint32][]byte)
+
+	for _, block := range blocks {
+		if !d.flat {
+			if err := d.loadBlockLocked(block); err != nil {
+				return nil, fmt.Errorf("failed to read block %s: %w", block.Name, err)
+			}
+		}
+
+		data := make([]byte, block.End-block.Start+1)
+		if copied := d.copyLocked(block.Start, data); copied < len(data) && !d.missing[block.Start] {
+			// Bytes not covered by any dump read as zero
+			fmt.Printf("⚠️  Dump only covers %d of %d bytes for block %s\n", copied, len(data), block.Name)
+			d.missing[block.Start] = true
+		}
+		result[block.Start] = data
+	}
+
+	return result, nil
+}
+
+// loadBlockLocked loads the dump file for a block in directory mode. Planned
+// ranges load the file of the declared block they lie within, once.
+func (d *FileDriver) loadBlockLocked(block types.MemoryBlock) error {
+	name, start := block.Name, block.Start
+	if block.Parent != "" {
+		name, start = block.Parent, block.ParentStart
+	}
+
+	for _, img := range d.images {
+		if img.start == start {
+			return nil
+		}
+	}
+
+	candidates := []string{
+		fmt.Sprintf("%04X.bin", start),
+		fmt.Sprintf("%04x.bin", start),
+	}
+	if name != "" {
+		candidates = append([]string{name + ".bin"}, candidates...)
+	}
+
+	for _, name := range candidates {
+		path := filepath.Join(d.dumpPath, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		d.images = append(d.images, &fileImage{start: start, data: data, path: path})
+		return nil
+	}
+
+	// Keep an empty image so the lookup is not repeated every tick
+	d.images = append(d.images, &fileImage{start: start})
+	return nil
+}
+
+// copyLocked copies image bytes starting at address into buf and returns how many were covered
+func (d *FileDriver) copyLocked(address uint32, buf []byte) int {
+	covered := 0
+	end := uint64(address) + uint64(len(buf))
+
+	for _, img := range d.images {
+		from, to, ok := img.overlap(uint64(address), end)
+		if !ok {
+			continue
+		}
+		covered += copy(buf[from-uint64(address):to-uint64(address)], img.data[from-uint64(img.start):to-uint64(img.start)])
+	}
+	return covered
+}
+
+// WriteBytes applies data to the in-memory copy and optionally writes it back to disk
+func (d *FileDriver) WriteBytes(address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Resolve every target byte first so a failed write leaves the images untouched
+	targets := make([]*fileImage, len(data))
+	for i := range data {
+		current := address + uint32(i)
+		for _, img := range d.images {
+			if img.contains(current) {
+				targets[i] = img
+				break
+			}
+		}
+		if targets[i] == nil {
+			return fmt.Errorf("address 0x%X is not covered by any dump", current)
+		}
+	}
+
+	for i, target := range targets {
+		target.data[address+uint32(i)-target.start] = data[i]
+	}
+
+	if d.writeBack {
+		return d.writeBackLocked(address, uint32(len(data)))
+	}
+
+	return nil
+}
+
+// writeBackLocked persists the written range to the dump files it touches
+func (d *FileDriver) writeBackLocked(address uint32, length uint32) error {
+	for _, img := range d.images {
+		from, to, ok := img.overlap(uint64(address), uint64(address)+uint64(length))
+		if !ok {
+			continue
+		}
+
+		f, err := os.OpenFile(img.path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open %s for write-back: %w", img.path, err)
+		}
+
+		offset := from - uint64(img.start)
+		_, err = f.WriteAt(img.data[offset:to-uint64(img.start)], int64(offset))
+		closeErr := f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write back to %s: %w", img.path, err)
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+	}
+
+	return nil
+}
+
+// Close releases the in-memory images
+func (d *FileDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.images = nil
+	d.flat = false
+	d.connected = false
+	return nil
+}
@@ -0,0 +1,177 @@
+package drivers
+
+import (
+	"bytes"
+	"gamehook/internal/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// sequence returns length bytes counting up from first
+func sequence(first byte, length int) []byte {
+	data := make([]byte, length)
+	for i := range data {
+		data[i] = first + byte(i)
+	}
+	return data
+}
+
+func writeDump(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readDump(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestFileDriverFlatImage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wram.bin")
+	writeDump(t, path, sequence(0x00, 16))
+
+	driver := NewFileDriver(path, 0xC000, false)
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Close()
+
+	result, err := driver.ReadMemoryBlocks([]types.MemoryBlock{
+		{Name: "inside", Start: 0xC004, End: 0xC007},
+		{Name: "overhang", Start: 0xC00E, End: 0xC011},
+		{Name: "outside", Start: 0xD000, End: 0xD001},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uint32][]byte{
+		0xC004: {0x04, 0x05, 0x06, 0x07},
+		0xC00E: {0x0E, 0x0F, 0x00, 0x00}, // Bytes past the dump read as zero
+		0xD000: {0x00, 0x00},
+	}
+	for start, data := range want {
+		if !bytes.Equal(result[start], data) {
+			t.Errorf("block at 0x%X read % X, want % X", start, result[start], data)
+		}
+	}
+
+	// Writes change the loaded copy but not the file without write-back
+	if err := driver.WriteBytes(0xC001, []byte{0xAA, 0xBB}); err != nil {
+		t.Fatal(err)
+	}
+	result, _ = driver.ReadMemoryBlocks([]types.MemoryBlock{{Start: 0xC000, End: 0xC003}})
+	if !bytes.Equal(result[0xC000], []byte{0x00, 0xAA, 0xBB, 0x03}) {
+		t.Errorf("read after write % X", result[0xC000])
+	}
+	if data := readDump(t, path); !bytes.Equal(data, sequence(0x00, 16)) {
+		t.Errorf("dump changed without write-back: % X", data)
+	}
+
+	// A write running past the dump is rejected whole
+	if err := driver.WriteBytes(0xC00F, []byte{0xCC, 0xDD}); err == nil {
+		t.Error("write past the end of the dump succeeded")
+	}
+	result, _ = driver.ReadMemoryBlocks([]types.MemoryBlock{{Start: 0xC00F, End: 0xC00F}})
+	if !bytes.Equal(result[0xC00F], []byte{0x0F}) {
+		t.Errorf("rejected write changed the image: % X", result[0xC00F])
+	}
+
+	// Reconnecting reloads the file and drops unsaved writes
+	driver.Close()
+	result, err = driver.ReadMemoryBlocks([]types.MemoryBlock{{Start: 0xC000, End: 0xC003}})
+	if err != nil || !bytes.Equal(result[0xC000], sequence(0x00, 4)) {
+		t.Errorf("read after reconnect % X (%v)", result[0xC000], err)
+	}
+}
+
+func TestFileDriverDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeDump(t, filepath.Join(dir, "wram.bin"), sequence(0x10, 8))
+	writeDump(t, filepath.Join(dir, "D000.bin"), sequence(0x20, 4))
+	writeDump(t, filepath.Join(dir, "e000.bin"), sequence(0x30, 4))
+
+	driver := NewFileDriver(dir, 0, false)
+	defer driver.Close()
+
+	result, err := driver.ReadMemoryBlocks([]types.MemoryBlock{
+		{Name: "wram", Start: 0xC000, End: 0xC007},                        // Found by block name
+		{Name: "hram", Start: 0xD000, End: 0xD003},                        // By upper-case start address
+		{Name: "echo", Start: 0xE000, End: 0xE003},                        // By lower-case start address
+		{Name: "vram", Start: 0x8000, End: 0x8001},                        // No dump at all
+		{Start: 0xC004, End: 0xC005, Parent: "wram", ParentStart: 0xC000}, // A planned range
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uint32][]byte{
+		0xC000: sequence(0x10, 8),
+		0xD000: sequence(0x20, 4),
+		0xE000: sequence(0x30, 4),
+		0x8000: {0x00, 0x00},
+		0xC004: {0x14, 0x15},
+	}
+	for start, data := range want {
+		if !bytes.Equal(result[start], data) {
+			t.Errorf("block at 0x%X read % X, want % X", start, result[start], data)
+		}
+	}
+
+	// Blocks without a dump can't be written
+	if err := driver.WriteBytes(0x8000, []byte{1}); err == nil {
+		t.Error("write to a block without a dump succeeded")
+	}
+}
+
+func TestFileDriverWriteBack(t *testing.T) {
+	dir := t.TempDir()
+	writeDump(t, filepath.Join(dir, "C000.bin"), sequence(0x00, 4))
+	writeDump(t, filepath.Join(dir, "C004.bin"), sequence(0x04, 4))
+
+	driver := NewFileDriver(dir, 0, true)
+	defer driver.Close()
+	if _, err := driver.ReadMemoryBlocks([]types.MemoryBlock{
+		{Start: 0xC000, End: 0xC003},
+		{Start: 0xC004, End: 0xC007},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A write across two adjacent dumps lands in both files
+	if err := driver.WriteBytes(0xC002, []byte{0xAA, 0xBB, 0xCC, 0xDD}); err != nil {
+		t.Fatal(err)
+	}
+	if data := readDump(t, filepath.Join(dir, "C000.bin")); !bytes.Equal(data, []byte{0x00, 0x01, 0xAA, 0xBB}) {
+		t.Errorf("C000.bin after write-back: % X", data)
+	}
+	if data := readDump(t, filepath.Join(dir, "C004.bin")); !bytes.Equal(data, []byte{0xCC, 0xDD, 0x06, 0x07}) {
+		t.Errorf("C004.bin after write-back: % X", data)
+	}
+
+	// A dump deleted after loading fails the write-back
+	if err := os.Remove(filepath.Join(dir, "C004.bin")); err != nil {
+		t.Fatal(err)
+	}
+	if err := driver.WriteBytes(0xC004, []byte{0xEE}); err == nil {
+		t.Error("write-back to a deleted dump succeeded")
+	}
+}
+
+func TestFileDriverMissingDump(t *testing.T) {
+	driver := NewFileDriver(filepath.Join(t.TempDir(), "missing.bin"), 0xC000, false)
+	if err := driver.Connect(); err == nil {
+		t.Error("connected to a missing dump")
+	}
+	if _, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Start: 0xC000, End: 0xC000}}); err == nil {
+		t.Error("read from a missing dump succeeded")
+	}
+	if err := driver.WriteBytes(0xC000, []byte{1}); err == nil {
+		t.Error("write to a missing dump succeeded")
+	}
+}
@@ -0,0 +1,389 @@
+package drivers
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"gamehook/internal/types"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultGDBChunkSize  = 1024 // Safe m/M packet size until qSupported reports PacketSize
+	maxGDBConsolePackets = 64   // Console output packets skipped while waiting for a reply
+)
+
+// GDBDriver talks to an emulator's GDB stub using the remote serial protocol over TCP
+type GDBDriver struct {
+	host           string
+	port           int
+	requestTimeout time.Duration
+	maxChunkSize   uint32 // Maximum bytes per m/M packet
+	maxRetries     int    // Retransmissions after a NAK
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewGDBDriver creates a new GDB remote serial protocol driver
+func NewGDBDriver(host string, port int, requestTimeout time.Duration) *GDBDriver {
+	return &GDBDriver{
+		host:           host,
+		port:           port,
+		requestTimeout: requestTimeout,
+		maxChunkSize:   defaultGDBChunkSize,
+		maxRetries:     3,
+	}
+}
+
+// Connect establishes the TCP connection and negotiates the packet size
+func (d *GDBDriver) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closeLocked()
+
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", d.host, d.port), d.requestTimeout*10)
+	if err != nil {
+		return fmt.Errorf("failed to connect to GDB stub: %w", err)
+	}
+
+	d.conn = conn
+	d.reader = bufio.NewReader(conn)
+
+	// The stub may have been replaced by one with a smaller packet size
+	d.maxChunkSize = defaultGDBChunkSize
+
+	// qSupported is optional; stubs that don't know it reply with an empty packet
+	response, err := d.transactLocked("qSupported:multiprocess-")
+	if err != nil {
+		d.closeLocked()
+		return fmt.Errorf("failed to communicate with GDB stub: %w", err)
+	}
+
+	for _, feature := range strings.Split(response, ";") {
+		if !strings.HasPrefix(feature, "PacketSize=") {
+			continue
+		}
+		size, err := strconv.ParseUint(strings.TrimPrefix(feature, "PacketSize="), 16, 32)
+		if err != nil || size < 64 {
+			continue
+		}
+		// Each byte is two hex digits, leave room for the command and checksum
+		d.maxChunkSize = uint32(size-32) / 2
+	}
+
+	fmt.Printf("🔌 Connected to GDB stub at %s:%d (chunk size %d bytes)\n", d.host, d.port, d.maxChunkSize)
+	return nil
+}
+
+// ReadMemoryBlocks reads multiple memory blocks from the GDB stub
+func (d *GDBDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	d.mu.Lock()
+	connected := d.conn != nil
+	d.mu.Unlock()
+
+	if !connected {
+		if err := d.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[uint32][]byte)
+
+	for _, block := range blocks {
+		data, err := d.ReadMemory(block.Start, block.End-block.Start+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block %s: %w", block.Name, err)
+		}
+		result[block.Start] = data
+	}
+
+	return result, nil
+}
+
+// ReadMemory reads memory in chunks using m packets
+func (d *GDBDriver) ReadMemory(address uint32, length uint32) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return nil, fmt.Errorf("not connected to GDB stub")
+	}
+
+	result := make([]byte, 0, length)
+	remaining := length
+	currentAddr := address
+
+	for remaining > 0 {
+		chunkSize := d.maxChunkSize
+		if remaining < chunkSize {
+			chunkSize = remaining
+		}
+
+		chunk, err := d.readChunkLocked(currentAddr, chunkSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk at 0x%X: %w", currentAddr, err)
+		}
+
+		result = append(result, chunk...)
+		currentAddr += chunkSize
+		remaining -= chunkSize
+	}
+
+	return result, nil
+}
+
+// readChunkLocked reads a single chunk with an m packet
+func (d *GDBDriver) readChunkLocked(address uint32, length uint32) ([]byte, error) {
+	response, err := d.transactLocked(fmt.Sprintf("m%x,%x", address, length))
+	if err != nil {
+		return nil, err
+	}
+
+	if isGDBError(response) {
+		return nil, fmt.Errorf("GDB stub returned %s for address 0x%X", response, address)
+	}
+
+	data, err := hex.DecodeString(response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory reply: %w", err)
+	}
+
+	// Stubs may return fewer bytes than requested at the end of a region
+	if uint32(len(data)) != length {
+		return nil, fmt.Errorf("expected %d bytes, got %d", length, len(data))
+	}
+
+	return data, nil
+}
+
+// WriteBytes writes bytes using M packets
+func (d *GDBDriver) WriteBytes(address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return fmt.Errorf("not connected to GDB stub")
+	}
+
+	offset := 0
+	for offset < len(data) {
+		chunkSize := int(d.maxChunkSize)
+		if len(data)-offset < chunkSize {
+			chunkSize = len(data) - offset
+		}
+
+		currentAddr := address + uint32(offset)
+		chunk := data[offset : offset+chunkSize]
+
+		response, err := d.transactLocked(fmt.Sprintf("M%x,%x:%s", currentAddr, len(chunk), hex.EncodeToString(chunk)))
+		if err != nil {
+			return fmt.Errorf("failed to write chunk at 0x%X: %w", currentAddr, err)
+		}
+		if response != "OK" {
+			return fmt.Errorf("GDB stub rejected write at 0x%X: %s", currentAddr, response)
+		}
+
+		offset += chunkSize
+	}
+
+	return nil
+}
+
+// Close closes the connection, detaching from the stub first
+func (d *GDBDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn != nil {
+		// Best effort: let the emulator keep running after we leave
+		d.conn.SetDeadline(time.Now().Add(d.requestTimeout))
+		d.conn.Write([]byte(encodeGDBPacket("D")))
+	}
+
+	return d.closeLocked()
+}
+
+// closeLocked closes the TCP connection
+func (d *GDBDriver) closeLocked() error {
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	d.reader = nil
+	return err
+}
+
+// transactLocked sends a packet, waits for its ack and returns the reply payload
+func (d *GDBDriver) transactLocked(payload string) (string, error) {
+	if err := d.conn.SetDeadline(time.Now().Add(d.requestTimeout)); err != nil {
+		return "", fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	packet := []byte(encodeGDBPacket(payload))
+
+	acked := false
+	for attempt := 0; attempt <= d.maxRetries && !acked; attempt++ {
+		if _, err := d.conn.Write(packet); err != nil {
+			return "", fmt.Errorf("failed to send packet: %w", err)
+		}
+
+		ack, err := d.reader.ReadByte()
+		if err != nil {
+			return "", fmt.Errorf("failed to read ack: %w", err)
+		}
+
+		switch ack {
+		case '+':
+			acked = true
+		case '-':
+			// Corrupted in transit, retransmit
+		case '$':
+			// Stub already in no-ack mode and sent the reply directly
+			d.reader.UnreadByte()
+			acked = true
+		default:
+			return "", fmt.Errorf("unexpected ack byte 0x%02X", ack)
+		}
+	}
+
+	if !acked {
+		return "", fmt.Errorf("packet not acknowledged after %d attempts", d.maxRetries+1)
+	}
+
+	consolePackets := 0
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		reply, err := d.readPacketLocked()
+		if err == errGDBChecksum {
+			d.conn.Write([]byte("-"))
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		d.conn.Write([]byte("+"))
+
+		// Console output packets can arrive before the real reply
+		if strings.HasPrefix(reply, "O") && reply != "OK" && isHexString(reply[1:]) {
+			if consolePackets++; consolePackets > maxGDBConsolePackets {
+				// The reply may still arrive, so the stream can't be trusted anymore
+				d.closeLocked()
+				return "", fmt.Errorf("no reply after %d console output packets", maxGDBConsolePackets)
+			}
+			attempt--
+			continue
+		}
+
+		return reply, nil
+	}
+
+	return "", fmt.Errorf("reply checksum failed after %d attempts", d.maxRetries+1)
+}
+
+// errGDBChecksum is returned when a received packet fails its checksum
+var errGDBChecksum = fmt.Errorf("GDB packet checksum mismatch")
+
+// readPacketLocked reads one $payload#xx packet and returns the decoded payload
+func (d *GDBDriver) readPacketLocked() (string, error) {
+	// Skip anything before the packet start (stray acks, notifications)
+	for {
+		b, err := d.reader.ReadByte()
+		if err != nil {
+			return "", fmt.Errorf("failed to read reply: %w", err)
+		}
+		if b == '$' {
+			break
+		}
+	}
+
+	body, err := d.reader.ReadBytes('#')
+	if err != nil {
+		return "", fmt.Errorf("failed to read reply: %w", err)
+	}
+	body = body[:len(body)-1]
+
+	checksum := make([]byte, 2)
+	if _, err := io.ReadFull(d.reader, checksum); err != nil {
+		return "", fmt.Errorf("failed to read reply checksum: %w", err)
+	}
+
+	expected, err := strconv.ParseUint(string(checksum), 16, 8)
+	if err != nil || uint8(expected) != gdbChecksum(body) {
+		return "", errGDBChecksum
+	}
+
+	return decodeGDBPayload(body), nil
+}
+
+// encodeGDBPacket frames a payload as $payload#checksum, escaping special characters
+func encodeGDBPacket(payload string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		if c == '$' || c == '#' || c == '}' || c == '*' {
+			escaped.WriteByte('}')
+			escaped.WriteByte(c ^ 0x20)
+		} else {
+			escaped.WriteByte(c)
+		}
+	}
+
+	body := escaped.String()
+	return fmt.Sprintf("$%s#%02x", body, gdbChecksum([]byte(body)))
+}
+
+// decodeGDBPayload expands run-length encoding and escapes in a reply body
+func decodeGDBPayload(body []byte) string {
+	out := make([]byte, 0, len(body))
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case c == '}' && i+1 < len(body):
+			i++
+			out = append(out, body[i]^0x20)
+		case c == '*' && i+1 < len(body) && len(out) > 0:
+			i++
+			repeat := int(body[i]) - 29
+			last := out[len(out)-1]
+			for j := 0; j < repeat; j++ {
+				out = append(out, last)
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return string(out)
+}
+
+// gdbChecksum returns the modulo-256 sum of the packet body
+func gdbChecksum(body []byte) uint8 {
+	var sum uint8
+	for _, b := range body {
+		sum += b
+	}
+	return sum
+}
+
+// isGDBError reports whether a reply is an Exx error packet
+func isGDBError(reply string) bool {
+	return len(reply) == 3 && reply[0] == 'E' && isHexString(reply[1:])
+}
+
+// isHexString reports whether s consists only of hex digits
+func isHexString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
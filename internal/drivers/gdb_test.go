@@ -0,0 +1,409 @@
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"gamehook/internal/types"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gdbStub is a minimal GDB remote serial protocol server over a fake RAM buffer
+type gdbStub struct {
+	t        *testing.T
+	listener net.Listener
+	base     uint32
+	packet   int // PacketSize reported by qSupported
+
+	mu          sync.Mutex
+	memory      []byte
+	reads       []uint32 // Lengths of m packets
+	writes      []uint32 // Lengths of M packets
+	nackNext    bool     // Reject the next packet once
+	corruptNext bool     // Send the next reply with a bad checksum once
+	consoleNext int      // Console output packets to send before the next reply
+	nacksSent   int
+	nacksGot    int
+}
+
+func newGDBStub(t *testing.T, base uint32, memory []byte, packetSize int) *gdbStub {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &gdbStub{t: t, listener: listener, base: base, memory: memory, packet: packetSize}
+	go stub.serve()
+	t.Cleanup(func() { listener.Close() })
+	return stub
+}
+
+func (s *gdbStub) driver() *GDBDriver {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return NewGDBDriver("127.0.0.1", addr.Port, time.Second)
+}
+
+func (s *gdbStub) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *gdbStub) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		payload, ok := s.readPacket(reader)
+		if !ok {
+			return
+		}
+
+		s.mu.Lock()
+		nack := s.nackNext
+		s.nackNext = false
+		s.mu.Unlock()
+		if nack {
+			s.mu.Lock()
+			s.nacksSent++
+			s.mu.Unlock()
+			conn.Write([]byte("-"))
+			continue
+		}
+		conn.Write([]byte("+"))
+
+		reply := s.reply(payload)
+		if payload == "D" {
+			conn.Write([]byte(encodeGDBPacket(reply)))
+			return
+		}
+
+		s.mu.Lock()
+		corrupt := s.corruptNext
+		s.corruptNext = false
+		console := s.consoleNext
+		s.consoleNext = 0
+		s.mu.Unlock()
+
+		for i := 0; i < console; i++ {
+			output := "O" + hex.EncodeToString([]byte("log\n"))
+			conn.Write([]byte(encodeGDBPacket(output)))
+			if ack, err := reader.ReadByte(); err != nil || ack != '+' {
+				// The driver may give up and close the connection
+				return
+			}
+		}
+
+		packet := "$" + reply + fmt.Sprintf("#%02x", gdbChecksum([]byte(reply)))
+		if corrupt {
+			conn.Write([]byte("$" + reply + fmt.Sprintf("#%02x", gdbChecksum([]byte(reply))+1)))
+			if ack, err := reader.ReadByte(); err != nil || ack != '-' {
+				s.t.Errorf("corrupted reply answered with %q, want '-'", ack)
+				return
+			}
+			s.mu.Lock()
+			s.nacksGot++
+			s.mu.Unlock()
+		}
+		conn.Write([]byte(packet))
+		ack, err := reader.ReadByte()
+		if err != nil {
+			return // The driver gave up and closed the connection
+		}
+		if ack != '+' {
+			s.t.Errorf("reply answered with %q, want '+'", ack)
+			return
+		}
+	}
+}
+
+// readPacket reads one $payload#xx packet, checking its checksum
+func (s *gdbStub) readPacket(reader *bufio.Reader) (string, bool) {
+	if _, err := reader.ReadString('$'); err != nil {
+		return "", false
+	}
+	body, err := reader.ReadBytes('#')
+	if err != nil {
+		return "", false
+	}
+	body = body[:len(body)-1]
+
+	checksum := make([]byte, 2)
+	if _, err := io.ReadFull(reader, checksum); err != nil {
+		return "", false
+	}
+	if want := fmt.Sprintf("%02x", gdbChecksum(body)); string(checksum) != want {
+		s.t.Errorf("packet %q has checksum %s, want %s", body, checksum, want)
+	}
+	return decodeGDBPayload(body), true
+}
+
+// reply handles one command and returns the reply payload
+func (s *gdbStub) reply(payload string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(payload, "qSupported") && s.packet == 0:
+		return "" // A stub that doesn't know qSupported
+	case strings.HasPrefix(payload, "qSupported"):
+		return fmt.Sprintf("PacketSize=%x;qXfer:features:read-", s.packet)
+	case payload == "D":
+		return "OK"
+	case strings.HasPrefix(payload, "m"):
+		var address, length uint32
+		fmt.Sscanf(payload, "m%x,%x", &address, &length)
+		s.reads = append(s.reads, length)
+		offset, ok := s.offset(address, length)
+		if !ok {
+			return "E01"
+		}
+		return encodeRLE(hex.EncodeToString(s.memory[offset : offset+length]))
+	case strings.HasPrefix(payload, "M"):
+		header, data, _ := strings.Cut(payload[1:], ":")
+		addressHex, lengthHex, _ := strings.Cut(header, ",")
+		address, _ := strconv.ParseUint(addressHex, 16, 32)
+		length, _ := strconv.ParseUint(lengthHex, 16, 32)
+		s.writes = append(s.writes, uint32(length))
+		decoded, err := hex.DecodeString(data)
+		offset, ok := s.offset(uint32(address), uint32(length))
+		if err != nil || !ok || len(decoded) != int(length) {
+			return "E02"
+		}
+		copy(s.memory[offset:], decoded)
+		return "OK"
+	}
+	return ""
+}
+
+func (s *gdbStub) offset(address, length uint32) (uint32, bool) {
+	if address < s.base || uint64(address-s.base)+uint64(length) > uint64(len(s.memory)) {
+		return 0, false
+	}
+	return address - s.base, true
+}
+
+// encodeRLE run-length encodes a reply the way GDB stubs do
+func encodeRLE(payload string) string {
+	var out strings.Builder
+	for i := 0; i < len(payload); {
+		run := 1
+		for i+run < len(payload) && payload[i+run] == payload[i] {
+			run++
+		}
+
+		repeat := min(run-1, 97)
+		for repeat >= 3 && strings.ContainsRune("#$+-", rune(repeat+29)) {
+			repeat--
+		}
+		if repeat < 3 {
+			out.WriteByte(payload[i])
+			i++
+			continue
+		}
+		out.WriteByte(payload[i])
+		out.WriteByte('*')
+		out.WriteByte(byte(repeat + 29))
+		i += repeat + 1
+	}
+	return out.String()
+}
+
+func gdbTestMemory() []byte {
+	memory := make([]byte, 0x400)
+	for i := range memory {
+		// Runs of repeated bytes exercise run-length encoded replies
+		if i%64 < 40 {
+			memory[i] = byte(i * 13)
+		}
+	}
+	return memory
+}
+
+func TestGDBDriverReadsInPacketSizeChunks(t *testing.T) {
+	memory := gdbTestMemory()
+	stub := newGDBStub(t, 0x1000, append([]byte(nil), memory...), 0xA0)
+	driver := stub.driver()
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	// (0xA0 - 32) / 2
+	if driver.maxChunkSize != 64 {
+		t.Fatalf("chunk size %d, want 64", driver.maxChunkSize)
+	}
+
+	data, err := driver.ReadMemoryBlocks([]types.MemoryBlock{
+		{Name: "wram", Start: 0x1000, End: 0x10FF},
+		{Name: "tail", Start: 0x1300, End: 0x1310},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[0x1000], memory[:0x100]) {
+		t.Errorf("wram read % X", data[0x1000])
+	}
+	if !bytes.Equal(data[0x1300], memory[0x300:0x311]) {
+		t.Errorf("tail read % X", data[0x1300])
+	}
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	want := []uint32{64, 64, 64, 64, 17}
+	if fmt.Sprint(stub.reads) != fmt.Sprint(want) {
+		t.Errorf("m packet lengths %v, want %v", stub.reads, want)
+	}
+}
+
+func TestGDBDriverRetransmits(t *testing.T) {
+	memory := gdbTestMemory()
+	stub := newGDBStub(t, 0x1000, append([]byte(nil), memory...), 0xA0)
+	driver := stub.driver()
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A NAK makes the driver resend its packet
+	stub.mu.Lock()
+	stub.nackNext = true
+	stub.mu.Unlock()
+	data, err := driver.ReadMemory(0x1000, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, memory[:16]) {
+		t.Errorf("read after NAK % X", data)
+	}
+
+	// A reply with a bad checksum is answered with '-' and sent again
+	stub.mu.Lock()
+	stub.corruptNext = true
+	stub.mu.Unlock()
+	data, err = driver.ReadMemory(0x1010, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, memory[0x10:0x20]) {
+		t.Errorf("read after bad checksum % X", data)
+	}
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if stub.nacksSent != 1 || stub.nacksGot != 1 {
+		t.Errorf("sent %d and received %d NAKs, want 1 each", stub.nacksSent, stub.nacksGot)
+	}
+}
+
+func TestGDBDriverWritesAndErrors(t *testing.T) {
+	stub := newGDBStub(t, 0x1000, gdbTestMemory(), 0xA0)
+	driver := stub.driver()
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	// '#', '$' and '}' in the data must survive escaping
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = "#$}*"[i%4]
+	}
+	if err := driver.WriteBytes(0x1010, data); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := driver.ReadMemory(0x1010, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Errorf("read back % X", read)
+	}
+
+	stub.mu.Lock()
+	if fmt.Sprint(stub.writes) != "[64 36]" {
+		t.Errorf("M packet lengths %v, want [64 36]", stub.writes)
+	}
+	stub.mu.Unlock()
+
+	if _, err := driver.ReadMemory(0x2000, 4); err == nil || !strings.Contains(err.Error(), "E01") {
+		t.Errorf("read outside memory returned %v, want E01", err)
+	}
+}
+
+func TestGDBDriverSkipsBoundedConsoleOutput(t *testing.T) {
+	memory := gdbTestMemory()
+	stub := newGDBStub(t, 0x1000, append([]byte(nil), memory...), 0xA0)
+	driver := stub.driver()
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Console output before the reply is acknowledged and skipped
+	stub.mu.Lock()
+	stub.consoleNext = maxGDBConsolePackets
+	stub.mu.Unlock()
+	read, err := driver.ReadMemory(0x1000, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, memory[:16]) {
+		t.Errorf("read after console output % X", read)
+	}
+
+	// A stub that never stops printing doesn't hold the driver forever
+	stub.mu.Lock()
+	stub.consoleNext = maxGDBConsolePackets + 1
+	stub.mu.Unlock()
+	if _, err := driver.ReadMemory(0x1000, 16); err == nil || !strings.Contains(err.Error(), "console output") {
+		t.Errorf("read behind endless console output returned %v", err)
+	}
+
+	// The connection is dropped so the late reply isn't taken for the next one
+	data, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "ram", Start: 0x1010, End: 0x101F}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[0x1010], memory[0x10:0x20]) {
+		t.Errorf("read after reconnecting % X", data[0x1010])
+	}
+}
+
+func TestGDBDriverRenegotiatesChunkSizeOnReconnect(t *testing.T) {
+	stub := newGDBStub(t, 0x1000, gdbTestMemory(), 0xA0)
+	driver := stub.driver()
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if driver.maxChunkSize != (0xA0-32)/2 {
+		t.Fatalf("chunk size %d, want %d", driver.maxChunkSize, (0xA0-32)/2)
+	}
+
+	// The emulator restarted with a stub that reports no packet size
+	stub.mu.Lock()
+	stub.packet = 0
+	stub.mu.Unlock()
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if driver.maxChunkSize != defaultGDBChunkSize {
+		t.Errorf("chunk size after reconnect %d, want the default %d", driver.maxChunkSize, defaultGDBChunkSize)
+	}
+}
@@ -0,0 +1,54 @@
+package drivers
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// helperProcess is a child test binary standing in for an emulator or tool
+type helperProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// startHelperProcess runs the named helper test in a child process and waits
+// for it to say it is ready
+func startHelperProcess(t *testing.T, name string) *helperProcess {
+	cmd := exec.Command(os.Args[0], "-test.run=^"+name+"$")
+	cmd.Env = append(os.Environ(), "GAMEHOOK_HELPER_PROCESS=1")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		stdin.Close()
+		cmd.Wait()
+	})
+
+	helper := &helperProcess{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}
+	if line := helper.readLine(t); line != "ready" {
+		t.Fatalf("helper process said %q", line)
+	}
+	return helper
+}
+
+func (h *helperProcess) readLine(t *testing.T) string {
+	t.Helper()
+	line, err := h.stdout.ReadString('\n')
+	if err != nil {
+		t.Fatalf("helper process: %v", err)
+	}
+	return strings.TrimSpace(line)
+}
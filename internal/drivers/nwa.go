@@ -0,0 +1,491 @@
+package drivers
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"gamehook/internal/types"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NWADomain maps a range of the platform address space onto an NWA memory domain
+type NWADomain struct {
+	Name   string // NWA domain name, e.g. "WRAM"
+	Start  uint32 // First bus address (inclusive)
+	End    uint32 // Last bus address (inclusive)
+	Offset uint32 // Domain offset that Start maps to
+}
+
+// DefaultSNESDomains maps the SNES bus onto the standard NWA domains
+var DefaultSNESDomains = []NWADomain{
+	{Name: "WRAM", Start: 0x7E0000, End: 0x7FFFFF, Offset: 0},
+	{Name: "WRAM", Start: 0x000000, End: 0x001FFF, Offset: 0}, // Low RAM mirror in bank $00
+	{Name: "SRAM", Start: 0x700000, End: 0x707FFF, Offset: 0}, // LoROM save RAM
+}
+
+// nwaSegment is part of a request that falls inside a single domain
+type nwaSegment struct {
+	domain string
+	offset uint32
+	length uint32
+}
+
+// NWADriver talks to emulators implementing the Emulator Network Access protocol
+type NWADriver struct {
+	host           string
+	port           int
+	requestTimeout time.Duration
+	domains        []NWADomain
+
+	mu       sync.Mutex
+	conn     net.Conn
+	reader   *bufio.Reader
+	emulator string
+	memories map[string]bool // Upper-case CORE_MEMORIES names, nil until listed
+}
+
+// nwaReplyError is an error reply from the emulator. The reply was read in
+// full, so unlike other failures it leaves the connection usable.
+type nwaReplyError struct {
+	reason string
+}
+
+func (e *nwaReplyError) Error() string {
+	return "NWA error: " + e.reason
+}
+
+// NewNWADriver creates a new NWA driver. A nil domain table uses DefaultSNESDomains.
+func NewNWADriver(host string, port int, requestTimeout time.Duration, domains []NWADomain) *NWADriver {
+	if len(domains) == 0 {
+		domains = DefaultSNESDomains
+	}
+	return &NWADriver{
+		host:           host,
+		port:           port,
+		requestTimeout: requestTimeout,
+		domains:        domains,
+	}
+}
+
+// Connect establishes the TCP connection and identifies GameHook to the emulator
+func (d *NWADriver) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closeLocked()
+
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", d.host, d.port), d.requestTimeout*10)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NWA emulator: %w", err)
+	}
+
+	d.conn = conn
+	d.reader = bufio.NewReader(conn)
+
+	if _, err := d.asciiCommandLocked("MY_NAME_IS GameHook"); err != nil {
+		d.closeLocked()
+		return fmt.Errorf("failed to communicate with NWA emulator: %w", err)
+	}
+
+	info, err := d.asciiCommandLocked("EMULATOR_INFO")
+	if err != nil {
+		d.closeLocked()
+		return fmt.Errorf("failed to query emulator info: %w", err)
+	}
+
+	d.emulator = info["name"]
+	if version := info["version"]; version != "" {
+		d.emulator += " " + version
+	}
+
+	// Without a game loaded there may be no memories yet; HasDomain asks again
+	if err := d.loadMemoriesLocked(); err != nil && d.conn == nil {
+		return fmt.Errorf("failed to list emulator memories: %w", err)
+	}
+
+	fmt.Printf("🔌 Connected to NWA emulator %s at %s:%d\n", d.emulator, d.host, d.port)
+	return nil
+}
+
+// ReadMemoryBlocks reads multiple memory blocks through CORE_READ
+func (d *NWADriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	d.mu.Lock()
+	connected := d.conn != nil
+	d.mu.Unlock()
+
+	if !connected {
+		if err := d.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return nil, fmt.Errorf("not connected to NWA emulator")
+	}
+
+	result := make(map[uint32][]byte)
+
+	for _, block := range blocks {
+		var segments []nwaSegment
+		var err error
+		if block.Domain != "" {
+			segments = domainSegment(block.Domain, block.Start, block.End-block.Start+1)
+		} else {
+			segments, err = d.resolve(block.Start, block.End-block.Start+1)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block %s: %w", block.Name, err)
+		}
+
+		data := make([]byte, 0, block.End-block.Start+1)
+		for _, seg := range segments {
+			chunk, err := d.coreReadLocked(seg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read block %s: %w", block.Name, err)
+			}
+			data = append(data, chunk...)
+		}
+		result[block.Start] = data
+	}
+
+	return result, nil
+}
+
+// WriteBytes writes data through binary CORE_WRITE commands
+func (d *NWADriver) WriteBytes(address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return fmt.Errorf("not connected to NWA emulator")
+	}
+
+	segments, err := d.resolve(address, uint32(len(data)))
+	if err != nil {
+		return err
+	}
+
+	return d.writeSegmentsLocked(segments, data)
+}
+
+// HasDomain reports whether the emulator lists domain among its core memories
+func (d *NWADriver) HasDomain(domain string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if domain == "" || d.conn == nil {
+		return false
+	}
+	if d.memories == nil {
+		if err := d.loadMemoriesLocked(); err != nil {
+			return false
+		}
+	}
+	return d.memories[strings.ToUpper(domain)]
+}
+
+// loadMemoriesLocked lists the emulator's memory domains with CORE_MEMORIES
+func (d *NWADriver) loadMemoriesLocked() error {
+	if err := d.sendLocked("CORE_MEMORIES"); err != nil {
+		return d.failLocked(err)
+	}
+
+	fields, err := d.readAsciiFieldsLocked()
+	if err != nil {
+		return d.failLocked(err)
+	}
+
+	d.memories = make(map[string]bool)
+	for _, field := range fields {
+		if field[0] == "name" {
+			d.memories[strings.ToUpper(field[1])] = true
+		}
+	}
+	return nil
+}
+
+// WriteDomainBytes writes data at an offset inside an NWA memory domain
+func (d *NWADriver) WriteDomainBytes(domain string, address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return fmt.Errorf("not connected to NWA emulator")
+	}
+
+	return d.writeSegmentsLocked(domainSegment(domain, address, uint32(len(data))), data)
+}
+
+// writeSegmentsLocked writes consecutive parts of data to each segment
+func (d *NWADriver) writeSegmentsLocked(segments []nwaSegment, data []byte) error {
+	offset := uint32(0)
+	for _, seg := range segments {
+		if err := d.coreWriteLocked(seg, data[offset:offset+seg.length]); err != nil {
+			return err
+		}
+		offset += seg.length
+	}
+
+	return nil
+}
+
+// domainSegment addresses a range inside a named domain ("sram" → NWA "SRAM")
+func domainSegment(domain string, offset uint32, length uint32) []nwaSegment {
+	return []nwaSegment{{domain: strings.ToUpper(domain), offset: offset, length: length}}
+}
+
+// Close closes the connection
+func (d *NWADriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closeLocked()
+}
+
+// closeLocked closes the TCP connection
+func (d *NWADriver) closeLocked() error {
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	d.reader = nil
+	d.memories = nil
+	return err
+}
+
+// failLocked closes the connection after err unless it is an error reply.
+// Any other failure may leave part of a reply unread, so later replies
+// could no longer be told apart.
+func (d *NWADriver) failLocked(err error) error {
+	var replyErr *nwaReplyError
+	if !errors.As(err, &replyErr) {
+		d.closeLocked()
+	}
+	return err
+}
+
+// resolve splits a bus range into per-domain segments
+func (d *NWADriver) resolve(address uint32, length uint32) ([]nwaSegment, error) {
+	var segments []nwaSegment
+	remaining := length
+	current := address
+
+	for remaining > 0 {
+		domain, ok := d.findDomain(current)
+		if !ok {
+			return nil, fmt.Errorf("address 0x%X is not mapped to an NWA memory domain", current)
+		}
+
+		n := domain.End - current + 1
+		if n > remaining || n == 0 {
+			n = remaining
+		}
+
+		segments = append(segments, nwaSegment{
+			domain: domain.Name,
+			offset: domain.Offset + (current - domain.Start),
+			length: n,
+		})
+
+		current += n
+		remaining -= n
+	}
+
+	return segments, nil
+}
+
+// findDomain returns the domain mapping containing address
+func (d *NWADriver) findDomain(address uint32) (NWADomain, bool) {
+	for _, domain := range d.domains {
+		if address >= domain.Start && address <= domain.End {
+			return domain, true
+		}
+	}
+	return NWADomain{}, false
+}
+
+// coreReadLocked reads one segment with CORE_READ
+func (d *NWADriver) coreReadLocked(seg nwaSegment) ([]byte, error) {
+	command := fmt.Sprintf("CORE_READ %s;$%x;$%x", seg.domain, seg.offset, seg.length)
+	if err := d.sendLocked(command); err != nil {
+		return nil, d.failLocked(err)
+	}
+
+	data, err := d.readBinaryReplyLocked()
+	if err != nil {
+		return nil, d.failLocked(fmt.Errorf("%s: %w", command, err))
+	}
+
+	if uint32(len(data)) != seg.length {
+		return nil, fmt.Errorf("expected %d bytes from %s, got %d", seg.length, seg.domain, len(data))
+	}
+
+	return data, nil
+}
+
+// coreWriteLocked writes one segment with a binary CORE_WRITE
+func (d *NWADriver) coreWriteLocked(seg nwaSegment, data []byte) error {
+	command := fmt.Sprintf("bCORE_WRITE %s;$%x;$%x", seg.domain, seg.offset, seg.length)
+	if err := d.sendLocked(command); err != nil {
+		return d.failLocked(err)
+	}
+
+	// Binary block: 0x00, 4-byte big endian size, payload
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header[1:], uint32(len(data)))
+	if _, err := d.conn.Write(append(header, data...)); err != nil {
+		return d.failLocked(fmt.Errorf("failed to send write payload: %w", err))
+	}
+
+	if _, err := d.readAsciiReplyLocked(); err != nil {
+		return d.failLocked(fmt.Errorf("%s: %w", command, err))
+	}
+
+	return nil
+}
+
+// asciiCommandLocked sends a command that expects a key:value reply
+func (d *NWADriver) asciiCommandLocked(command string) (map[string]string, error) {
+	if err := d.sendLocked(command); err != nil {
+		return nil, d.failLocked(err)
+	}
+
+	fields, err := d.readAsciiReplyLocked()
+	if err != nil {
+		return nil, d.failLocked(err)
+	}
+	return fields, nil
+}
+
+// sendLocked writes a command line and arms the request deadline
+func (d *NWADriver) sendLocked(command string) error {
+	if err := d.conn.SetDeadline(time.Now().Add(d.requestTimeout)); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	if _, err := d.conn.Write([]byte(command + "\n")); err != nil {
+		return fmt.Errorf("failed to send command: %w", err)
+	}
+
+	return nil
+}
+
+// readAsciiReplyLocked reads a reply and requires it to be an ascii map
+func (d *NWADriver) readAsciiReplyLocked() (map[string]string, error) {
+	fields, err := d.readAsciiFieldsLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(fields))
+	for _, field := range fields {
+		result[field[0]] = field[1]
+	}
+	return result, nil
+}
+
+// readAsciiFieldsLocked reads an ascii reply as key/value pairs in order, for
+// replies that repeat keys such as the entries of CORE_MEMORIES
+func (d *NWADriver) readAsciiFieldsLocked() ([][2]string, error) {
+	kind, err := d.reader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reply: %w", err)
+	}
+
+	switch kind {
+	case '\n':
+		return d.readAsciiBodyLocked()
+	case 0:
+		if _, err := d.readBinaryBodyLocked(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unexpected binary reply")
+	default:
+		return nil, fmt.Errorf("invalid reply marker 0x%02X", kind)
+	}
+}
+
+// readBinaryReplyLocked reads a reply and requires it to be a binary block
+func (d *NWADriver) readBinaryReplyLocked() ([]byte, error) {
+	kind, err := d.reader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reply: %w", err)
+	}
+
+	switch kind {
+	case 0:
+		return d.readBinaryBodyLocked()
+	case '\n':
+		fields, err := d.readAsciiBodyLocked()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unexpected ascii reply: %v", fields)
+	default:
+		return nil, fmt.Errorf("invalid reply marker 0x%02X", kind)
+	}
+}
+
+// readAsciiBodyLocked parses key:value lines up to the terminating blank line.
+// An "error" key is turned into a Go error.
+func (d *NWADriver) readAsciiBodyLocked() ([][2]string, error) {
+	var fields [][2]string
+	var errorKind, reason string
+	failed := false
+
+	for {
+		line, err := d.reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read reply: %w", err)
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		key, value, _ := strings.Cut(line, ":")
+		switch key {
+		case "error":
+			errorKind, failed = value, true
+		case "reason":
+			reason = value
+		}
+		fields = append(fields, [2]string{key, value})
+	}
+
+	if failed {
+		if errorKind == "" {
+			errorKind = "unknown error"
+		}
+		if reason != "" {
+			errorKind += ": " + reason
+		}
+		return nil, &nwaReplyError{reason: errorKind}
+	}
+
+	return fields, nil
+}
+
+// readBinaryBodyLocked reads the size-prefixed payload of a binary reply
+func (d *NWADriver) readBinaryBodyLocked() ([]byte, error) {
+	sizeBuf := make([]byte, 4)
+	if _, err := io.ReadFull(d.reader, sizeBuf); err != nil {
+		return nil, fmt.Errorf("failed to read reply size: %w", err)
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(sizeBuf))
+	if _, err := io.ReadFull(d.reader, data); err != nil {
+		return nil, fmt.Errorf("failed to read reply data: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,230 @@
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"gamehook/internal/types"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNWA is an Emulator Network Access server with WRAM and SRAM memories
+type fakeNWA struct {
+	t        *testing.T
+	listener net.Listener
+
+	mu          sync.Mutex
+	memories    map[string][]byte
+	connections int
+	garbage     bool // Answer the next CORE_READ with an invalid reply marker
+}
+
+func newFakeNWA(t *testing.T) *fakeNWA {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeNWA{
+		t:        t,
+		listener: listener,
+		memories: map[string][]byte{"WRAM": make([]byte, 0x20000), "SRAM": make([]byte, 0x8000)},
+	}
+	for i := range fake.memories["WRAM"] {
+		fake.memories["WRAM"][i] = byte(i*7 + 3)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go fake.serve()
+	return fake
+}
+
+func (f *fakeNWA) driver() *NWADriver {
+	return NewNWADriver("127.0.0.1", f.listener.Addr().(*net.TCPAddr).Port, time.Second, nil)
+}
+
+func (f *fakeNWA) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.connections++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeNWA) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	ascii := func(lines ...string) {
+		var reply strings.Builder
+		reply.WriteString("\n")
+		for _, line := range lines {
+			reply.WriteString(line + "\n")
+		}
+		conn.Write([]byte(reply.String() + "\n"))
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+		params := strings.Split(args, ";")
+
+		f.mu.Lock()
+		switch command {
+		case "MY_NAME_IS":
+			ascii("name:" + args)
+		case "EMULATOR_INFO":
+			ascii("name:fake", "version:1.0")
+		case "CORE_MEMORIES":
+			ascii("name:WRAM", "access:rw", "size:131072", "name:SRAM", "access:rw", "size:32768")
+		case "CORE_READ", "bCORE_WRITE":
+			memory, known := f.memories[params[0]]
+			offset, _ := strconv.ParseUint(strings.TrimPrefix(params[1], "$"), 16, 32)
+			length, _ := strconv.ParseUint(strings.TrimPrefix(params[2], "$"), 16, 32)
+
+			if command == "bCORE_WRITE" {
+				header := make([]byte, 5)
+				io.ReadFull(reader, header)
+				payload := make([]byte, binary.BigEndian.Uint32(header[1:]))
+				io.ReadFull(reader, payload)
+				if !known {
+					ascii("error:invalid_argument", "reason:no memory "+params[0])
+				} else {
+					copy(memory[offset:], payload)
+					ascii()
+				}
+				break
+			}
+
+			switch {
+			case f.garbage:
+				f.garbage = false
+				conn.Write([]byte{0x7F, 1, 2, 3})
+			case !known:
+				ascii("error:invalid_argument", "reason:no memory "+params[0])
+			default:
+				reply := make([]byte, 5, 5+length)
+				binary.BigEndian.PutUint32(reply[1:], uint32(length))
+				conn.Write(append(reply, memory[offset:offset+length]...))
+			}
+		default:
+			ascii("error:invalid_command")
+		}
+		f.mu.Unlock()
+	}
+}
+
+func TestNWADriverReadsAndWritesBinaryBlocks(t *testing.T) {
+	fake := newFakeNWA(t)
+	driver := fake.driver()
+	defer driver.Close()
+
+	blocks, err := driver.ReadMemoryBlocks([]types.MemoryBlock{
+		{Name: "wram", Start: 0x7E0010, End: 0x7E001F},
+		{Name: "mirror", Start: 0x000010, End: 0x000013}, // Low RAM mirror of the same bytes
+		{Name: "save", Domain: "sram", Start: 0x0100, End: 0x0103},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wram := fake.memories["WRAM"]
+	if !bytes.Equal(blocks[0x7E0010], wram[0x10:0x20]) || !bytes.Equal(blocks[0x000010], wram[0x10:0x14]) {
+		t.Errorf("read % X and % X, want % X", blocks[0x7E0010], blocks[0x000010], wram[0x10:0x20])
+	}
+	if !bytes.Equal(blocks[0x0100], make([]byte, 4)) {
+		t.Errorf("sram read % X", blocks[0x0100])
+	}
+
+	// The tail of LoROM save RAM and a domain write, read back
+	if err := driver.WriteBytes(0x707FFE, []byte{0xAB, 0xCD}); err != nil {
+		t.Fatal(err)
+	}
+	if err := driver.WriteDomainBytes("sram", 0x0100, []byte{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	blocks, err = driver.ReadMemoryBlocks([]types.MemoryBlock{
+		{Name: "tail", Domain: "sram", Start: 0x7FFE, End: 0x7FFF},
+		{Name: "save", Domain: "sram", Start: 0x0100, End: 0x0103},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(blocks[0x7FFE], []byte{0xAB, 0xCD}) || !bytes.Equal(blocks[0x0100], []byte{1, 2, 3, 4}) {
+		t.Errorf("read back % X and % X", blocks[0x7FFE], blocks[0x0100])
+	}
+
+	if err := driver.WriteBytes(0x6F8000, []byte{1}); err == nil {
+		t.Error("write outside the domain table succeeded")
+	}
+}
+
+func TestNWADriverDomainsComeFromTheEmulator(t *testing.T) {
+	fake := newFakeNWA(t)
+	driver := fake.driver()
+	defer driver.Close()
+
+	if driver.HasDomain("sram") {
+		t.Error("domain reported before connecting")
+	}
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	for domain, want := range map[string]bool{"sram": true, "WRAM": true, "vram": false, "": false} {
+		if got := driver.HasDomain(domain); got != want {
+			t.Errorf("HasDomain(%q) = %v, want %v", domain, got, want)
+		}
+	}
+}
+
+func TestNWADriverErrorReplies(t *testing.T) {
+	fake := newFakeNWA(t)
+	driver := fake.driver()
+	defer driver.Close()
+
+	// An error reply fails the read but keeps the connection
+	_, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "vram", Domain: "vram", Start: 0, End: 3}})
+	if err == nil || !strings.Contains(err.Error(), "NWA error: invalid_argument: no memory VRAM") {
+		t.Fatalf("read of an unknown memory returned %v", err)
+	}
+	if err := driver.WriteDomainBytes("vram", 0, []byte{1}); err == nil {
+		t.Error("write to an unknown memory succeeded")
+	}
+	if _, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "wram", Start: 0x7E0000, End: 0x7E0003}}); err != nil {
+		t.Fatalf("read after an error reply failed: %v", err)
+	}
+
+	// A malformed reply closes the connection, and the next read reconnects
+	fake.mu.Lock()
+	fake.garbage = true
+	fake.mu.Unlock()
+	if _, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "wram", Start: 0x7E0000, End: 0x7E0003}}); err == nil || !strings.Contains(err.Error(), "invalid reply marker") {
+		t.Fatalf("read with a malformed reply returned %v", err)
+	}
+	if err := driver.WriteBytes(0x7E0000, []byte{1}); err == nil {
+		t.Error("write on a closed connection succeeded")
+	}
+	blocks, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "wram", Start: 0x7E0000, End: 0x7E0003}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fake.memories["WRAM"][:4]; !bytes.Equal(blocks[0x7E0000], want) {
+		t.Errorf("read after reconnecting % X, want % X", blocks[0x7E0000], want)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.connections != 2 {
+		t.Errorf("%d connections, want 2", fake.connections)
+	}
+}
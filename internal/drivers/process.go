@@ -0,0 +1,483 @@
+//go:build linux
+
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"gamehook/internal/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// procMapping is one line of /proc/<pid>/maps
+type procMapping struct {
+	start uint64
+	end   uint64
+	perms string
+	path  string
+}
+
+const processScanChunkSize = 1 << 20
+
+// ProcessDriver reads and writes the memory of a native Linux emulator through /proc/<pid>/mem
+type ProcessDriver struct {
+	pid          int
+	name         string
+	baseOverride uint64 // Host address of emulated RAM, skips locating
+
+	mu      sync.Mutex
+	mem     *os.File
+	current int // PID currently attached
+	locator *RAMLocator
+
+	// The located base survives reconnects to the same process
+	base     uint64
+	basePID  int
+	baseSeen bool
+}
+
+// NewProcessDriver creates a new process-memory driver. Either pid or name selects the process.
+func NewProcessDriver(pid int, name string, baseOverride uint64) *ProcessDriver {
+	return &ProcessDriver{
+		pid:          pid,
+		name:         name,
+		baseOverride: baseOverride,
+	}
+}
+
+// SetLocator sets the RAM layout declared by the loaded mapper
+func (d *ProcessDriver) SetLocator(locator *RAMLocator) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if locator != nil && d.locator != nil && *locator == *d.locator {
+		return
+	}
+
+	d.locator = locator
+	d.baseSeen = false
+}
+
+// Connect attaches to the process. The RAM base is located on first access.
+func (d *ProcessDriver) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closeLocked()
+
+	pid := d.pid
+	if pid == 0 {
+		found, err := findProcessByName(d.name)
+		if err != nil {
+			return err
+		}
+		pid = found
+	}
+
+	memPath := fmt.Sprintf("/proc/%d/mem", pid)
+	mem, err := os.OpenFile(memPath, os.O_RDWR, 0)
+	if err != nil {
+		// Read-only access still allows monitoring
+		var roErr error
+		if mem, roErr = os.Open(memPath); roErr != nil {
+			return fmt.Errorf("failed to open process memory (ptrace permission required): %w", err)
+		}
+		fmt.Printf("⚠️  Process %d memory opened read-only: %v\n", pid, err)
+	}
+
+	d.mem = mem
+	d.current = pid
+
+	// A restarted emulator gets a new PID and a new (ASLR) layout
+	if d.baseSeen && (d.basePID != pid || !d.baseValidLocked()) {
+		d.baseSeen = false
+	}
+
+	fmt.Printf("🔌 Attached to process %s (pid %d)\n", processName(pid), pid)
+	return nil
+}
+
+// ReadMemoryBlocks reads multiple memory blocks from the process
+func (d *ProcessDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	d.mu.Lock()
+	connected := d.mem != nil
+	d.mu.Unlock()
+
+	if !connected {
+		if err := d.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.ensureBaseLocked(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint32][]byte)
+
+	for _, block := range blocks {
+		hostAddr, err := d.hostAddressLocked(block.Start)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block %s: %w", block.Name, err)
+		}
+
+		data := make([]byte, block.End-block.Start+1)
+		if _, err := d.mem.ReadAt(data, int64(hostAddr)); err != nil {
+			// The process may have exited; reattach on the next read
+			d.closeLocked()
+			return nil, fmt.Errorf("failed to read block %s: %w", block.Name, err)
+		}
+		result[block.Start] = data
+	}
+
+	return result, nil
+}
+
+// WriteBytes writes data into the process
+func (d *ProcessDriver) WriteBytes(address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.mem == nil {
+		return fmt.Errorf("not attached to a process")
+	}
+
+	if err := d.ensureBaseLocked(); err != nil {
+		return err
+	}
+
+	hostAddr, err := d.hostAddressLocked(address)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.mem.WriteAt(data, int64(hostAddr)); err != nil {
+		return fmt.Errorf("failed to write at 0x%X: %w", address, err)
+	}
+
+	return nil
+}
+
+// Close detaches from the process, keeping the located base for reconnects
+func (d *ProcessDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closeLocked()
+}
+
+// closeLocked closes /proc/<pid>/mem
+func (d *ProcessDriver) closeLocked() error {
+	if d.mem == nil {
+		return nil
+	}
+	err := d.mem.Close()
+	d.mem = nil
+	return err
+}
+
+// BaseAddress returns the located host address of emulated RAM
+func (d *ProcessDriver) BaseAddress() (uint64, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.base, d.baseSeen
+}
+
+// hostAddressLocked translates an emulated address into the process address space
+func (d *ProcessDriver) hostAddressLocked(address uint32) (uint64, error) {
+	start := uint32(0)
+	if d.locator != nil {
+		start = d.locator.EmulatedStart
+	}
+	if address < start {
+		return 0, fmt.Errorf("address 0x%X is below the emulated RAM start 0x%X", address, start)
+	}
+	return d.base + uint64(address-start), nil
+}
+
+// ensureBaseLocked locates emulated RAM unless a cached base is available
+func (d *ProcessDriver) ensureBaseLocked() error {
+	if d.baseSeen && d.basePID == d.current {
+		return nil
+	}
+
+	base, where, err := d.locateLocked()
+	if err != nil {
+		return err
+	}
+
+	d.base = base
+	d.basePID = d.current
+	d.baseSeen = true
+
+	fmt.Printf("📍 Emulated RAM located at 0x%X (%s)\n", base, where)
+	return nil
+}
+
+// locateLocked finds the host address of emulated RAM
+func (d *ProcessDriver) locateLocked() (uint64, string, error) {
+	if d.baseOverride != 0 {
+		return d.baseOverride, "configured", nil
+	}
+
+	if d.locator == nil {
+		return 0, "", fmt.Errorf("RAM base unknown: load a mapper that declares platform.processMemory or set process.base_address")
+	}
+
+	// An empty module would match whichever mapping comes first
+	if d.locator.Signature == "" && d.locator.Module == "" {
+		return 0, "", fmt.Errorf("RAM locator needs a module or a signature")
+	}
+
+	mappings, err := readProcMaps(d.current)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if d.locator.Signature == "" {
+		for _, m := range mappings {
+			if strings.Contains(m.path, d.locator.Module) {
+				return uint64(int64(m.start) + d.locator.Offset), m.path, nil
+			}
+		}
+		return 0, "", fmt.Errorf("no mapping matching %q in process %d", d.locator.Module, d.current)
+	}
+
+	pattern, mask, err := parseSignature(d.locator.Signature)
+	if err != nil {
+		return 0, "", err
+	}
+
+	for _, m := range mappings {
+		if m.perms[0] != 'r' || (d.locator.Module != "" && !strings.Contains(m.path, d.locator.Module)) {
+			continue
+		}
+		if match, ok := d.scanMappingLocked(m, pattern, mask); ok {
+			where := m.path
+			if where == "" {
+				where = "anonymous mapping"
+			}
+			return uint64(int64(match) + d.locator.SignatureOffset), where, nil
+		}
+	}
+
+	return 0, "", fmt.Errorf("signature %q not found in process %d", d.locator.Signature, d.current)
+}
+
+// scanMappingLocked searches one mapping for the signature
+func (d *ProcessDriver) scanMappingLocked(m procMapping, pattern []byte, mask []bool) (uint64, bool) {
+	overlap := uint64(len(pattern) - 1)
+	buf := make([]byte, processScanChunkSize+overlap)
+
+	for addr := m.start; addr < m.end; addr += processScanChunkSize {
+		size := uint64(len(buf))
+		if addr+size > m.end {
+			size = m.end - addr
+		}
+
+		n, err := d.mem.ReadAt(buf[:size], int64(addr))
+		if err != nil && n == 0 {
+			// Guard pages and special mappings can't be read
+			return 0, false
+		}
+
+		if i := indexSignature(buf[:n], pattern, mask); i >= 0 {
+			return addr + uint64(i), true
+		}
+	}
+
+	return 0, false
+}
+
+// baseValidLocked reports whether the cached base still points at emulated RAM
+func (d *ProcessDriver) baseValidLocked() bool {
+	if d.baseOverride != 0 {
+		return true
+	}
+
+	probe := make([]byte, 1)
+	if _, err := d.mem.ReadAt(probe, int64(d.base)); err != nil {
+		return false
+	}
+
+	if d.locator == nil || d.locator.Signature == "" {
+		return true
+	}
+
+	pattern, mask, err := parseSignature(d.locator.Signature)
+	if err != nil {
+		return false
+	}
+
+	at := make([]byte, len(pattern))
+	if _, err := d.mem.ReadAt(at, int64(d.base)-d.locator.SignatureOffset); err != nil {
+		return false
+	}
+	return indexSignature(at, pattern, mask) == 0
+}
+
+// parseSignature parses hex bytes such as "47 42 ?? 00" into a pattern and wildcard mask
+func parseSignature(signature string) ([]byte, []bool, error) {
+	compact := strings.Join(strings.Fields(signature), "")
+	if compact == "" || len(compact)%2 != 0 {
+		return nil, nil, fmt.Errorf("invalid signature %q", signature)
+	}
+
+	pattern := make([]byte, len(compact)/2)
+	mask := make([]bool, len(compact)/2)
+
+	for i := range pattern {
+		token := compact[i*2 : i*2+2]
+		if token == "??" {
+			continue
+		}
+		b, err := hex.DecodeString(token)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid signature %q: %w", signature, err)
+		}
+		pattern[i] = b[0]
+		mask[i] = true
+	}
+
+	return pattern, mask, nil
+}
+
+// indexSignature returns the first offset in data matching the pattern, or -1
+func indexSignature(data []byte, pattern []byte, mask []bool) int {
+	// Anchor on the first fixed byte so bytes.IndexByte does most of the work
+	anchor := -1
+	for i, fixed := range mask {
+		if fixed {
+			anchor = i
+			break
+		}
+	}
+	if anchor < 0 {
+		return 0
+	}
+
+	for from := 0; from+len(pattern) <= len(data); {
+		j := bytes.IndexByte(data[from+anchor:len(data)-len(pattern)+anchor+1], pattern[anchor])
+		if j < 0 {
+			return -1
+		}
+		candidate := from + j
+
+		matched := true
+		for k := range pattern {
+			if mask[k] && data[candidate+k] != pattern[k] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return candidate
+		}
+		from = candidate + 1
+	}
+
+	return -1
+}
+
+// readProcMaps parses /proc/<pid>/maps
+func readProcMaps(pid int) ([]procMapping, error) {
+	file, err := os.Open(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process mappings: %w", err)
+	}
+	defer file.Close()
+
+	var mappings []procMapping
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// start-end perms offset dev inode [path]
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		startStr, endStr, ok := strings.Cut(fields[0], "-")
+		if !ok {
+			continue
+		}
+		start, err1 := strconv.ParseUint(startStr, 16, 64)
+		end, err2 := strconv.ParseUint(endStr, 16, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		mapping := procMapping{start: start, end: end, perms: fields[1]}
+		if len(fields) > 5 {
+			mapping.path = strings.Join(fields[5:], " ")
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read process mappings: %w", err)
+	}
+
+	return mappings, nil
+}
+
+// findProcessByName returns the lowest PID whose command name or executable matches name
+func findProcessByName(name string) (int, error) {
+	if name == "" {
+		return 0, fmt.Errorf("process driver requires a pid or process name")
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	var matches []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		if processName(pid) == name || processExecutable(pid) == name {
+			matches = append(matches, pid)
+		}
+	}
+
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("no process named %q found", name)
+	}
+
+	sort.Ints(matches)
+	if len(matches) > 1 {
+		fmt.Printf("⚠️  %d processes named %q, attaching to pid %d\n", len(matches), name, matches[0])
+	}
+
+	return matches[0], nil
+}
+
+// processName returns the command name from /proc/<pid>/comm
+func processName(pid int) string {
+	comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+// processExecutable returns the base name of argv[0], which is not truncated like comm
+func processExecutable(pid int) string {
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil || len(cmdline) == 0 {
+		return ""
+	}
+	argv0, _, _ := bytes.Cut(cmdline, []byte{0})
+	return filepath.Base(string(argv0))
+}
@@ -0,0 +1,15 @@
+package drivers
+
+// RAMLocator describes how to find emulated RAM inside a host process.
+//
+// With a signature, readable mappings (optionally restricted to those whose
+// path contains Module) are scanned for the byte pattern and the RAM base is
+// the match address plus SignatureOffset. Without one, the RAM base is the
+// start of the first mapping matching Module plus Offset.
+type RAMLocator struct {
+	Module          string
+	Signature       string // Hex bytes, "??" for wildcards
+	SignatureOffset int64
+	Offset          int64
+	EmulatedStart   uint32 // Emulated address found at the RAM base
+}
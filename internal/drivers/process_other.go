@@ -0,0 +1,48 @@
+//go:build !linux
+
+package drivers
+
+import (
+	"fmt"
+	"gamehook/internal/types"
+)
+
+// errProcessUnsupported is returned by every operation on platforms without /proc
+var errProcessUnsupported = fmt.Errorf("process memory access is only supported on Linux")
+
+// ProcessDriver reads and writes the memory of a native emulator. It
+// needs /proc, so on this platform every operation fails.
+type ProcessDriver struct{}
+
+// NewProcessDriver creates a new process-memory driver. Either pid or name selects the process.
+func NewProcessDriver(pid int, name string, baseOverride uint64) *ProcessDriver {
+	return &ProcessDriver{}
+}
+
+// SetLocator sets the RAM layout declared by the loaded mapper
+func (d *ProcessDriver) SetLocator(locator *RAMLocator) {}
+
+// Connect reports that process memory access is unavailable
+func (d *ProcessDriver) Connect() error {
+	return errProcessUnsupported
+}
+
+// ReadMemoryBlocks reports that process memory access is unavailable
+func (d *ProcessDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	return nil, errProcessUnsupported
+}
+
+// WriteBytes reports that process memory access is unavailable
+func (d *ProcessDriver) WriteBytes(address uint32, data []byte) error {
+	return errProcessUnsupported
+}
+
+// Close does nothing
+func (d *ProcessDriver) Close() error {
+	return nil
+}
+
+// BaseAddress never has a located base
+func (d *ProcessDriver) BaseAddress() (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,183 @@
+//go:build linux
+
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"gamehook/internal/types"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const (
+	helperSignatureSize = 16
+	helperRAMSize       = 0x2000
+)
+
+// helperSignature is built at runtime so the raw bytes only exist in the helper's RAM buffer
+func helperSignature() []byte {
+	signature := make([]byte, helperSignatureSize)
+	for i := range signature {
+		signature[i] = byte(0xA5 ^ i*37)
+	}
+	return signature
+}
+
+// helperRAM returns the initial contents of the helper's emulated RAM
+func helperRAM() []byte {
+	ram := make([]byte, helperRAMSize)
+	for i := range ram {
+		ram[i] = byte(i * 3)
+	}
+	return ram
+}
+
+// TestHelperProcess is the fake emulator run by startHelperProcess, not a real test.
+// It holds a signature followed by emulated RAM and answers "dump <offset> <length>" on stdin.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GAMEHOOK_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	buffer := append(helperSignature(), helperRAM()...)
+	fmt.Println("ready")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var offset, length int
+		if _, err := fmt.Sscanf(scanner.Text(), "dump %d %d", &offset, &length); err != nil {
+			fmt.Println("error")
+			continue
+		}
+		ram := buffer[helperSignatureSize:]
+		fmt.Println(hex.EncodeToString(ram[offset : offset+length]))
+	}
+
+	runtime.KeepAlive(buffer)
+	os.Exit(0)
+}
+
+// dump returns emulated RAM as the helper process itself sees it
+func (h *helperProcess) dump(t *testing.T, offset, length int) []byte {
+	t.Helper()
+	fmt.Fprintf(h.stdin, "dump %d %d\n", offset, length)
+	data, err := hex.DecodeString(h.readLine(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func connectProcessDriver(t *testing.T, helper *helperProcess) *ProcessDriver {
+	driver := NewProcessDriver(helper.cmd.Process.Pid, "", 0)
+	driver.SetLocator(&RAMLocator{
+		// The second byte is a wildcard to exercise masked matching
+		Signature:       "a5 ?? " + hex.EncodeToString(helperSignature()[2:]),
+		SignatureOffset: helperSignatureSize,
+		EmulatedStart:   0xC000,
+	})
+	if err := driver.Connect(); err != nil {
+		if os.IsPermission(err) || strings.Contains(err.Error(), "permission") {
+			t.Skipf("cannot open helper process memory: %v", err)
+		}
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { driver.Close() })
+	return driver
+}
+
+func TestProcessDriverLocatesSignature(t *testing.T) {
+	helper := startHelperProcess(t, "TestHelperProcess")
+	driver := connectProcessDriver(t, helper)
+
+	ram := helperRAM()
+	data, err := driver.ReadMemoryBlocks([]types.MemoryBlock{
+		{Name: "wram", Start: 0xC000, End: 0xC0FF},
+		{Name: "tail", Start: 0xDFF0, End: 0xDFFF},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[0xC000], ram[:0x100]) {
+		t.Errorf("wram read % X", data[0xC000][:16])
+	}
+	if !bytes.Equal(data[0xDFF0], ram[0x1FF0:]) {
+		t.Errorf("tail read % X", data[0xDFF0])
+	}
+
+	// Reconnecting to the same process keeps the located base
+	base, ok := driver.BaseAddress()
+	if !ok {
+		t.Fatal("base not located")
+	}
+	driver.Close()
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if again, ok := driver.BaseAddress(); !ok || again != base {
+		t.Errorf("base after reconnect 0x%X (%v), want 0x%X", again, ok, base)
+	}
+
+	// Addresses below the emulated start can't be translated
+	if _, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "low", Start: 0x8000, End: 0x8001}}); err == nil {
+		t.Error("read below the emulated start succeeded")
+	}
+}
+
+func TestProcessDriverWrites(t *testing.T) {
+	helper := startHelperProcess(t, "TestHelperProcess")
+	driver := connectProcessDriver(t, helper)
+
+	if _, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "wram", Start: 0xC000, End: 0xC000}}); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte{0xDE, 0xAD, 0xBE, 0xEF}
+	if err := driver.WriteBytes(0xC123, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := helper.dump(t, 0x123, 4); !bytes.Equal(got, payload) {
+		t.Errorf("helper process sees % X, want % X", got, payload)
+	}
+
+	data, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "wram", Start: 0xC122, End: 0xC127}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ram := helperRAM()
+	want := append(append([]byte{ram[0x122]}, payload...), ram[0x127])
+	if !bytes.Equal(data[0xC122], want) {
+		t.Errorf("read back % X, want % X", data[0xC122], want)
+	}
+}
+
+func TestProcessDriverNeedsModuleOrSignature(t *testing.T) {
+	helper := startHelperProcess(t, "TestHelperProcess")
+	driver := connectProcessDriver(t, helper)
+	blocks := []types.MemoryBlock{{Name: "wram", Start: 0xC000, End: 0xC000}}
+
+	// Without either, the first mapping in the process would be taken as RAM
+	driver.SetLocator(&RAMLocator{EmulatedStart: 0xC000})
+	if _, err := driver.ReadMemoryBlocks(blocks); err == nil {
+		t.Error("located RAM without a module or signature")
+	}
+	if _, ok := driver.BaseAddress(); ok {
+		t.Error("base recorded without a module or signature")
+	}
+
+	// A module alone picks its first mapping
+	driver.SetLocator(&RAMLocator{Module: filepath.Base(os.Args[0]), EmulatedStart: 0xC000})
+	if _, err := driver.ReadMemoryBlocks(blocks); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := driver.BaseAddress(); !ok {
+		t.Error("base not located by module")
+	}
+}
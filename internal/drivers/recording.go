@@ -0,0 +1,462 @@
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Session recording format
+//
+// A recording starts with the magic "GHREC" and a format version byte,
+// followed by the session start time (int64 unix nanoseconds, little endian).
+// The blocks read in one update tick, across all memory domains, are then
+// appended as one record:
+//
+//	kind       byte     'K' keyframe or 'D' delta
+//	frame      uvarint  frame index
+//	offset     uvarint  microseconds since session start
+//	blocks     uvarint  block count
+//	per block: domain uvarint length + name ("" for the system bus),
+//	           start uvarint, length uvarint, then
+//	           keyframe: length raw bytes
+//	           delta:    run count uvarint, runs of {offset uvarint, length uvarint, bytes}
+//
+// Version 1 recordings have no domain field and only hold the system bus.
+//
+// Deltas only store bytes that changed since the previous record, so idle
+// frames cost a few bytes. A keyframe is written periodically and whenever
+// the block layout changes.
+const (
+	recordingMagic        = "GHREC"
+	recordingVersion      = 2
+	recordKindKeyframe    = 'K'
+	recordKindDelta       = 'D'
+	recordingRunMergeGap  = 4
+	recordingFlushPeriod  = time.Second
+	defaultKeyframePeriod = 600
+)
+
+// FrameBlocks holds the blocks of one frame by memory domain ("" for the
+// system bus) and start address
+type FrameBlocks map[string]map[uint32][]byte
+
+// RecordedFrame is a single decoded record from a session recording
+type RecordedFrame struct {
+	Index  uint64
+	Offset time.Duration
+	Blocks FrameBlocks
+}
+
+// SessionRecorder appends memory reads to a compact recording file
+type SessionRecorder struct {
+	path             string
+	keyframeInterval uint64
+
+	mu        sync.Mutex
+	file      *os.File
+	writer    *bufio.Writer
+	startTime time.Time
+	lastFlush time.Time
+	frame     uint64
+	previous  FrameBlocks
+	buf       bytes.Buffer
+	scratch   [binary.MaxVarintLen64]byte
+}
+
+// NewSessionRecorder creates a new recording file at path
+func NewSessionRecorder(path string, keyframeInterval int) (*SessionRecorder, error) {
+	if keyframeInterval <= 0 {
+		keyframeInterval = defaultKeyframePeriod
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	r := &SessionRecorder{
+		path:             path,
+		keyframeInterval: uint64(keyframeInterval),
+		file:             file,
+		writer:           bufio.NewWriterSize(file, 64*1024),
+		startTime:        time.Now(),
+		lastFlush:        time.Now(),
+	}
+
+	header := make([]byte, 0, len(recordingMagic)+9)
+	header = append(header, recordingMagic...)
+	header = append(header, recordingVersion)
+	header = binary.LittleEndian.AppendUint64(header, uint64(r.startTime.UnixNano()))
+
+	if _, err := r.writer.Write(header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write recording header: %w", err)
+	}
+
+	return r, nil
+}
+
+// Path returns the recording file path
+func (r *SessionRecorder) Path() string {
+	return r.path
+}
+
+// FrameCount returns the number of frames recorded so far
+func (r *SessionRecorder) FrameCount() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frame
+}
+
+// Record appends the blocks read in one update tick to the recording
+func (r *SessionRecorder) Record(blocks FrameBlocks) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return fmt.Errorf("recorder is closed")
+	}
+
+	now := time.Now()
+	keyframe := r.frame%r.keyframeInterval == 0 || !sameLayout(r.previous, blocks)
+
+	r.buf.Reset()
+	if keyframe {
+		r.buf.WriteByte(recordKindKeyframe)
+	} else {
+		r.buf.WriteByte(recordKindDelta)
+	}
+	r.putUvarint(r.frame)
+	r.putUvarint(uint64(now.Sub(r.startTime) / time.Microsecond))
+	r.putUvarint(uint64(blocks.count()))
+
+	for _, domain := range sortedDomains(blocks) {
+		for _, start := range sortedStarts(blocks[domain]) {
+			data := blocks[domain][start]
+			r.putUvarint(uint64(len(domain)))
+			r.buf.WriteString(domain)
+			r.putUvarint(uint64(start))
+			r.putUvarint(uint64(len(data)))
+
+			if keyframe {
+				r.buf.Write(data)
+				continue
+			}
+
+			runs := changedRuns(r.previous[domain][start], data)
+			r.putUvarint(uint64(len(runs)))
+			for _, run := range runs {
+				r.putUvarint(uint64(run[0]))
+				r.putUvarint(uint64(run[1] - run[0]))
+				r.buf.Write(data[run[0]:run[1]])
+			}
+		}
+	}
+
+	if _, err := r.writer.Write(r.buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write frame %d: %w", r.frame, err)
+	}
+
+	if r.previous == nil {
+		r.previous = make(FrameBlocks, len(blocks))
+	}
+	r.previous.copyFrom(blocks)
+
+	r.frame++
+
+	if keyframe || now.Sub(r.lastFlush) > recordingFlushPeriod {
+		r.lastFlush = now
+		return r.writer.Flush()
+	}
+
+	return nil
+}
+
+// Close flushes and closes the recording file
+func (r *SessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	flushErr := r.writer.Flush()
+	closeErr := r.file.Close()
+	r.file = nil
+
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+// putUvarint appends a uvarint to the record buffer
+func (r *SessionRecorder) putUvarint(v uint64) {
+	n := binary.PutUvarint(r.scratch[:], v)
+	r.buf.Write(r.scratch[:n])
+}
+
+// count returns the number of blocks across all domains
+func (f FrameBlocks) count() int {
+	n := 0
+	for _, blocks := range f {
+		n += len(blocks)
+	}
+	return n
+}
+
+// copyFrom makes f hold a copy of other, reusing f's buffers
+func (f FrameBlocks) copyFrom(other FrameBlocks) {
+	for domain, blocks := range f {
+		if _, ok := other[domain]; !ok {
+			delete(f, domain)
+			continue
+		}
+		for start := range blocks {
+			if _, ok := other[domain][start]; !ok {
+				delete(blocks, start)
+			}
+		}
+	}
+	for domain, blocks := range other {
+		if f[domain] == nil {
+			f[domain] = make(map[uint32][]byte, len(blocks))
+		}
+		for start, data := range blocks {
+			f[domain][start] = append(f[domain][start][:0], data...)
+		}
+	}
+}
+
+// sameLayout reports whether both frames have the same domains, starts and lengths
+func sameLayout(a, b FrameBlocks) bool {
+	if a.count() != b.count() {
+		return false
+	}
+	for domain, blocks := range b {
+		for start, data := range blocks {
+			prev, ok := a[domain][start]
+			if !ok || len(prev) != len(data) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sortedDomains returns the domains of a frame in ascending order
+func sortedDomains(blocks FrameBlocks) []string {
+	domains := make([]string, 0, len(blocks))
+	for domain := range blocks {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// sortedStarts returns block start addresses in ascending order
+func sortedStarts(blocks map[uint32][]byte) []uint32 {
+	starts := make([]uint32, 0, len(blocks))
+	for start := range blocks {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	return starts
+}
+
+// changedRuns returns [from, to) ranges where data differs from previous,
+// merging runs separated by only a few unchanged bytes
+func changedRuns(previous, data []byte) [][2]int {
+	var runs [][2]int
+	i := 0
+	for i < len(data) {
+		if i < len(previous) && previous[i] == data[i] {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(data) && (i >= len(previous) || previous[i] != data[i]) {
+			i++
+		}
+
+		if n := len(runs); n > 0 && start-runs[n-1][1] <= recordingRunMergeGap {
+			runs[n-1][1] = i
+		} else {
+			runs = append(runs, [2]int{start, i})
+		}
+	}
+	return runs
+}
+
+// RecordingReader decodes a session recording one frame at a time
+type RecordingReader struct {
+	reader    *bufio.Reader
+	version   byte
+	startTime time.Time
+	state     FrameBlocks
+}
+
+// NewRecordingReader validates the recording header and prepares to decode frames
+func NewRecordingReader(r io.Reader) (*RecordingReader, error) {
+	reader := bufio.NewReaderSize(r, 64*1024)
+
+	header := make([]byte, len(recordingMagic)+9)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("failed to read recording header: %w", err)
+	}
+
+	if string(header[:len(recordingMagic)]) != recordingMagic {
+		return nil, fmt.Errorf("not a GameHook recording")
+	}
+	version := header[len(recordingMagic)]
+	if version < 1 || version > recordingVersion {
+		return nil, fmt.Errorf("unsupported recording version %d", version)
+	}
+
+	startNanos := int64(binary.LittleEndian.Uint64(header[len(recordingMagic)+1:]))
+
+	return &RecordingReader{
+		reader:    reader,
+		version:   version,
+		startTime: time.Unix(0, startNanos),
+		state:     make(FrameBlocks),
+	}, nil
+}
+
+// StartTime returns when the recording session started
+func (rr *RecordingReader) StartTime() time.Time {
+	return rr.startTime
+}
+
+// Next decodes the next frame. It returns io.EOF at the end of the recording.
+// The returned blocks are owned by the reader and change on the next call.
+func (rr *RecordingReader) Next() (*RecordedFrame, error) {
+	kind, err := rr.reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if kind != recordKindKeyframe && kind != recordKindDelta {
+		return nil, fmt.Errorf("corrupt recording: unknown record kind 0x%02X", kind)
+	}
+
+	index, err := rr.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	offset, err := rr.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	count, err := rr.uvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == recordKindKeyframe {
+		rr.state = make(FrameBlocks)
+	}
+
+	for i := uint64(0); i < count; i++ {
+		domain, err := rr.domain()
+		if err != nil {
+			return nil, err
+		}
+		start, err := rr.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		length, err := rr.uvarint()
+		if err != nil {
+			return nil, err
+		}
+
+		if kind == recordKindKeyframe {
+			data := make([]byte, length)
+			if _, err := io.ReadFull(rr.reader, data); err != nil {
+				return nil, rr.truncated(err)
+			}
+			if rr.state[domain] == nil {
+				rr.state[domain] = make(map[uint32][]byte)
+			}
+			rr.state[domain][uint32(start)] = data
+			continue
+		}
+
+		data, ok := rr.state[domain][uint32(start)]
+		if !ok || uint64(len(data)) != length {
+			return nil, fmt.Errorf("corrupt recording: delta for unknown block 0x%X in frame %d", start, index)
+		}
+
+		runs, err := rr.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		for j := uint64(0); j < runs; j++ {
+			runOffset, err := rr.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			runLength, err := rr.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			if runOffset+runLength > length {
+				return nil, fmt.Errorf("corrupt recording: run exceeds block 0x%X in frame %d", start, index)
+			}
+			if _, err := io.ReadFull(rr.reader, data[runOffset:runOffset+runLength]); err != nil {
+				return nil, rr.truncated(err)
+			}
+		}
+	}
+
+	return &RecordedFrame{
+		Index:  index,
+		Offset: time.Duration(offset) * time.Microsecond,
+		Blocks: rr.state,
+	}, nil
+}
+
+// domain reads the memory domain of a block; version 1 only holds the system bus
+func (rr *RecordingReader) domain() (string, error) {
+	if rr.version < 2 {
+		return "", nil
+	}
+	length, err := rr.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if length > 255 {
+		return "", fmt.Errorf("corrupt recording: domain name of %d bytes", length)
+	}
+	name := make([]byte, length)
+	if _, err := io.ReadFull(rr.reader, name); err != nil {
+		return "", rr.truncated(err)
+	}
+	return string(name), nil
+}
+
+// uvarint reads a uvarint, treating EOF mid-record as truncation
+func (rr *RecordingReader) uvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(rr.reader)
+	if err != nil {
+		return 0, rr.truncated(err)
+	}
+	return v, nil
+}
+
+// truncated converts EOF inside a record into a descriptive error
+func (rr *RecordingReader) truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("recording truncated: %w", io.ErrUnexpectedEOF)
+	}
+	return err
+}
@@ -0,0 +1,221 @@
+package drivers
+
+import (
+	"bytes"
+	"gamehook/internal/types"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRecordingFrames returns frames that change a few bytes each, add a save
+// RAM block at frame 5 and grow the bus block at frame 8
+func testRecordingFrames() []FrameBlocks {
+	var frames []FrameBlocks
+	wram := make([]byte, 64)
+	for i := 0; i < 12; i++ {
+		wram[i%len(wram)] = byte(i + 1)
+		wram[40] = byte(i * 3)
+		if i == 8 {
+			wram = append(wram, 0xEE, 0xEF)
+		}
+
+		frame := FrameBlocks{"": {0xC000: append([]byte(nil), wram...)}}
+		if i >= 5 {
+			frame["sram"] = map[uint32][]byte{0x0000: {byte(i), 0x55}}
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func writeTestRecording(t *testing.T, keyframeInterval int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.ghrec")
+	recorder, err := NewSessionRecorder(path, keyframeInterval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, frame := range testRecordingFrames() {
+		if err := recorder.Record(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if recorder.FrameCount() != 12 {
+		t.Errorf("recorder counted %d frames, want 12", recorder.FrameCount())
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Record(FrameBlocks{}); err == nil {
+		t.Error("recorded into a closed recorder")
+	}
+	return path
+}
+
+func sameFrame(a, b FrameBlocks) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for domain, blocks := range a {
+		if len(blocks) != len(b[domain]) {
+			return false
+		}
+		for start, data := range blocks {
+			if !bytes.Equal(data, b[domain][start]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	frames := testRecordingFrames()
+
+	// Every third frame a keyframe, so deltas follow keyframes and layout changes
+	path := writeTestRecording(t, 3)
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reader, err := NewRecordingReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if since := time.Since(reader.StartTime()); since < 0 || since > time.Minute {
+		t.Errorf("recording started %v ago", since)
+	}
+
+	var lastOffset time.Duration
+	for i, want := range frames {
+		frame, err := reader.Next()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if frame.Index != uint64(i) || frame.Offset < lastOffset {
+			t.Errorf("frame %d decoded as index %d at %v", i, frame.Index, frame.Offset)
+		}
+		lastOffset = frame.Offset
+		if !sameFrame(frame.Blocks, want) {
+			t.Errorf("frame %d decoded as %v, want %v", i, frame.Blocks, want)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("read past the last frame returned %v, want EOF", err)
+	}
+}
+
+func TestRecordingDeltasAreSmallerThanKeyframes(t *testing.T) {
+	keyframes, err := os.Stat(writeTestRecording(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deltas, err := os.Stat(writeTestRecording(t, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Three keyframes (the first and two layout changes) and nine small deltas
+	if deltas.Size()*2 > keyframes.Size() {
+		t.Errorf("delta recording is %d bytes, keyframe-only %d", deltas.Size(), keyframes.Size())
+	}
+}
+
+func TestRecordingRejectsDamagedFiles(t *testing.T) {
+	data, err := os.ReadFile(writeTestRecording(t, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewRecordingReader(bytes.NewReader([]byte("NOTREC\x02"))); err == nil {
+		t.Error("accepted a file without the magic")
+	}
+
+	// A cut-off recording fails instead of ending early
+	reader, err := NewRecordingReader(bytes.NewReader(data[:len(data)-3]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for err == nil {
+		_, err = reader.Next()
+	}
+	if err == io.EOF || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("truncated recording ended with %v", err)
+	}
+}
+
+func TestReplaySeeksAndLoops(t *testing.T) {
+	path := writeTestRecording(t, 3)
+	frames := testRecordingFrames()
+	wram := []types.MemoryBlock{{Name: "wram", Start: 0xC000, End: 0xC03F}}
+
+	readFrame := func(replay *ReplayDriver) uint64 {
+		t.Helper()
+		blocks, err := replay.ReadMemoryBlocks(wram)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frame := replay.Status()["frame"].(uint64)
+		if want := frames[frame][""][0xC000][:64]; !bytes.Equal(blocks[0xC000], want) {
+			t.Errorf("frame %d read % X, want % X", frame, blocks[0xC000], want)
+		}
+		return frame
+	}
+
+	// Stepwise: ticks never advance, steps seek forward and wrap when looping
+	stepwise := NewReplayDriver(path, 1, true, true)
+	defer stepwise.Close()
+	if err := stepwise.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	stepwise.Tick()
+	if frame := readFrame(stepwise); frame != 0 {
+		t.Errorf("stepwise tick moved to frame %d", frame)
+	}
+	stepwise.Step(7)
+	if frame := readFrame(stepwise); frame != 7 {
+		t.Errorf("step of 7 reached frame %d", frame)
+	}
+	stepwise.Step(4) // Frame 11 is the last
+	if err := stepwise.Step(1); err != nil {
+		t.Fatal(err)
+	}
+	if frame := readFrame(stepwise); frame != 0 {
+		t.Errorf("looping past the end reached frame %d, want 0", frame)
+	}
+
+	// Speed 0: one frame per tick, then the end without looping
+	once := NewReplayDriver(path, 0, false, false)
+	defer once.Close()
+	for want := uint64(0); want < 12; want++ {
+		if frame := readFrame(once); frame != want {
+			t.Fatalf("tick %d read frame %d", want, frame)
+		}
+		if err := once.Tick(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if frame := readFrame(once); frame != 11 || once.Status()["finished"] != true {
+		t.Errorf("replay ended at frame %d, finished %v", frame, once.Status()["finished"])
+	}
+	if err := once.Step(1); err == nil {
+		t.Error("stepping past the end without looping succeeded")
+	}
+
+	// Timed playback fast enough that every frame is due releases the last one
+	fast := NewReplayDriver(path, 1e6, false, false)
+	defer fast.Close()
+	if err := fast.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	fast.Tick()
+	if frame := readFrame(fast); frame != 11 {
+		t.Errorf("fast replay reached frame %d, want 11", frame)
+	}
+}
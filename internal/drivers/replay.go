@@ -0,0 +1,308 @@
+package drivers
+
+import (
+	"fmt"
+	"gamehook/internal/types"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReplayDriver plays back a session recording as if it were a live emulator.
+//
+// In timed mode frames are released on each Tick according to their recorded
+// timestamps scaled by speed (1.0 is the original speed, 2.0 twice as fast, 0
+// releases one frame per tick). In stepwise mode frames only advance through
+// Step. Reads never advance playback, so every domain read in one update tick
+// sees the same frame. Writes patch the current frame and are overwritten by
+// the next one.
+type ReplayDriver struct {
+	path     string
+	speed    float64
+	stepwise bool
+	loop     bool
+
+	mu        sync.Mutex
+	file      *os.File
+	reader    *RecordingReader
+	current   FrameBlocks
+	next      *RecordedFrame
+	frame     uint64
+	offset    time.Duration
+	started   time.Time
+	finished  bool
+	lastError error
+}
+
+// NewReplayDriver creates a new replay driver for the recording at path
+func NewReplayDriver(path string, speed float64, stepwise bool, loop bool) *ReplayDriver {
+	if speed < 0 {
+		speed = 1.0
+	}
+	return &ReplayDriver{
+		path:     path,
+		speed:    speed,
+		stepwise: stepwise,
+		loop:     loop,
+	}
+}
+
+// Connect opens the recording and loads its first frame
+func (d *ReplayDriver) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.reader != nil {
+		return nil
+	}
+
+	return d.openLocked()
+}
+
+// openLocked (re)opens the recording from the beginning
+func (d *ReplayDriver) openLocked() error {
+	d.closeLocked()
+
+	file, err := os.Open(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+
+	reader, err := NewRecordingReader(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	d.file = file
+	d.reader = reader
+	d.finished = false
+	d.lastError = nil
+	d.started = time.Now()
+
+	first, err := reader.Next()
+	if err != nil {
+		d.closeLocked()
+		if err == io.EOF {
+			return fmt.Errorf("recording %s has no frames", d.path)
+		}
+		return err
+	}
+	d.applyLocked(first)
+
+	fmt.Printf("📼 Replaying %s (recorded %s)\n", d.path, reader.StartTime().Format(time.RFC3339))
+	return nil
+}
+
+// applyLocked makes frame the current frame and prefetches the one after it
+func (d *ReplayDriver) applyLocked(frame *RecordedFrame) {
+	if d.current == nil {
+		d.current = make(FrameBlocks, len(frame.Blocks))
+	}
+	d.current.copyFrom(frame.Blocks)
+	d.frame = frame.Index
+	d.offset = frame.Offset
+
+	next, err := d.reader.Next()
+	if err != nil {
+		d.next = nil
+		d.finished = true
+		if err != io.EOF {
+			d.lastError = err
+			fmt.Printf("⚠️  Replay stopped at frame %d: %v\n", d.frame, err)
+		}
+		return
+	}
+	d.next = next
+}
+
+// advanceLocked releases every frame that is due at the current playback time
+func (d *ReplayDriver) advanceLocked() error {
+	if d.finished {
+		if !d.loop || d.lastError != nil {
+			return nil
+		}
+		return d.openLocked()
+	}
+
+	if d.speed == 0 {
+		d.applyLocked(d.next)
+		return nil
+	}
+
+	elapsed := time.Duration(float64(time.Since(d.started)) * d.speed)
+	for d.next != nil && d.next.Offset <= elapsed {
+		d.applyLocked(d.next)
+	}
+
+	return nil
+}
+
+// Tick advances timed playback. It is called once per update tick, before
+// that tick's reads; stepwise replays ignore it.
+func (d *ReplayDriver) Tick() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.reader == nil || d.stepwise {
+		return nil
+	}
+	return d.advanceLocked()
+}
+
+// Step advances a stepwise replay by n frames
+func (d *ReplayDriver) Step(n int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.reader == nil {
+		if err := d.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if d.finished {
+			if !d.loop || d.lastError != nil {
+				return fmt.Errorf("end of recording at frame %d", d.frame)
+			}
+			if err := d.openLocked(); err != nil {
+				return err
+			}
+			continue
+		}
+		d.applyLocked(d.next)
+	}
+
+	return nil
+}
+
+// Status returns the current playback position
+func (d *ReplayDriver) Status() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	status := map[string]interface{}{
+		"path":     d.path,
+		"speed":    d.speed,
+		"stepwise": d.stepwise,
+		"loop":     d.loop,
+		"frame":    d.frame,
+		"offset":   d.offset.String(),
+		"finished": d.finished,
+	}
+	if d.lastError != nil {
+		status["error"] = d.lastError.Error()
+	}
+	return status
+}
+
+// ReadMemoryBlocks returns the blocks of the current replay frame. Blocks
+// with a domain are read from that domain's recorded blocks.
+func (d *ReplayDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.reader == nil {
+		if err := d.openLocked(); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[uint32][]byte)
+
+	for _, block := range blocks {
+		data := make([]byte, block.End-block.Start+1)
+		if !d.copyLocked(block.Domain, block.Start, data) {
+			return nil, fmt.Errorf("block %s (%s 0x%X-0x%X) is not in the recording", block.Name, recordedDomainName(block.Domain), block.Start, block.End)
+		}
+		result[block.Start] = data
+	}
+
+	return result, nil
+}
+
+// recordedDomainName describes a domain in errors
+func recordedDomainName(domain string) string {
+	if domain == "" {
+		return "system bus"
+	}
+	return domain
+}
+
+// copyLocked fills buf from the recorded blocks of domain, reporting whether every byte was covered
+func (d *ReplayDriver) copyLocked(domain string, address uint32, buf []byte) bool {
+	covered := 0
+	end := uint64(address) + uint64(len(buf))
+
+	for start, data := range d.current[domain] {
+		from := uint64(address)
+		if from < uint64(start) {
+			from = uint64(start)
+		}
+		to := end
+		if recordedEnd := uint64(start) + uint64(len(data)); to > recordedEnd {
+			to = recordedEnd
+		}
+		if from >= to {
+			continue
+		}
+		covered += copy(buf[from-uint64(address):to-uint64(address)], data[from-uint64(start):to-uint64(start)])
+	}
+
+	return covered == len(buf)
+}
+
+// WriteBytes patches the current replay frame
+func (d *ReplayDriver) WriteBytes(address uint32, data []byte) error {
+	return d.WriteDomainBytes("", address, data)
+}
+
+// HasDomain reports whether the current frame holds blocks of domain
+func (d *ReplayDriver) HasDomain(domain string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.reader == nil {
+		if err := d.openLocked(); err != nil {
+			return false
+		}
+	}
+	_, ok := d.current[domain]
+	return ok
+}
+
+// WriteDomainBytes patches a domain of the current replay frame
+func (d *ReplayDriver) WriteDomainBytes(domain string, address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for start, block := range d.current[domain] {
+		if address >= start && uint64(address)+uint64(len(data)) <= uint64(start)+uint64(len(block)) {
+			copy(block[address-start:], data)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("address %s 0x%X is not in the recording", recordedDomainName(domain), address)
+}
+
+// Close closes the recording file
+func (d *ReplayDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closeLocked()
+}
+
+// closeLocked releases the recording file
+func (d *ReplayDriver) closeLocked() error {
+	d.reader = nil
+	d.next = nil
+	if d.file == nil {
+		return nil
+	}
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
@@ -0,0 +1,64 @@
+package drivers
+
+import (
+	"bytes"
+	"gamehook/internal/types"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplayDomainsAdvanceOncePerTick(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.ghrec")
+	recorder, err := NewSessionRecorder(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := byte(0); i < 3; i++ {
+		frame := FrameBlocks{
+			"":     {0xC000: {i, i + 1}},
+			"sram": {0x0000: {0xA0 + i}},
+		}
+		if err := recorder.Record(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay := NewReplayDriver(path, 0, false, false)
+	defer replay.Close()
+	router := NewDomainRouter(replay, nil)
+
+	// Each tick reads the bus and save RAM separately, as the update loop does
+	for i := byte(0); i < 3; i++ {
+		if i > 0 {
+			if err := replay.Tick(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		bus, err := replay.ReadMemoryBlocks([]types.MemoryBlock{{Name: "wram", Start: 0xC000, End: 0xC001}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		blocks, offset, err := router.Resolve("sram", []types.MemoryBlock{{Name: "sram", Domain: "sram", Start: 0, End: 0}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sram, err := replay.ReadMemoryBlocks(blocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sram = Rekey(sram, offset)
+
+		if !bytes.Equal(bus[0xC000], []byte{i, i + 1}) || !bytes.Equal(sram[0], []byte{0xA0 + i}) {
+			t.Errorf("tick %d read bus % X and sram % X", i, bus[0xC000], sram[0])
+		}
+	}
+
+	if _, err := replay.ReadMemoryBlocks([]types.MemoryBlock{{Name: "vram", Domain: "vram", Start: 0, End: 0}}); err == nil {
+		t.Error("read of an unrecorded domain succeeded")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,6 +21,41 @@ type AdaptiveRetroArchDriver struct {
 	maxChunkSize  uint32 // Maximum bytes to read in one request
 	bufferSize    int    // Socket buffer size
 	testChunkSize uint32 // Size to test for optimal chunk size
+
+	// Pipelining parameters
+	pipelineDepth int // READ_CORE_MEMORY requests kept in flight (1 = request/response)
+	chunkRetries  int // Retransmissions of a lost chunk before the tick fails
+
+	// mu serializes ticks and writes on the shared UDP socket
+	mu    sync.Mutex
+	stats retroArchStats
+
+	// lateReplies is set when a request went unanswered in time, so its
+	// reply may still arrive and must not be taken for a later request's
+	lateReplies bool
+}
+
+// retroArchStats tracks per-tick read latency
+type retroArchStats struct {
+	ticks         uint64
+	lastLatency   time.Duration
+	avgLatency    time.Duration // Exponential moving average
+	maxLatency    time.Duration
+	lastRequests  int
+	retransmits   uint64
+	staleReplies  uint64
+	failedTicks   uint64
+	lastTickStart time.Time
+}
+
+// pipelinedChunk is one READ_CORE_MEMORY request of a pipelined tick
+type pipelinedChunk struct {
+	block    uint32 // Start of the memory block the chunk belongs to
+	offset   uint32 // Offset of the chunk inside the block
+	address  uint32
+	length   uint32
+	attempts int
+	sentAt   time.Time
 }
 
 // Platform-specific configurations
@@ -59,7 +95,21 @@ func NewAdaptiveRetroArchDriver(host string, port int, requestTimeout time.Durat
 		maxChunkSize:   2048,        // Default safe chunk size
 		bufferSize:     1024 * 1024, // Default 1MB buffer
 		testChunkSize:  1024,        // Start testing with 1KB
+		pipelineDepth:  1,
+		chunkRetries:   3,
+	}
+}
+
+// SetPipelineDepth sets how many READ_CORE_MEMORY requests may be in flight at once.
+// A depth of 1 keeps the strict request/response behaviour.
+func (d *AdaptiveRetroArchDriver) SetPipelineDepth(depth int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if depth < 1 {
+		depth = 1
 	}
+	d.pipelineDepth = depth
 }
 
 // SetPlatform configures optimal settings for a specific platform
@@ -67,6 +117,9 @@ func (d *AdaptiveRetroArchDriver) SetPlatform(platform string) {
 	// Normalize platform name (uppercase, trim spaces)
 	normalizedPlatform := strings.ToUpper(strings.TrimSpace(platform))
 
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	if config, exists := platformConfigs[normalizedPlatform]; exists {
 		d.maxChunkSize = config.maxChunkSize
 		d.bufferSize = config.bufferSize
@@ -80,6 +133,14 @@ func (d *AdaptiveRetroArchDriver) SetPlatform(platform string) {
 
 // Connect establishes connection to RetroArch and auto-detects optimal settings
 func (d *AdaptiveRetroArchDriver) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.connectLocked()
+}
+
+// connectLocked opens the connection; the caller must hold d.mu
+func (d *AdaptiveRetroArchDriver) connectLocked() error {
 	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", d.host, d.port))
 	if err != nil {
 		return fmt.Errorf("failed to resolve UDP address: %w", err)
@@ -158,23 +219,270 @@ func (d *AdaptiveRetroArchDriver) testConnection() error {
 
 // ReadMemoryBlocks reads multiple memory blocks from RetroArch
 func (d *AdaptiveRetroArchDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	if d.conn == nil {
-		if err := d.Connect(); err != nil {
+		if err := d.connectLocked(); err != nil {
 			return nil, err
 		}
 	}
 
+	start := time.Now()
+	if err := d.drainLateReplies(); err != nil {
+		d.recordTick(start, 0, err)
+		return nil, err
+	}
+
+	var result map[uint32][]byte
+	var requests int
+	var err error
+
+	if d.pipelineDepth > 1 {
+		result, requests, err = d.readPipelined(blocks)
+	} else {
+		result, requests, err = d.readSequential(blocks)
+	}
+	if err != nil {
+		d.lateReplies = true
+	}
+
+	d.recordTick(start, requests, err)
+	return result, err
+}
+
+// lateReplyWindow is how long drainLateReplies waits for replies still on their way
+const lateReplyWindow = 2 * time.Millisecond
+
+// drainLateReplies discards the replies to requests that timed out in earlier
+// ticks, which would otherwise be read as replies to this tick's requests for
+// the same address
+func (d *AdaptiveRetroArchDriver) drainLateReplies() error {
+	if !d.lateReplies {
+		return nil
+	}
+
+	buffer := make([]byte, int(d.maxChunkSize)*3+64)
+	if err := d.conn.SetReadDeadline(time.Now().Add(lateReplyWindow)); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	for {
+		if _, err := d.conn.Read(buffer); err != nil {
+			if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+				return fmt.Errorf("failed to read response: %w", err)
+			}
+			d.lateReplies = false
+			return nil
+		}
+		d.stats.staleReplies++
+	}
+}
+
+// readSequential reads blocks one request at a time
+func (d *AdaptiveRetroArchDriver) readSequential(blocks []types.MemoryBlock) (map[uint32][]byte, int, error) {
 	result := make(map[uint32][]byte)
+	requests := 0
 
 	for _, block := range blocks {
-		data, err := d.ReadMemory(block.Start, block.End-block.Start+1)
+		length := block.End - block.Start + 1
+		data, err := d.ReadMemory(block.Start, length)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read block %s: %w", block.Name, err)
+			return nil, requests, fmt.Errorf("failed to read block %s: %w", block.Name, err)
 		}
+		requests += int((length + d.maxChunkSize - 1) / d.maxChunkSize)
 		result[block.Start] = data
 	}
 
-	return result, nil
+	return result, requests, nil
+}
+
+// readPipelined keeps up to pipelineDepth chunk requests in flight, matching
+// replies by their echoed address and retransmitting lost chunks individually
+func (d *AdaptiveRetroArchDriver) readPipelined(blocks []types.MemoryBlock) (map[uint32][]byte, int, error) {
+	result := make(map[uint32][]byte, len(blocks))
+	var queue []*pipelinedChunk
+
+	for _, block := range blocks {
+		length := block.End - block.Start + 1
+		result[block.Start] = make([]byte, length)
+		for offset := uint32(0); offset < length; offset += d.maxChunkSize {
+			chunkSize := d.maxChunkSize
+			if length-offset < chunkSize {
+				chunkSize = length - offset
+			}
+			queue = append(queue, &pipelinedChunk{
+				block:   block.Start,
+				offset:  offset,
+				address: block.Start + offset,
+				length:  chunkSize,
+			})
+		}
+	}
+
+	requests := len(queue)
+	inFlight := make(map[uint32]*pipelinedChunk, d.pipelineDepth)
+	buffer := make([]byte, int(d.maxChunkSize)*3+64)
+
+	for len(queue) > 0 || len(inFlight) > 0 {
+		// Fill the pipeline. A chunk whose address is already in flight waits,
+		// since replies can only be told apart by address.
+		for i := 0; i < len(queue) && len(inFlight) < d.pipelineDepth; {
+			chunk := queue[i]
+			if _, busy := inFlight[chunk.address]; busy {
+				i++
+				continue
+			}
+			if err := d.sendChunkRequest(chunk); err != nil {
+				return nil, requests, err
+			}
+			inFlight[chunk.address] = chunk
+			queue = append(queue[:i], queue[i+1:]...)
+		}
+
+		// Wait for the next reply, or until the oldest request times out
+		deadline := time.Time{}
+		for _, chunk := range inFlight {
+			if expiry := chunk.sentAt.Add(d.requestTimeout); deadline.IsZero() || expiry.Before(deadline) {
+				deadline = expiry
+			}
+		}
+		if err := d.conn.SetReadDeadline(deadline); err != nil {
+			return nil, requests, fmt.Errorf("failed to set deadline: %w", err)
+		}
+
+		n, err := d.conn.Read(buffer)
+		if err != nil {
+			if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+				return nil, requests, fmt.Errorf("failed to read response: %w", err)
+			}
+
+			// Retransmit every request that has been waiting too long
+			now := time.Now()
+			for _, chunk := range inFlight {
+				if now.Sub(chunk.sentAt) < d.requestTimeout {
+					continue
+				}
+				if chunk.attempts > d.chunkRetries {
+					return nil, requests, fmt.Errorf("chunk at 0x%X lost after %d attempts", chunk.address, chunk.attempts)
+				}
+				if err := d.sendChunkRequest(chunk); err != nil {
+					return nil, requests, err
+				}
+				d.lateReplies = true
+				d.stats.retransmits++
+				requests++
+			}
+			continue
+		}
+
+		address, data, err := parseReadReply(strings.TrimSpace(string(buffer[:n])))
+		if err != nil {
+			// Late replies to other commands, or garbage
+			d.stats.staleReplies++
+			continue
+		}
+
+		chunk, ok := inFlight[address]
+		if ok && data == nil {
+			return nil, requests, fmt.Errorf("RetroArch returned error for address %x", address)
+		}
+		if !ok || uint32(len(data)) != chunk.length {
+			// A duplicate reply to a chunk that was retransmitted and already answered
+			d.stats.staleReplies++
+			continue
+		}
+
+		copy(result[chunk.block][chunk.offset:], data)
+		delete(inFlight, address)
+	}
+
+	return result, requests, nil
+}
+
+// sendChunkRequest sends the READ_CORE_MEMORY request for a chunk
+func (d *AdaptiveRetroArchDriver) sendChunkRequest(chunk *pipelinedChunk) error {
+	if err := d.conn.SetWriteDeadline(time.Now().Add(d.requestTimeout)); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	command := fmt.Sprintf("READ_CORE_MEMORY %x %d", chunk.address, chunk.length)
+	if _, err := d.conn.Write([]byte(command)); err != nil {
+		return fmt.Errorf("failed to send command: %w", err)
+	}
+
+	chunk.attempts++
+	chunk.sentAt = time.Now()
+	return nil
+}
+
+// parseReadReply parses "READ_CORE_MEMORY <address> <bytes...>". An error reply
+// ("-1") returns the address with nil data.
+func parseReadReply(response string) (uint32, []byte, error) {
+	parts := strings.Fields(response)
+	if len(parts) < 3 || parts[0] != "READ_CORE_MEMORY" {
+		return 0, nil, fmt.Errorf("invalid response format: %s", response)
+	}
+
+	address, err := strconv.ParseUint(strings.TrimPrefix(parts[1], "0x"), 16, 32)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid address in response: %s", parts[1])
+	}
+
+	if parts[2] == "-1" {
+		return uint32(address), nil, nil
+	}
+
+	data := make([]byte, len(parts)-2)
+	for i, byteStr := range parts[2:] {
+		b, err := strconv.ParseUint(byteStr, 16, 8)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid byte value %s: %w", byteStr, err)
+		}
+		data[i] = byte(b)
+	}
+
+	return uint32(address), data, nil
+}
+
+// recordTick updates the latency statistics for one ReadMemoryBlocks call
+func (d *AdaptiveRetroArchDriver) recordTick(start time.Time, requests int, err error) {
+	latency := time.Since(start)
+
+	d.stats.ticks++
+	d.stats.lastTickStart = start
+	d.stats.lastLatency = latency
+	d.stats.lastRequests = requests
+	if err != nil {
+		d.stats.failedTicks++
+	}
+	if latency > d.stats.maxLatency {
+		d.stats.maxLatency = latency
+	}
+	if d.stats.avgLatency == 0 {
+		d.stats.avgLatency = latency
+	} else {
+		d.stats.avgLatency = (d.stats.avgLatency*7 + latency) / 8
+	}
+}
+
+// Stats returns per-tick read latency and pipelining counters
+func (d *AdaptiveRetroArchDriver) Stats() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return map[string]interface{}{
+		"pipeline_depth":  d.pipelineDepth,
+		"chunk_size":      d.maxChunkSize,
+		"ticks":           d.stats.ticks,
+		"failed_ticks":    d.stats.failedTicks,
+		"last_latency_ms": float64(d.stats.lastLatency) / float64(time.Millisecond),
+		"avg_latency_ms":  float64(d.stats.avgLatency) / float64(time.Millisecond),
+		"max_latency_ms":  float64(d.stats.maxLatency) / float64(time.Millisecond),
+		"last_requests":   d.stats.lastRequests,
+		"retransmits":     d.stats.retransmits,
+		"stale_replies":   d.stats.staleReplies,
+		"last_tick_at":    d.stats.lastTickStart,
+	}
 }
 
 // ReadMemory reads memory using adaptive chunking
@@ -230,35 +538,28 @@ func (d *AdaptiveRetroArchDriver) readChunk(address uint32, length uint32) ([]by
 	// Build command: READ_CORE_MEMORY <address> <length>
 	command := fmt.Sprintf("READ_CORE_MEMORY %s %d", addrStr, length)
 
-	response, err := d.sendCommand(command)
+	// Replies for another address answer earlier requests and are skipped
+	var data []byte
+	_, err := d.exchange(command, func(response string) bool {
+		replyAddress, replyData, err := parseReadReply(response)
+		if err != nil || replyAddress != address {
+			d.stats.staleReplies++
+			return false
+		}
+		data = replyData
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse response: "READ_CORE_MEMORY <address> <byte1> <byte2> ..."
-	parts := strings.Fields(response)
-	if len(parts) < 3 {
-		return nil, fmt.Errorf("invalid response format: %s", response)
-	}
-
 	// Check for error response
-	if parts[2] == "-1" {
+	if data == nil {
 		return nil, fmt.Errorf("RetroArch returned error for address %s", addrStr)
 	}
 
-	// Skip command name and address, parse the bytes
-	byteStrings := parts[2:]
-	if len(byteStrings) != int(length) {
-		return nil, fmt.Errorf("expected %d bytes, got %d", length, len(byteStrings))
-	}
-
-	data := make([]byte, length)
-	for i, byteStr := range byteStrings {
-		b, err := strconv.ParseUint(byteStr, 16, 8)
-		if err != nil {
-			return nil, fmt.Errorf("invalid byte value %s: %w", byteStr, err)
-		}
-		data[i] = byte(b)
+	if len(data) != int(length) {
+		return nil, fmt.Errorf("expected %d bytes, got %d", length, len(data))
 	}
 
 	return data, nil
@@ -266,6 +567,9 @@ func (d *AdaptiveRetroArchDriver) readChunk(address uint32, length uint32) ([]by
 
 // WriteBytes writes bytes to RetroArch
 func (d *AdaptiveRetroArchDriver) WriteBytes(address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	if d.conn == nil {
 		return fmt.Errorf("not connected to RetroArch")
 	}
@@ -321,6 +625,14 @@ func (d *AdaptiveRetroArchDriver) writeChunk(address uint32, data []byte) error
 
 // Close closes the connection
 func (d *AdaptiveRetroArchDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.closeLocked()
+}
+
+// closeLocked closes the connection; the caller must hold d.mu
+func (d *AdaptiveRetroArchDriver) closeLocked() error {
 	if d.conn != nil {
 		err := d.conn.Close()
 		d.conn = nil
@@ -331,6 +643,16 @@ func (d *AdaptiveRetroArchDriver) Close() error {
 
 // sendCommand sends a command to RetroArch and returns the response
 func (d *AdaptiveRetroArchDriver) sendCommand(command string) (string, error) {
+	// Skip late replies to earlier (e.g. retransmitted) requests
+	name, _, _ := strings.Cut(command, " ")
+	return d.exchange(command, func(response string) bool {
+		return name == "VERSION" || strings.HasPrefix(response, name)
+	})
+}
+
+// exchange sends command and returns the first reply accept takes. A request
+// that times out leaves its reply on the way, so later ticks drain it first.
+func (d *AdaptiveRetroArchDriver) exchange(command string, accept func(response string) bool) (string, error) {
 	if d.conn == nil {
 		return "", fmt.Errorf("not connected")
 	}
@@ -353,11 +675,165 @@ func (d *AdaptiveRetroArchDriver) sendCommand(command string) (string, error) {
 	}
 
 	buffer := make([]byte, bufferSize)
-	n, err := d.conn.Read(buffer)
+	for {
+		n, err := d.conn.Read(buffer)
+		if err != nil {
+			d.lateReplies = true
+			return "", fmt.Errorf("failed to read response: %w", err)
+		}
+
+		response := strings.TrimSpace(string(buffer[:n]))
+		if accept(response) {
+			return response, nil
+		}
+	}
+}
+
+// frameAdvanceInterval spaces FRAMEADVANCE commands so RetroArch runs one frame per command
+const frameAdvanceInterval = 20 * time.Millisecond
+
+// EmulationStatus queries GET_STATUS
+func (d *AdaptiveRetroArchDriver) EmulationStatus() (EmulationStatus, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return EmulationStatus{}, fmt.Errorf("not connected to RetroArch")
+	}
+
+	response, err := d.sendCommand("GET_STATUS")
+	if err != nil {
+		return EmulationStatus{}, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	return parseRetroArchStatus(response)
+}
+
+// Pause pauses emulation if it is running
+func (d *AdaptiveRetroArchDriver) Pause() error {
+	return d.setPaused(true)
+}
+
+// Resume resumes emulation if it is paused
+func (d *AdaptiveRetroArchDriver) Resume() error {
+	return d.setPaused(false)
+}
+
+// TogglePause sends PAUSE_TOGGLE
+func (d *AdaptiveRetroArchDriver) TogglePause() error {
+	return d.control("PAUSE_TOGGLE")
+}
+
+// FrameAdvance sends one FRAMEADVANCE per frame. RetroArch pauses before the first frame.
+func (d *AdaptiveRetroArchDriver) FrameAdvance(frames int) error {
+	if frames < 1 {
+		frames = 1
+	}
+
+	for i := 0; i < frames; i++ {
+		if i > 0 {
+			time.Sleep(frameAdvanceInterval)
+		}
+		if err := d.control("FRAMEADVANCE"); err != nil {
+			return fmt.Errorf("frame %d of %d: %w", i+1, frames, err)
+		}
+	}
+
+	return nil
+}
+
+// Reset sends RESET
+func (d *AdaptiveRetroArchDriver) Reset() error {
+	return d.control("RESET")
+}
+
+// SaveState sends SAVE_STATE for the current slot
+func (d *AdaptiveRetroArchDriver) SaveState() error {
+	return d.control("SAVE_STATE")
+}
+
+// LoadState sends LOAD_STATE for the current slot
+func (d *AdaptiveRetroArchDriver) LoadState() error {
+	return d.control("LOAD_STATE")
+}
+
+// ChangeStateSlot sends STATE_SLOT_PLUS or STATE_SLOT_MINUS once per step
+func (d *AdaptiveRetroArchDriver) ChangeStateSlot(delta int) error {
+	command := "STATE_SLOT_PLUS"
+	if delta < 0 {
+		command = "STATE_SLOT_MINUS"
+		delta = -delta
+	}
+
+	for i := 0; i < delta; i++ {
+		if err := d.control(command); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setPaused toggles pause only when the current state differs
+func (d *AdaptiveRetroArchDriver) setPaused(paused bool) error {
+	status, err := d.EmulationStatus()
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return err
+	}
+	if status.State == "contentless" {
+		return fmt.Errorf("no content is running")
+	}
+	if status.Paused == paused {
+		return nil
+	}
+
+	return d.control("PAUSE_TOGGLE")
+}
+
+// control sends a command that RetroArch does not reply to
+func (d *AdaptiveRetroArchDriver) control(command string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return fmt.Errorf("not connected to RetroArch")
+	}
+
+	if err := d.conn.SetWriteDeadline(time.Now().Add(d.requestTimeout)); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	if _, err := d.conn.Write([]byte(command)); err != nil {
+		return fmt.Errorf("failed to send %s: %w", command, err)
+	}
+
+	return nil
+}
+
+// parseRetroArchStatus parses a GET_STATUS reply such as
+// "GET_STATUS PAUSED game_boy,Pokemon Red,crc32=9f7fdd53" or "GET_STATUS CONTENTLESS"
+func parseRetroArchStatus(response string) (EmulationStatus, error) {
+	fields := strings.SplitN(strings.TrimSpace(response), " ", 3)
+	if len(fields) < 2 || fields[0] != "GET_STATUS" {
+		return EmulationStatus{}, fmt.Errorf("unexpected status reply: %q", response)
+	}
+
+	status := EmulationStatus{State: strings.ToLower(fields[1])}
+	switch status.State {
+	case "paused":
+		status.Paused = true
+	case "playing", "contentless":
+	default:
+		return EmulationStatus{}, fmt.Errorf("unknown emulation state %q", fields[1])
+	}
+
+	if len(fields) == 3 {
+		details := fields[2]
+		if i := strings.LastIndex(details, ",crc32="); i >= 0 {
+			status.CRC32 = strings.ToLower(details[i+len(",crc32="):])
+			details = details[:i]
+		}
+		status.System, status.Content, _ = strings.Cut(details, ",")
 	}
 
-	response := strings.TrimSpace(string(buffer[:n]))
-	return response, nil
+	return status, nil
 }
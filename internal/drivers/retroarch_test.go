@@ -0,0 +1,265 @@
+package drivers
+
+import (
+	"bytes"
+	"fmt"
+	"gamehook/internal/types"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRetroArch answers VERSION and READ_CORE_MEMORY over UDP from memory at address 0
+type fakeRetroArch struct {
+	t    *testing.T
+	conn *net.UDPConn
+
+	mu     sync.Mutex
+	memory []byte
+	reads  map[uint32]int // READ_CORE_MEMORY requests per address since the intercept was set
+
+	// intercept may drop, hold back or add to the reply to a read. It returns
+	// true when it took care of the reply.
+	intercept func(address uint32, attempt int, reply string, send func(string)) bool
+}
+
+func newFakeRetroArch(t *testing.T, size int) *fakeRetroArch {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeRetroArch{t: t, conn: conn, memory: make([]byte, size), reads: make(map[uint32]int)}
+	for i := range fake.memory {
+		fake.memory[i] = byte(i*3 + 1)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go fake.serve()
+	return fake
+}
+
+// driver connects a driver to the fake with small chunks, so reads take many requests
+func (f *fakeRetroArch) driver(pipelineDepth int) *AdaptiveRetroArchDriver {
+	f.t.Helper()
+	driver := NewAdaptiveRetroArchDriver("127.0.0.1", f.conn.LocalAddr().(*net.UDPAddr).Port, 50*time.Millisecond)
+	if err := driver.Connect(); err != nil {
+		f.t.Fatal(err)
+	}
+	f.t.Cleanup(func() { driver.Close() })
+	driver.maxChunkSize = 16
+	driver.SetPipelineDepth(pipelineDepth)
+	return driver
+}
+
+func (f *fakeRetroArch) setIntercept(intercept func(address uint32, attempt int, reply string, send func(string)) bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.intercept = intercept
+	f.reads = make(map[uint32]int)
+}
+
+func (f *fakeRetroArch) setMemory(address int, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copy(f.memory[address:], data)
+}
+
+// readReply formats the reply to a read of the current memory
+func (f *fakeRetroArch) readReply(address, length uint32) string {
+	if uint64(address)+uint64(length) > uint64(len(f.memory)) {
+		return fmt.Sprintf("READ_CORE_MEMORY %x -1", address)
+	}
+	var reply strings.Builder
+	fmt.Fprintf(&reply, "READ_CORE_MEMORY %x", address)
+	for _, b := range f.memory[address : address+length] {
+		fmt.Fprintf(&reply, " %02x", b)
+	}
+	return reply.String()
+}
+
+func (f *fakeRetroArch) serve() {
+	buffer := make([]byte, 1024)
+	for {
+		n, client, err := f.conn.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		send := func(reply string) { f.conn.WriteToUDP([]byte(reply), client) }
+
+		fields := strings.Fields(string(buffer[:n]))
+		switch {
+		case len(fields) == 1 && fields[0] == "VERSION":
+			send("1.19.1")
+		case len(fields) == 3 && fields[0] == "READ_CORE_MEMORY":
+			address, _ := strconv.ParseUint(fields[1], 16, 32)
+			length, _ := strconv.ParseUint(fields[2], 10, 32)
+
+			f.mu.Lock()
+			f.reads[uint32(address)]++
+			attempt := f.reads[uint32(address)]
+			reply := f.readReply(uint32(address), uint32(length))
+			intercept := f.intercept
+			f.mu.Unlock()
+
+			if intercept == nil || !intercept(uint32(address), attempt, reply, send) {
+				send(reply)
+			}
+		}
+	}
+}
+
+var retroArchTestBlocks = []types.MemoryBlock{
+	{Name: "wram", Start: 0x000, End: 0x07F},
+	{Name: "hram", Start: 0x100, End: 0x13F},
+}
+
+// checkRetroArchRead reads the test blocks and compares them with the fake's memory
+func checkRetroArchRead(t *testing.T, fake *fakeRetroArch, driver *AdaptiveRetroArchDriver) {
+	t.Helper()
+	blocks, err := driver.ReadMemoryBlocks(retroArchTestBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for _, block := range retroArchTestBlocks {
+		if want := fake.memory[block.Start : block.End+1]; !bytes.Equal(blocks[block.Start], want) {
+			t.Errorf("%s read % X, want % X", block.Name, blocks[block.Start], want)
+		}
+	}
+}
+
+func TestRetroArchPipelinedReadsSurviveLossAndReordering(t *testing.T) {
+	fake := newFakeRetroArch(t, 0x200)
+	driver := fake.driver(4)
+
+	// Drop the first reply to every other chunk, and answer the rest in pairs,
+	// the second request's reply first
+	var held []string
+	var heldMu sync.Mutex
+	fake.setIntercept(func(address uint32, attempt int, reply string, send func(string)) bool {
+		if attempt == 1 && address/16%2 == 1 {
+			return true
+		}
+		heldMu.Lock()
+		defer heldMu.Unlock()
+		held = append(held, reply)
+		if len(held) == 2 {
+			send(held[1])
+			send(held[0])
+			held = held[:0]
+		} else {
+			// A lone request is answered late, after its retransmission
+			pending := reply
+			time.AfterFunc(80*time.Millisecond, func() {
+				heldMu.Lock()
+				defer heldMu.Unlock()
+				if len(held) == 1 && held[0] == pending {
+					send(pending)
+					held = held[:0]
+				}
+			})
+		}
+		return true
+	})
+
+	checkRetroArchRead(t, fake, driver)
+	if stats := driver.Stats(); stats["retransmits"].(uint64) == 0 {
+		t.Error("lost chunks were not retransmitted")
+	}
+}
+
+func TestRetroArchLateRepliesAreNotReadAsFresh(t *testing.T) {
+	for _, depth := range []int{1, 4} {
+		t.Run(fmt.Sprintf("depth=%d", depth), func(t *testing.T) {
+			fake := newFakeRetroArch(t, 0x200)
+			driver := fake.driver(depth)
+
+			// The first reply to the first chunk is delayed past the request
+			// timeout; the retransmission is answered at once
+			fake.setIntercept(func(address uint32, attempt int, reply string, send func(string)) bool {
+				if address == 0 && attempt == 1 {
+					time.AfterFunc(80*time.Millisecond, func() { send(reply) })
+					return true
+				}
+				return false
+			})
+			if depth == 1 {
+				// Without pipelining a timed out chunk fails the tick
+				if _, err := driver.ReadMemoryBlocks(retroArchTestBlocks); err == nil {
+					t.Fatal("read with a delayed reply succeeded")
+				}
+			} else {
+				checkRetroArchRead(t, fake, driver)
+			}
+
+			// Memory changes while the late reply is on its way
+			fake.setMemory(0, []byte{0xAA, 0xBB, 0xCC})
+			time.Sleep(120 * time.Millisecond)
+
+			checkRetroArchRead(t, fake, driver)
+		})
+	}
+}
+
+func TestRetroArchReadChunkChecksEchoedAddress(t *testing.T) {
+	fake := newFakeRetroArch(t, 0x200)
+	driver := fake.driver(1)
+
+	// Every read is preceded by a reply for another address
+	fake.setIntercept(func(address uint32, attempt int, reply string, send func(string)) bool {
+		fake.mu.Lock()
+		stale := fake.readReply(address+0x40, 16)
+		fake.mu.Unlock()
+		send(stale)
+		send(reply)
+		return true
+	})
+
+	checkRetroArchRead(t, fake, driver)
+	if stats := driver.Stats(); stats["stale_replies"].(uint64) == 0 {
+		t.Error("replies for other addresses were not skipped")
+	}
+
+	// Error replies fail the read
+	if _, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "past", Start: 0x1F8, End: 0x207}}); err == nil {
+		t.Error("read past the end of memory succeeded")
+	}
+}
+
+func TestParseRetroArchStatus(t *testing.T) {
+	tests := []struct {
+		reply string
+		want  EmulationStatus
+	}{
+		{"GET_STATUS PLAYING game_boy,Pokemon Red,crc32=9F7FDD53\n",
+			EmulationStatus{State: "playing", System: "game_boy", Content: "Pokemon Red", CRC32: "9f7fdd53"}},
+		{"GET_STATUS PAUSED super_nes,Super Metroid,crc32=d63ed5f8",
+			EmulationStatus{State: "paused", Paused: true, System: "super_nes", Content: "Super Metroid", CRC32: "d63ed5f8"}},
+		// Content names may contain commas; the CRC32 is always last
+		{"GET_STATUS PLAYING game_boy,Pokemon - Red Version (USA, Europe),crc32=9f7fdd53",
+			EmulationStatus{State: "playing", System: "game_boy", Content: "Pokemon - Red Version (USA, Europe)", CRC32: "9f7fdd53"}},
+		// Older versions leave the CRC32 out
+		{"GET_STATUS PLAYING game_boy,Pokemon Red",
+			EmulationStatus{State: "playing", System: "game_boy", Content: "Pokemon Red"}},
+		{"GET_STATUS CONTENTLESS", EmulationStatus{State: "contentless"}},
+	}
+	for _, tt := range tests {
+		got, err := parseRetroArchStatus(tt.reply)
+		if err != nil {
+			t.Errorf("%q: %v", tt.reply, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q parsed as %+v, want %+v", tt.reply, got, tt.want)
+		}
+	}
+
+	for _, reply := range []string{"", "VERSION 1.19.1", "GET_STATUS", "GET_STATUS STOPPED game_boy,Pokemon Red"} {
+		if got, err := parseRetroArchStatus(reply); err == nil {
+			t.Errorf("%q parsed as %+v, want an error", reply, got)
+		}
+	}
+}
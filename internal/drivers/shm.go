@@ -0,0 +1,11 @@
+package drivers
+
+import "io"
+
+// sharedFile is a random-access view of a shared memory file
+type sharedFile interface {
+	io.ReaderAt
+	io.WriterAt
+	Size() int64
+	Close() error
+}
@@ -0,0 +1,39 @@
+//go:build !unix
+
+package drivers
+
+import (
+	"fmt"
+	"os"
+)
+
+// osSharedFile is a sharedFile backed by positional file I/O
+type osSharedFile struct {
+	*os.File
+	size int64
+}
+
+// openSharedFile opens the file at path for positional reads and writes
+func openSharedFile(path string) (sharedFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() == 0 {
+		f.Close()
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+
+	return &osSharedFile{File: f, size: info.Size()}, nil
+}
+
+// Size returns the file length at open time
+func (f *osSharedFile) Size() int64 {
+	return f.size
+}
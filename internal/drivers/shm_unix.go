@@ -0,0 +1,70 @@
+//go:build unix
+
+package drivers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+)
+
+// mappedFile is a sharedFile backed by mmap
+type mappedFile struct {
+	data []byte
+}
+
+// openSharedFile maps the file at path read/write into this process
+func openSharedFile(path string) (sharedFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mmap %s: %w", path, err)
+	}
+
+	return &mappedFile{data: data}, nil
+}
+
+// ReadAt copies mapped bytes at off into p
+func (m *mappedFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(m.data)) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return copy(p, m.data[off:]), nil
+}
+
+// WriteAt copies p into the mapping at off
+func (m *mappedFile) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(m.data)) {
+		return 0, io.ErrShortWrite
+	}
+	return copy(m.data[off:], p), nil
+}
+
+// Size returns the mapping length
+func (m *mappedFile) Size() int64 {
+	return int64(len(m.data))
+}
+
+// Close unmaps the file
+func (m *mappedFile) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	err := syscall.Munmap(m.data)
+	m.data = nil
+	return err
+}
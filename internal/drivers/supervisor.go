@@ -0,0 +1,246 @@
+package drivers
+
+import (
+	"fmt"
+	"gamehook/internal/types"
+	"sync"
+	"time"
+)
+
+// ConnectionState is the supervised state of a driver connection
+type ConnectionState string
+
+const (
+	StateDisconnected ConnectionState = "disconnected"
+	StateConnecting   ConnectionState = "connecting"
+	StateConnected    ConnectionState = "connected"
+	StateDegraded     ConnectionState = "degraded" // Connected, but recent reads failed
+)
+
+// maxBackoff caps the exponential reconnect delay
+const maxBackoff = 30 * time.Second
+
+// DriverStatus is a snapshot of the supervised connection
+type DriverStatus struct {
+	Driver            string          `json:"driver"`
+	State             ConnectionState `json:"state"`
+	PreviousState     ConnectionState `json:"previous_state,omitempty"`
+	Since             time.Time       `json:"since"`
+	ConsecutiveErrors int             `json:"consecutive_errors"`
+	ReconnectAttempts int             `json:"reconnect_attempts"`
+	LastError         string          `json:"last_error,omitempty"`
+	LastSuccess       *time.Time      `json:"last_success,omitempty"`
+	NextRetry         *time.Time      `json:"next_retry,omitempty"`
+
+	// Stats holds driver performance counters when the driver reports them
+	Stats map[string]interface{} `json:"stats,omitempty"`
+}
+
+// Supervisor tracks a driver's connection state and reconnects with exponential backoff.
+//
+// A reconnected driver stays connecting until its first successful read.
+// Read failures move a connected driver to degraded; after maxRetries
+// consecutive failures it is closed and marked disconnected. Reconnects are
+// attempted on later reads once the backoff delay (retryDelay doubled per
+// failed attempt, capped at 30s) has passed.
+type Supervisor struct {
+	driver     Driver
+	name       string
+	maxRetries int
+	retryDelay time.Duration
+
+	mu        sync.Mutex
+	status    DriverStatus
+	nextRetry time.Time
+	listeners []func(DriverStatus)
+	pending   []DriverStatus // Transitions not yet delivered to listeners
+
+	// stats is the driver's counters as of the last read. Reading them from
+	// Status would wait for a tick in progress on drivers that lock per tick.
+	stats map[string]interface{}
+}
+
+// NewSupervisor creates a supervisor for driver
+func NewSupervisor(driver Driver, name string, maxRetries int, retryDelay time.Duration) *Supervisor {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if retryDelay <= 0 {
+		retryDelay = 100 * time.Millisecond
+	}
+	return &Supervisor{
+		driver:     driver,
+		name:       name,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+		status: DriverStatus{
+			Driver: name,
+			State:  StateDisconnected,
+			Since:  time.Now(),
+		},
+	}
+}
+
+// OnStatusChange registers a callback invoked after every state transition
+func (s *Supervisor) OnStatusChange(listener func(DriverStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// Status returns the current connection status
+func (s *Supervisor) Status() DriverStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.snapshotLocked()
+	status.Stats = s.stats
+	return status
+}
+
+// EnsureConnected reconnects a disconnected driver once its backoff has expired
+func (s *Supervisor) EnsureConnected() error {
+	s.mu.Lock()
+	state := s.status.State
+	if state == StateDisconnected && time.Now().Before(s.nextRetry) {
+		wait := time.Until(s.nextRetry)
+		s.mu.Unlock()
+		return fmt.Errorf("driver disconnected, retrying in %v", wait.Round(time.Millisecond))
+	}
+	s.mu.Unlock()
+
+	if state == StateDisconnected {
+		return s.connect()
+	}
+	return nil
+}
+
+// ReadMemoryBlocks reads through the driver, connecting and backing off as needed
+func (s *Supervisor) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	if err := s.EnsureConnected(); err != nil {
+		return nil, err
+	}
+
+	data, err := s.driver.ReadMemoryBlocks(blocks)
+
+	// The driver is idle between reads, so its counters are taken now
+	var stats map[string]interface{}
+	if reporter, ok := s.driver.(StatsReporter); ok {
+		stats = reporter.Stats()
+	}
+
+	s.mu.Lock()
+	s.stats = stats
+	if err != nil {
+		s.status.ConsecutiveErrors++
+		s.status.LastError = err.Error()
+		switch {
+		case s.status.ConsecutiveErrors > s.maxRetries:
+			s.driver.Close()
+			s.scheduleRetryLocked()
+			s.transitionLocked(StateDisconnected)
+		case s.status.State != StateConnecting:
+			s.transitionLocked(StateDegraded)
+		}
+	} else {
+		now := time.Now()
+		s.status.ConsecutiveErrors = 0
+		s.status.ReconnectAttempts = 0
+		s.status.LastSuccess = &now
+		s.transitionLocked(StateConnected)
+	}
+	s.mu.Unlock()
+
+	s.notify()
+	return data, err
+}
+
+// connect attempts a reconnect, scheduling the next attempt on failure
+func (s *Supervisor) connect() error {
+	s.mu.Lock()
+	s.transitionLocked(StateConnecting)
+	s.mu.Unlock()
+	s.notify()
+
+	err := s.driver.Connect()
+
+	s.mu.Lock()
+	if err != nil {
+		s.status.LastError = err.Error()
+		s.scheduleRetryLocked()
+		s.transitionLocked(StateDisconnected)
+	} else {
+		// Stay connecting: the first successful read confirms the connection
+		s.status.ConsecutiveErrors = 0
+	}
+	s.mu.Unlock()
+	s.notify()
+
+	if err != nil {
+		return fmt.Errorf("reconnect failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the driver and marks it disconnected
+func (s *Supervisor) Close() error {
+	err := s.driver.Close()
+
+	s.mu.Lock()
+	s.nextRetry = time.Time{}
+	s.transitionLocked(StateDisconnected)
+	s.mu.Unlock()
+	s.notify()
+
+	return err
+}
+
+// scheduleRetryLocked sets the next reconnect time using exponential backoff
+func (s *Supervisor) scheduleRetryLocked() {
+	delay := s.retryDelay
+	for i := 0; i < s.status.ReconnectAttempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	s.status.ReconnectAttempts++
+	s.nextRetry = time.Now().Add(delay)
+}
+
+// transitionLocked changes state, queueing a notification if it actually changed
+func (s *Supervisor) transitionLocked(state ConnectionState) {
+	if s.status.State == state {
+		return
+	}
+	s.status.PreviousState = s.status.State
+	s.status.State = state
+	s.status.Since = time.Now()
+	s.pending = append(s.pending, s.snapshotLocked())
+}
+
+// snapshotLocked copies the status for callers outside the lock
+func (s *Supervisor) snapshotLocked() DriverStatus {
+	status := s.status
+	if status.State == StateDisconnected && !s.nextRetry.IsZero() {
+		next := s.nextRetry
+		status.NextRetry = &next
+	}
+	return status
+}
+
+// notify delivers queued transitions to listeners outside the lock
+func (s *Supervisor) notify() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, status := range pending {
+		for _, listener := range listeners {
+			listener(status)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package drivers
+
+import (
+	"fmt"
+	"gamehook/internal/types"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedDriver fails connects and reads while told to, and reports how often it was used
+type scriptedDriver struct {
+	mu         sync.Mutex
+	connectErr error
+	readErr    error
+	connects   int
+	closes     int
+	reads      int
+	block      chan struct{} // Holds reads, with mu locked, until closed
+}
+
+func (d *scriptedDriver) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.connects++
+	return d.connectErr
+}
+
+func (d *scriptedDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.block != nil {
+		<-d.block
+	}
+	d.reads++
+	if d.readErr != nil {
+		return nil, d.readErr
+	}
+	return map[uint32][]byte{0: {1}}, nil
+}
+
+func (d *scriptedDriver) WriteBytes(address uint32, data []byte) error { return nil }
+
+func (d *scriptedDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closes++
+	return nil
+}
+
+func (d *scriptedDriver) Stats() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return map[string]interface{}{"reads": d.reads}
+}
+
+func (d *scriptedDriver) set(connectErr, readErr error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.connectErr, d.readErr = connectErr, readErr
+}
+
+func TestSupervisorStateMachine(t *testing.T) {
+	driver := &scriptedDriver{}
+	supervisor := NewSupervisor(driver, "scripted", 2, 20*time.Millisecond)
+
+	var transitions []string
+	supervisor.OnStatusChange(func(status DriverStatus) {
+		transitions = append(transitions, string(status.State))
+	})
+
+	read := func(wantState ConnectionState) error {
+		t.Helper()
+		_, err := supervisor.ReadMemoryBlocks(nil)
+		if state := supervisor.Status().State; state != wantState {
+			t.Fatalf("state %s after a read returning %v, want %s", state, err, wantState)
+		}
+		return err
+	}
+
+	// Connecting lasts until a read succeeds; failures before that don't degrade
+	driver.set(nil, fmt.Errorf("no content"))
+	read(StateConnecting)
+	read(StateConnecting)
+	if err := read(StateDisconnected); err == nil {
+		t.Fatal("third failed read succeeded")
+	}
+	if driver.closes != 1 {
+		t.Errorf("driver closed %d times, want once", driver.closes)
+	}
+
+	// No reconnect before the backoff has passed
+	if err := read(StateDisconnected); err == nil || !strings.Contains(err.Error(), "retrying in") {
+		t.Errorf("read during backoff returned %v", err)
+	}
+	if driver.connects != 1 {
+		t.Errorf("%d connects during backoff, want 1", driver.connects)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	driver.set(nil, nil)
+	read(StateConnected)
+
+	// Connected drivers degrade on a failed read and recover on the next good one
+	driver.set(nil, fmt.Errorf("timeout"))
+	read(StateDegraded)
+	driver.set(nil, nil)
+	read(StateConnected)
+
+	want := "connecting disconnected connecting connected degraded connected"
+	if got := strings.Join(transitions, " "); got != want {
+		t.Errorf("transitions %q, want %q", got, want)
+	}
+	if status := supervisor.Status(); status.ConsecutiveErrors != 0 || status.LastSuccess == nil || status.Stats["reads"] != driver.reads {
+		t.Errorf("status after recovering is %+v", status)
+	}
+}
+
+func TestSupervisorBacksOffFailedConnects(t *testing.T) {
+	driver := &scriptedDriver{}
+	supervisor := NewSupervisor(driver, "scripted", 0, 20*time.Millisecond)
+
+	driver.set(fmt.Errorf("refused"), nil)
+	for attempt := 1; attempt <= 3; attempt++ {
+		if _, err := supervisor.ReadMemoryBlocks(nil); err == nil || !strings.Contains(err.Error(), "reconnect failed") {
+			t.Fatalf("attempt %d returned %v", attempt, err)
+		}
+
+		status := supervisor.Status()
+		if status.State != StateDisconnected || status.ReconnectAttempts != attempt || status.NextRetry == nil {
+			t.Fatalf("status after attempt %d is %+v", attempt, status)
+		}
+		// The delay doubles per failed attempt
+		delay := 20 * time.Millisecond << (attempt - 1)
+		if wait := time.Until(*status.NextRetry); wait > delay || wait < delay/2 {
+			t.Errorf("retry %d in %v, want %v", attempt, wait, delay)
+		}
+		time.Sleep(delay)
+	}
+
+	// Closing clears the backoff, so the next read reconnects at once
+	supervisor.Close()
+	driver.set(nil, nil)
+	if _, err := supervisor.ReadMemoryBlocks(nil); err != nil {
+		t.Fatal(err)
+	}
+	if status := supervisor.Status(); status.State != StateConnected || status.ReconnectAttempts != 0 {
+		t.Errorf("status after reconnecting is %+v", status)
+	}
+}
+
+func TestSupervisorStatusDoesNotWaitForReads(t *testing.T) {
+	driver := &scriptedDriver{block: make(chan struct{})}
+	supervisor := NewSupervisor(driver, "scripted", 3, time.Second)
+
+	done := make(chan struct{})
+	go func() {
+		supervisor.ReadMemoryBlocks(nil)
+		close(done)
+	}()
+
+	// The read holds the driver's lock; Status must not need it
+	time.Sleep(10 * time.Millisecond)
+	status := make(chan DriverStatus)
+	go func() { status <- supervisor.Status() }()
+	select {
+	case s := <-status:
+		if s.State != StateConnecting {
+			t.Errorf("state %s during the first read, want connecting", s.State)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Status waited for the read in progress")
+	}
+
+	close(driver.block)
+	<-done
+	if s := supervisor.Status(); s.Stats["reads"] != 1 {
+		t.Errorf("stats %v after the read, want 1 read", s.Stats)
+	}
+}
@@ -0,0 +1,403 @@
+package drivers
+
+import (
+	"fmt"
+	"gamehook/internal/types"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Usb2snesMapping translates a range of SNES bus addresses into usb2snes address space
+type Usb2snesMapping struct {
+	Start  uint32 // First SNES bus address (inclusive)
+	End    uint32 // Last SNES bus address (inclusive)
+	Target uint32 // usb2snes address that Start maps to
+}
+
+// DefaultUsb2snesMappings covers WRAM, its low-RAM mirror at 0x000000-0x001FFF
+// and LoROM save RAM. Addresses outside these ranges are passed through
+// unchanged, so they must already be usb2snes addresses.
+var DefaultUsb2snesMappings = []Usb2snesMapping{
+	{Start: 0x7E0000, End: 0x7FFFFF, Target: 0xF50000}, // WRAM
+	{Start: 0x000000, End: 0x001FFF, Target: 0xF50000}, // Low RAM mirror in bank $00
+	{Start: 0x700000, End: 0x707FFF, Target: 0xE00000}, // LoROM save RAM
+}
+
+// Usb2snesDomains places named memory domains in usb2snes address space
+var Usb2snesDomains = map[string]uint32{
+	"rom":   0x000000,
+	"sram":  0xE00000,
+	"wram":  0xF50000,
+	"vram":  0xF70000,
+	"apu":   0xF80000,
+	"cgram": 0xF90000,
+	"oam":   0xF90200,
+}
+
+// usb2snesRequest is the JSON command frame understood by (Q)Usb2snes
+type usb2snesRequest struct {
+	Opcode   string   `json:"Opcode"`
+	Space    string   `json:"Space"`
+	Operands []string `json:"Operands,omitempty"`
+}
+
+// usb2snesReply is the JSON reply frame for commands that return results
+type usb2snesReply struct {
+	Results []string `json:"Results"`
+}
+
+// Usb2snesDriver talks to SD2SNES/FXPak hardware or bridged emulators through a (Q)Usb2snes server
+type Usb2snesDriver struct {
+	url            string
+	device         string
+	requestTimeout time.Duration
+	mappings       []Usb2snesMapping
+	maxReadSize    uint32
+	maxWriteSize   uint32
+
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	attached string
+}
+
+// NewUsb2snesDriver creates a new usb2snes driver. An empty device attaches to the first one listed.
+func NewUsb2snesDriver(url string, device string, requestTimeout time.Duration, mappings []Usb2snesMapping) *Usb2snesDriver {
+	if len(mappings) == 0 {
+		mappings = DefaultUsb2snesMappings
+	}
+	return &Usb2snesDriver{
+		url:            url,
+		device:         device,
+		requestTimeout: requestTimeout,
+		mappings:       mappings,
+		maxReadSize:    0x8000,
+		maxWriteSize:   1024, // Older firmware rejects larger PutAddress payloads
+	}
+}
+
+// Connect opens the WebSocket, selects a device and attaches to it
+func (d *Usb2snesDriver) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closeLocked()
+
+	dialer := websocket.Dialer{HandshakeTimeout: d.requestTimeout * 10}
+	conn, _, err := dialer.Dial(d.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to usb2snes server: %w", err)
+	}
+	d.conn = conn
+
+	devices, err := d.queryLocked(usb2snesRequest{Opcode: "DeviceList", Space: "SNES"})
+	if err != nil {
+		d.closeLocked()
+		return fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	device := ""
+	for _, candidate := range devices {
+		if d.device == "" || strings.EqualFold(candidate, d.device) {
+			device = candidate
+			break
+		}
+	}
+	if device == "" {
+		d.closeLocked()
+		if d.device != "" {
+			return fmt.Errorf("device %q not found (available: %v)", d.device, devices)
+		}
+		return fmt.Errorf("no usb2snes devices available")
+	}
+
+	// Attach and Name have no reply
+	if err := d.sendLocked(usb2snesRequest{Opcode: "Attach", Space: "SNES", Operands: []string{device}}); err != nil {
+		d.closeLocked()
+		return err
+	}
+	if err := d.sendLocked(usb2snesRequest{Opcode: "Name", Space: "SNES", Operands: []string{"GameHook"}}); err != nil {
+		d.closeLocked()
+		return err
+	}
+
+	// Info only succeeds once the attach went through
+	info, err := d.queryLocked(usb2snesRequest{Opcode: "Info", Space: "SNES"})
+	if err != nil {
+		d.closeLocked()
+		return fmt.Errorf("failed to attach to %s: %w", device, err)
+	}
+
+	d.attached = device
+	firmware := ""
+	if len(info) > 0 {
+		firmware = info[0]
+	}
+	fmt.Printf("🔌 Attached to usb2snes device %s (%s)\n", device, firmware)
+	return nil
+}
+
+// ReadMemoryBlocks reads multiple memory blocks with GetAddress
+func (d *Usb2snesDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	d.mu.Lock()
+	connected := d.conn != nil
+	d.mu.Unlock()
+
+	if !connected {
+		if err := d.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := make(map[uint32][]byte)
+
+	for _, block := range blocks {
+		var segments [][2]uint32
+		if block.Domain == "" {
+			segments = d.translate(block.Start, block.End-block.Start+1, d.maxReadSize)
+		} else {
+			base, ok := Usb2snesDomains[block.Domain]
+			if !ok {
+				return nil, fmt.Errorf("unknown usb2snes memory domain %q", block.Domain)
+			}
+			segments = splitSegments(base+block.Start, block.End-block.Start+1, d.maxReadSize)
+		}
+
+		data := make([]byte, 0, block.End-block.Start+1)
+		for _, seg := range segments {
+			chunk, err := d.getAddressLocked(seg[0], seg[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read block %s: %w", block.Name, err)
+			}
+			data = append(data, chunk...)
+		}
+		result[block.Start] = data
+	}
+
+	return result, nil
+}
+
+// WriteBytes writes data with PutAddress
+func (d *Usb2snesDriver) WriteBytes(address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return fmt.Errorf("not connected to usb2snes server")
+	}
+
+	return d.putSegmentsLocked(d.translate(address, uint32(len(data)), d.maxWriteSize), data)
+}
+
+// HasDomain reports whether domain is a known usb2snes memory space
+func (d *Usb2snesDriver) HasDomain(domain string) bool {
+	_, ok := Usb2snesDomains[domain]
+	return ok
+}
+
+// WriteDomainBytes writes data at an offset inside a usb2snes memory domain
+func (d *Usb2snesDriver) WriteDomainBytes(domain string, address uint32, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return fmt.Errorf("not connected to usb2snes server")
+	}
+
+	base, ok := Usb2snesDomains[domain]
+	if !ok {
+		return fmt.Errorf("unknown usb2snes memory domain %q", domain)
+	}
+
+	return d.putSegmentsLocked(splitSegments(base+address, uint32(len(data)), d.maxWriteSize), data)
+}
+
+// putSegmentsLocked writes consecutive parts of data to each {address, length} segment
+func (d *Usb2snesDriver) putSegmentsLocked(segments [][2]uint32, data []byte) error {
+	offset := uint32(0)
+	for _, seg := range segments {
+		if err := d.putAddressLocked(seg[0], data[offset:offset+seg[1]]); err != nil {
+			return fmt.Errorf("failed to write at 0x%X: %w", seg[0], err)
+		}
+		offset += seg[1]
+	}
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (d *Usb2snesDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closeLocked()
+}
+
+// closeLocked closes the WebSocket connection
+func (d *Usb2snesDriver) closeLocked() error {
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	d.attached = ""
+	return err
+}
+
+// TranslateAddress converts a SNES bus address into usb2snes address space
+func (d *Usb2snesDriver) TranslateAddress(address uint32) uint32 {
+	if mapping, ok := d.findMapping(address); ok {
+		return mapping.Target + (address - mapping.Start)
+	}
+	return address
+}
+
+// translate splits a bus range into {usb2snes address, length} segments that
+// stay inside one mapping and do not exceed maxSize
+func (d *Usb2snesDriver) translate(address uint32, length uint32, maxSize uint32) [][2]uint32 {
+	var segments [][2]uint32
+	remaining := length
+	current := address
+
+	for remaining > 0 {
+		n := remaining
+		if mapping, ok := d.findMapping(current); ok {
+			if left := mapping.End - current + 1; left != 0 && left < n {
+				n = left
+			}
+		} else if next, ok := d.nextMapping(current); ok && next-current < n {
+			n = next - current
+		}
+		if n > maxSize {
+			n = maxSize
+		}
+
+		segments = append(segments, [2]uint32{d.TranslateAddress(current), n})
+		current += n
+		remaining -= n
+	}
+
+	return segments
+}
+
+// splitSegments divides a usb2snes range into {address, length} segments of at most maxSize
+func splitSegments(address uint32, length uint32, maxSize uint32) [][2]uint32 {
+	var segments [][2]uint32
+	for length > 0 {
+		n := length
+		if n > maxSize {
+			n = maxSize
+		}
+		segments = append(segments, [2]uint32{address, n})
+		address += n
+		length -= n
+	}
+	return segments
+}
+
+// findMapping returns the mapping containing address
+func (d *Usb2snesDriver) findMapping(address uint32) (Usb2snesMapping, bool) {
+	for _, mapping := range d.mappings {
+		if address >= mapping.Start && address <= mapping.End {
+			return mapping, true
+		}
+	}
+	return Usb2snesMapping{}, false
+}
+
+// nextMapping returns the start of the first mapping above address
+func (d *Usb2snesDriver) nextMapping(address uint32) (uint32, bool) {
+	found := false
+	next := uint32(0)
+	for _, mapping := range d.mappings {
+		if mapping.Start > address && (!found || mapping.Start < next) {
+			next = mapping.Start
+			found = true
+		}
+	}
+	return next, found
+}
+
+// getAddressLocked reads length bytes at a usb2snes address
+func (d *Usb2snesDriver) getAddressLocked(address uint32, length uint32) ([]byte, error) {
+	request := usb2snesRequest{
+		Opcode:   "GetAddress",
+		Space:    "SNES",
+		Operands: []string{fmt.Sprintf("%X", address), fmt.Sprintf("%X", length)},
+	}
+	if err := d.sendLocked(request); err != nil {
+		return nil, err
+	}
+
+	// The reply may be split across several binary frames
+	data := make([]byte, 0, length)
+	for uint32(len(data)) < length {
+		messageType, payload, err := d.conn.ReadMessage()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read memory reply: %w", err)
+		}
+		if messageType != websocket.BinaryMessage {
+			return nil, fmt.Errorf("unexpected text reply to GetAddress: %s", payload)
+		}
+		data = append(data, payload...)
+	}
+
+	if uint32(len(data)) != length {
+		return nil, fmt.Errorf("expected %d bytes, got %d", length, len(data))
+	}
+
+	return data, nil
+}
+
+// putAddressLocked writes data at a usb2snes address
+func (d *Usb2snesDriver) putAddressLocked(address uint32, data []byte) error {
+	request := usb2snesRequest{
+		Opcode:   "PutAddress",
+		Space:    "SNES",
+		Operands: []string{fmt.Sprintf("%X", address), fmt.Sprintf("%X", len(data))},
+	}
+	if err := d.sendLocked(request); err != nil {
+		return err
+	}
+
+	if err := d.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		return fmt.Errorf("failed to send write payload: %w", err)
+	}
+
+	return nil
+}
+
+// queryLocked sends a command and waits for its JSON Results reply
+func (d *Usb2snesDriver) queryLocked(request usb2snesRequest) ([]string, error) {
+	if err := d.sendLocked(request); err != nil {
+		return nil, err
+	}
+
+	var reply usb2snesReply
+	if err := d.conn.ReadJSON(&reply); err != nil {
+		return nil, fmt.Errorf("failed to read %s reply: %w", request.Opcode, err)
+	}
+
+	return reply.Results, nil
+}
+
+// sendLocked sends a JSON command and arms the request deadline
+func (d *Usb2snesDriver) sendLocked(request usb2snesRequest) error {
+	deadline := time.Now().Add(d.requestTimeout)
+	if err := d.conn.SetWriteDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	if err := d.conn.SetReadDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	if err := d.conn.WriteJSON(request); err != nil {
+		return fmt.Errorf("failed to send %s: %w", request.Opcode, err)
+	}
+
+	return nil
+}
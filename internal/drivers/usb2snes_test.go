@@ -0,0 +1,242 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/json"
+	"gamehook/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeUsb2snes is a minimal (Q)Usb2snes server over a sparse usb2snes address space
+type fakeUsb2snes struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu       sync.Mutex
+	written  map[uint32]byte
+	attached string
+	requests []usb2snesRequest
+}
+
+func newFakeUsb2snes(t *testing.T) *fakeUsb2snes {
+	fake := &fakeUsb2snes{t: t, written: make(map[uint32]byte)}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeUsb2snes) driver(device string) *Usb2snesDriver {
+	url := "ws" + strings.TrimPrefix(f.server.URL, "http")
+	return NewUsb2snesDriver(url, device, time.Second, nil)
+}
+
+// byteAt returns the byte at a usb2snes address
+func (f *fakeUsb2snes) byteAt(address uint32) byte {
+	if b, ok := f.written[address]; ok {
+		return b
+	}
+	return byte(address*7 ^ address>>8)
+}
+
+func (f *fakeUsb2snes) handle(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		f.t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var request usb2snesRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			f.t.Errorf("invalid command frame %q: %v", message, err)
+			return
+		}
+
+		f.mu.Lock()
+		f.requests = append(f.requests, request)
+		f.mu.Unlock()
+
+		switch request.Opcode {
+		case "DeviceList":
+			conn.WriteJSON(usb2snesReply{Results: []string{"SD2SNES COM3", "EmuNWAccess"}})
+		case "Attach":
+			f.mu.Lock()
+			f.attached = request.Operands[0]
+			f.mu.Unlock()
+		case "Name":
+		case "Info":
+			conn.WriteJSON(usb2snesReply{Results: []string{"1.11.0", "USB2SNES SNES", "/sd2snes/m3nu.bin"}})
+		case "GetAddress":
+			address, _ := strconv.ParseUint(request.Operands[0], 16, 32)
+			length, _ := strconv.ParseUint(request.Operands[1], 16, 32)
+
+			f.mu.Lock()
+			data := make([]byte, length)
+			for i := range data {
+				data[i] = f.byteAt(uint32(address) + uint32(i))
+			}
+			f.mu.Unlock()
+
+			// Like the hardware, replies arrive in frames of at most 1024 bytes
+			for len(data) > 0 {
+				n := min(len(data), 1024)
+				conn.WriteMessage(websocket.BinaryMessage, data[:n])
+				data = data[n:]
+			}
+		case "PutAddress":
+			address, _ := strconv.ParseUint(request.Operands[0], 16, 32)
+			length, _ := strconv.ParseUint(request.Operands[1], 16, 32)
+
+			messageType, payload, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType != websocket.BinaryMessage || len(payload) != int(length) {
+				f.t.Errorf("PutAddress payload is %d bytes of type %d, want %d binary", len(payload), messageType, length)
+				return
+			}
+
+			f.mu.Lock()
+			for i, b := range payload {
+				f.written[uint32(address)+uint32(i)] = b
+			}
+			f.mu.Unlock()
+		default:
+			f.t.Errorf("unexpected opcode %s", request.Opcode)
+		}
+	}
+}
+
+// operands returns the operands of every request with opcode
+func (f *fakeUsb2snes) operands(opcode string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var operands []string
+	for _, request := range f.requests {
+		if request.Opcode == opcode {
+			operands = append(operands, strings.Join(request.Operands, " "))
+		}
+	}
+	return operands
+}
+
+func TestUsb2snesDriverAttachesToDevice(t *testing.T) {
+	fake := newFakeUsb2snes(t)
+
+	driver := fake.driver("emunwaccess")
+	defer driver.Close()
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if driver.attached != "EmuNWAccess" || fake.attached != "EmuNWAccess" {
+		t.Errorf("attached to %q (server saw %q), want EmuNWAccess", driver.attached, fake.attached)
+	}
+
+	missing := fake.driver("retroarch")
+	if err := missing.Connect(); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("attaching to a missing device returned %v", err)
+	}
+
+	// With no device configured the first one listed is used
+	first := fake.driver("")
+	defer first.Close()
+	if err := first.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if first.attached != "SD2SNES COM3" {
+		t.Errorf("attached to %q, want the first device", first.attached)
+	}
+}
+
+func TestUsb2snesDriverReadsAcrossFrames(t *testing.T) {
+	fake := newFakeUsb2snes(t)
+	driver := fake.driver("")
+	defer driver.Close()
+
+	data, err := driver.ReadMemoryBlocks([]types.MemoryBlock{
+		{Name: "wram", Start: 0x7E0000, End: 0x7E0FFF},
+		{Name: "mirror", Start: 0x001FF0, End: 0x00200F}, // Crosses the end of the low RAM mirror
+		{Name: "sram", Domain: "sram", Start: 0x0000, End: 0x00FF},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(name string, got []byte, segments ...[2]uint32) {
+		t.Helper()
+		var want []byte
+		for _, seg := range segments {
+			for i := uint32(0); i < seg[1]; i++ {
+				want = append(want, fake.byteAt(seg[0]+i))
+			}
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s read %d bytes that differ from the fake's", name, len(got))
+		}
+	}
+	expect("wram", data[0x7E0000], [2]uint32{0xF50000, 0x1000})
+	expect("mirror", data[0x001FF0], [2]uint32{0xF51FF0, 0x10}, [2]uint32{0x002000, 0x10})
+	expect("sram", data[0x0000], [2]uint32{0xE00000, 0x100})
+
+	want := []string{"F50000 1000", "F51FF0 10", "2000 10", "E00000 100"}
+	if got := fake.operands("GetAddress"); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("GetAddress operands %v, want %v", got, want)
+	}
+}
+
+func TestUsb2snesDriverWrites(t *testing.T) {
+	fake := newFakeUsb2snes(t)
+	driver := fake.driver("")
+	defer driver.Close()
+
+	if err := driver.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := make([]byte, 1500)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	if err := driver.WriteBytes(0x7E0100, payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := driver.WriteDomainBytes("sram", 0x10, []byte{0xAA, 0xBB}); err != nil {
+		t.Fatal(err)
+	}
+
+	// PutAddress has no reply, so the read back also orders the writes before the checks below
+	data, err := driver.ReadMemoryBlocks([]types.MemoryBlock{{Name: "wram", Start: 0x7E0100, End: 0x7E06DB}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[0x7E0100], payload) {
+		t.Error("written bytes not read back")
+	}
+
+	// Writes are split at the firmware's 1024-byte PutAddress limit
+	want := []string{"F50100 400", "F50500 1DC", "E00010 2"}
+	if got := fake.operands("PutAddress"); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("PutAddress operands %v, want %v", got, want)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.written[0xE00010] != 0xAA || fake.written[0xE00011] != 0xBB {
+		t.Error("domain write did not reach save RAM")
+	}
+}
@@ -0,0 +1,161 @@
+package mappers
+
+import (
+	"fmt"
+	"gamehook/internal/memory"
+	"time"
+)
+
+// ===== BANK-SWITCHED PROPERTIES =====
+
+// BankStatus reports whether a banked property's bank is mapped in
+type BankStatus struct {
+	Bank       uint32     `json:"bank"`
+	ActiveBank *uint32    `json:"active_bank,omitempty"` // nil for domain-banked properties or before the register is read
+	Stale      bool       `json:"stale"`                 // The value is the last one seen while Bank was mapped in
+	CachedAt   *time.Time `json:"cached_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// resolveBanking places each banked property in its bank-switched window.
+//
+// Properties inside a register-selected block (or declaring their own
+// bankRegister) are read through the memory manager's per-bank caches.
+// Properties inside a domain-banked block are rewritten to the bank's
+// address in that domain and read like any other domain property.
+func (m *Mapper) resolveBanking() error {
+	var propertyWindows []memory.BankedWindow
+
+	for name, prop := range m.Properties {
+		if prop.Bank == nil {
+			continue
+		}
+
+		if caps := m.Platform.Capabilities; caps != nil && caps.SupportsBanking != nil && !*caps.SupportsBanking {
+			return fmt.Errorf("property %s declares bank %d but platform %s does not support banking",
+				name, *prop.Bank, m.Platform.Name)
+		}
+
+		extent := propertyExtent(prop)
+		if extent == 0 {
+			extent = 1
+		}
+		end := prop.Address + extent - 1
+
+		if prop.BankSelect != nil {
+			propertyWindows = append(propertyWindows, memory.BankedWindow{
+				Domain:   prop.Domain,
+				Start:    prop.Address,
+				End:      end,
+				Register: *prop.BankSelect,
+			})
+			continue
+		}
+
+		block, ok := m.bankedBlockFor(prop.Domain, prop.Address, end)
+		if !ok {
+			return fmt.Errorf("property %s declares bank %d but is not inside a banked memory block and has no bankRegister",
+				name, *prop.Bank)
+		}
+
+		if block.Banking.Register != nil {
+			register := *block.Banking.Register
+			prop.BankSelect = &register
+			continue
+		}
+
+		// Every bank is stored back to back in the banking domain
+		prop.Domain = block.Banking.Domain
+		prop.Address = *prop.Bank*block.Banking.BankSize + (prop.Address - block.Block.Start)
+	}
+
+	// Property windows come first so their own registers take precedence
+	m.BankedWindows = propertyWindows
+	for _, block := range m.Platform.BankedBlocks {
+		if block.Banking.Register == nil {
+			continue
+		}
+		m.BankedWindows = append(m.BankedWindows, memory.BankedWindow{
+			Domain:   block.Block.Domain,
+			Start:    block.Block.Start,
+			End:      block.Block.End,
+			Register: *block.Banking.Register,
+		})
+	}
+
+	return nil
+}
+
+// bankedBlockFor returns the banked block containing the whole range
+func (m *Mapper) bankedBlockFor(domain string, start uint32, end uint32) (BankedBlock, bool) {
+	for _, block := range m.Platform.BankedBlocks {
+		if block.Block.Domain == domain && start >= block.Block.Start && end <= block.Block.End {
+			return block, true
+		}
+	}
+	return BankedBlock{}, false
+}
+
+// BankStatus reports whether a banked property's bank is mapped in. It returns
+// nil for properties without a bank.
+func (m *Mapper) BankStatus(name string, memManager *memory.Manager) *BankStatus {
+	prop, exists := m.Properties[name]
+	if !exists || prop.Bank == nil {
+		return nil
+	}
+
+	status := &BankStatus{Bank: *prop.Bank}
+	if prop.BankSelect == nil {
+		return status
+	}
+
+	read, err := memManager.ReadBankedBytes(prop.Domain, prop.Address, prop.Length, *prop.Bank)
+	if read != nil {
+		active := read.ActiveBank
+		status.ActiveBank = &active
+		status.Stale = read.Stale
+		if !read.CachedAt.IsZero() {
+			cachedAt := read.CachedAt
+			status.CachedAt = &cachedAt
+		}
+	}
+	if err != nil {
+		status.Stale = true
+		status.Error = err.Error()
+	}
+
+	return status
+}
+
+// CheckBankMapped returns an error when a register-selected property's bank is
+// not mapped in, since writing it would modify whichever bank is
+func (m *Mapper) CheckBankMapped(prop *Property, memManager *memory.Manager) error {
+	return m.checkBankMappedAt(prop, memManager.Snapshot())
+}
+
+// checkBankMappedAt is CheckBankMapped against a specific snapshot
+func (m *Mapper) checkBankMappedAt(prop *Property, snap *memory.Snapshot) error {
+	if prop.Bank == nil || prop.BankSelect == nil {
+		return nil
+	}
+
+	read, err := snap.ReadBankedBytes(prop.Domain, prop.Address, prop.Length, *prop.Bank)
+	if read != nil && read.Stale {
+		return fmt.Errorf("property %s is in bank %d but bank %d is mapped in", prop.Name, *prop.Bank, read.ActiveBank)
+	}
+	return err
+}
+
+// readPropertyBytes reads a property's raw bytes from a snapshot, going
+// through the bank caches for register-selected banked properties
+func (m *Mapper) readPropertyBytes(prop *Property, snap *memory.Snapshot) ([]byte, error) {
+	if prop.Bank == nil || prop.BankSelect == nil {
+		return snap.ReadDomainBytes(prop.Domain, prop.Address, prop.Length)
+	}
+
+	read, err := snap.ReadBankedBytes(prop.Domain, prop.Address, prop.Length, *prop.Bank)
+	if err != nil {
+		return nil, err
+	}
+	return read.Data, nil
+}
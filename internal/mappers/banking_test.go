@@ -0,0 +1,51 @@
+package mappers
+
+import (
+	"gamehook/internal/memory"
+	"testing"
+)
+
+func TestBankStatusMarksStaleValues(t *testing.T) {
+	register := memory.BankRegister{Address: 0xFF70, Mask: 0x07, ZeroSelects: 1}
+	mapper := &Mapper{
+		Platform: Platform{Endian: "little"},
+		Properties: map[string]*Property{
+			"bank2Counter": {Name: "bank2Counter", Type: PropertyTypeUint8, Address: 0xD000, Length: 1,
+				Bank: uint32Ptr(2), BankSelect: &register, Freezable: true},
+			"hp": {Name: "hp", Type: PropertyTypeUint8, Address: 0xC000, Length: 1},
+		},
+	}
+	if err := mapper.resolveBanking(); err != nil {
+		t.Fatal(err)
+	}
+	manager := memory.NewManager()
+	manager.SetBankedWindows(mapper.BankedWindows)
+
+	if status := mapper.BankStatus("hp", manager); status != nil {
+		t.Errorf("unbanked property has bank status %+v", status)
+	}
+
+	// Bank 2 mapped in
+	manager.Update(map[uint32][]byte{0xC000: {1}, 0xD000: {42}, 0xFF70: {2}})
+	status := mapper.BankStatus("bank2Counter", manager)
+	if status.Stale || status.ActiveBank == nil || *status.ActiveBank != 2 || status.Error != "" {
+		t.Errorf("status while mapped in %+v", status)
+	}
+	if err := mapper.CheckBankMapped(mapper.Properties["bank2Counter"], manager); err != nil {
+		t.Errorf("bank reported unmapped while mapped in: %v", err)
+	}
+
+	// Switched out, the cached value is stale and the property can't be frozen
+	manager.Update(map[uint32][]byte{0xC000: {1}, 0xD000: {7}, 0xFF70: {0}})
+	status = mapper.BankStatus("bank2Counter", manager)
+	if !status.Stale || *status.ActiveBank != 1 || status.CachedAt == nil {
+		t.Errorf("status while switched out %+v", status)
+	}
+	data, err := mapper.readPropertyBytes(mapper.Properties["bank2Counter"], manager.Snapshot())
+	if err != nil || len(data) != 1 || data[0] != 42 {
+		t.Errorf("switched out value % X (%v), want the cached 2A", data, err)
+	}
+	if err := mapper.FreezeProperty("bank2Counter", manager); err == nil {
+		t.Error("froze a property whose bank is switched out")
+	}
+}
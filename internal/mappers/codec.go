@@ -0,0 +1,221 @@
+package mappers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// ===== NUMERIC ENCODING =====
+
+// decodeNumber decodes the raw bytes of a numeric property into a number.
+// Enums and flags decode to their underlying integer and BCD to its decimal value.
+func (m *Mapper) decodeNumber(prop *Property, data []byte) (float64, error) {
+	littleEndian := prop.Endian == "little" || (prop.Endian == "" && m.Platform.Endian == "little")
+
+	size := numberSize(prop, len(data))
+	if size == 0 {
+		return 0, fmt.Errorf("type %s is not numeric", prop.Type)
+	}
+	if len(data) < size {
+		return 0, fmt.Errorf("%s needs %d bytes, got %d", prop.Type, size, len(data))
+	}
+
+	switch prop.Type {
+	case PropertyTypeUint8, PropertyTypeUint16, PropertyTypeUint32, PropertyTypeEnum, PropertyTypeFlags:
+		return float64(readUnsigned(data[:size], littleEndian)), nil
+	case PropertyTypeInt8:
+		return float64(int8(data[0])), nil
+	case PropertyTypeInt16:
+		return float64(int16(readUnsigned(data[:2], littleEndian))), nil
+	case PropertyTypeInt32:
+		return float64(int32(readUnsigned(data[:4], littleEndian))), nil
+	case PropertyTypeFloat32:
+		return float64(math.Float32frombits(uint32(readUnsigned(data[:4], littleEndian)))), nil
+	case PropertyTypeFloat64:
+		return math.Float64frombits(readUnsigned(data[:8], littleEndian)), nil
+	case PropertyTypeBCD:
+		return float64(m.parseBCDFromBytes(data)), nil
+	case PropertyTypeBool:
+		if data[0] != 0 {
+			return 1, nil
+		}
+		return 0, nil
+	case PropertyTypeBit:
+		return float64((data[0] >> *prop.Position) & 1), nil
+	case PropertyTypeNibble:
+		return float64((data[0] >> (*prop.Position * 4)) & 0x0F), nil
+	}
+	return 0, fmt.Errorf("type %s is not numeric", prop.Type)
+}
+
+// encodeNumber encodes a number into the raw bytes of a numeric property.
+// current is the property's present bytes; bytes the type does not own (the
+// rest of the byte around a bit or nibble, padding after an enum) are kept.
+func (m *Mapper) encodeNumber(prop *Property, value float64, current []byte) ([]byte, error) {
+	littleEndian := prop.Endian == "little" || (prop.Endian == "" && m.Platform.Endian == "little")
+
+	size := numberSize(prop, len(current))
+	if size == 0 {
+		return nil, fmt.Errorf("setting values for type %s not yet implemented", prop.Type)
+	}
+	if len(current) < size {
+		return nil, fmt.Errorf("%s needs %d bytes, got %d", prop.Type, size, len(current))
+	}
+
+	data := append([]byte(nil), current...)
+	if prop.Type != PropertyTypeFloat32 && prop.Type != PropertyTypeFloat64 {
+		value = math.Round(value)
+	}
+
+	switch prop.Type {
+	case PropertyTypeUint8, PropertyTypeUint16, PropertyTypeUint32, PropertyTypeEnum, PropertyTypeFlags:
+		limit := math.Pow(2, float64(8*size)) - 1
+		if value < 0 || value > limit {
+			return nil, fmt.Errorf("value %v is out of range for %s (0 to %v)", value, prop.Type, limit)
+		}
+		writeUnsigned(data[:size], uint64(value), littleEndian)
+	case PropertyTypeInt8, PropertyTypeInt16, PropertyTypeInt32:
+		limit := math.Pow(2, float64(8*size-1))
+		if value < -limit || value > limit-1 {
+			return nil, fmt.Errorf("value %v is out of range for %s (%v to %v)", value, prop.Type, -limit, limit-1)
+		}
+		writeUnsigned(data[:size], uint64(int64(value)), littleEndian)
+	case PropertyTypeFloat32:
+		writeUnsigned(data[:4], uint64(math.Float32bits(float32(value))), littleEndian)
+	case PropertyTypeFloat64:
+		writeUnsigned(data[:8], math.Float64bits(value), littleEndian)
+	case PropertyTypeBCD:
+		if value < 0 || value >= math.Pow(10, float64(2*len(data))) {
+			return nil, fmt.Errorf("value %v does not fit in %d BCD bytes", value, len(data))
+		}
+		digits := uint64(value)
+		for i := len(data) - 1; i >= 0; i-- {
+			data[i] = byte(digits%10) | byte(digits/10%10)<<4
+			digits /= 100
+		}
+	case PropertyTypeBool:
+		if value != 0 && value != 1 {
+			return nil, fmt.Errorf("value %v is not a bool", value)
+		}
+		data[0] = byte(value)
+	case PropertyTypeBit:
+		if value != 0 && value != 1 {
+			return nil, fmt.Errorf("value %v is not a bit", value)
+		}
+		data[0] = data[0]&^(1<<*prop.Position) | byte(value)<<*prop.Position
+	case PropertyTypeNibble:
+		if value < 0 || value > 0x0F {
+			return nil, fmt.Errorf("value %v is out of range for a nibble (0 to 15)", value)
+		}
+		shift := *prop.Position * 4
+		data[0] = data[0]&^(0x0F<<shift) | byte(value)<<shift
+	}
+
+	return data, nil
+}
+
+// numberSize returns how many bytes a numeric property's value occupies, or 0
+// for types that are not numeric. length is the number of bytes available.
+func numberSize(prop *Property, length int) int {
+	switch prop.Type {
+	case PropertyTypeUint8, PropertyTypeInt8, PropertyTypeBool:
+		return 1
+	case PropertyTypeUint16, PropertyTypeInt16:
+		return 2
+	case PropertyTypeUint32, PropertyTypeInt32, PropertyTypeFloat32:
+		return 4
+	case PropertyTypeFloat64:
+		return 8
+	case PropertyTypeBit, PropertyTypeNibble:
+		if prop.Position == nil {
+			return 0
+		}
+		return 1
+	case PropertyTypeEnum, PropertyTypeFlags:
+		if length > 4 {
+			return 4
+		}
+		return length
+	case PropertyTypeBCD:
+		return length
+	}
+	return 0
+}
+
+// readUnsigned reads an unsigned integer of len(data) bytes
+func readUnsigned(data []byte, littleEndian bool) uint64 {
+	switch len(data) {
+	case 2:
+		if littleEndian {
+			return uint64(binary.LittleEndian.Uint16(data))
+		}
+		return uint64(binary.BigEndian.Uint16(data))
+	case 4:
+		if littleEndian {
+			return uint64(binary.LittleEndian.Uint32(data))
+		}
+		return uint64(binary.BigEndian.Uint32(data))
+	case 8:
+		if littleEndian {
+			return binary.LittleEndian.Uint64(data)
+		}
+		return binary.BigEndian.Uint64(data)
+	}
+
+	result := uint64(0)
+	for i := range data {
+		b := data[i]
+		if littleEndian {
+			b = data[len(data)-1-i]
+		}
+		result = result<<8 | uint64(b)
+	}
+	return result
+}
+
+// writeUnsigned writes value as an unsigned integer filling data
+func writeUnsigned(data []byte, value uint64, littleEndian bool) {
+	for i := range data {
+		shift := uint(8 * i)
+		if littleEndian {
+			data[i] = byte(value >> shift)
+		} else {
+			data[len(data)-1-i] = byte(value >> shift)
+		}
+	}
+}
+
+// numberFromValue converts a value decoded from JSON or read from memory to a number
+func numberFromValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
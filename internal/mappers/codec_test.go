@@ -0,0 +1,169 @@
+package mappers
+
+import (
+	"bytes"
+	"gamehook/internal/memory"
+	"testing"
+)
+
+func uint32Ptr(value uint32) *uint32 {
+	return &value
+}
+
+func TestEncodeDecodeNumbers(t *testing.T) {
+	mapper := &Mapper{Platform: Platform{Endian: "little"}}
+
+	tests := []struct {
+		name    string
+		prop    Property
+		current []byte
+		value   float64
+		want    []byte
+	}{
+		{"uint16 little", Property{Type: PropertyTypeUint16}, []byte{0, 0}, 0x1234, []byte{0x34, 0x12}},
+		{"uint16 big", Property{Type: PropertyTypeUint16, Endian: "big"}, []byte{0, 0}, 0x1234, []byte{0x12, 0x34}},
+		{"uint32", Property{Type: PropertyTypeUint32}, []byte{0, 0, 0, 0}, 999999, []byte{0x3F, 0x42, 0x0F, 0x00}},
+		{"int8", Property{Type: PropertyTypeInt8}, []byte{0}, -2, []byte{0xFE}},
+		{"int16 big", Property{Type: PropertyTypeInt16, Endian: "big"}, []byte{0, 0}, -300, []byte{0xFE, 0xD4}},
+		{"int32", Property{Type: PropertyTypeInt32}, []byte{0, 0, 0, 0}, -1, []byte{0xFF, 0xFF, 0xFF, 0xFF}},
+		{"float32", Property{Type: PropertyTypeFloat32}, []byte{0, 0, 0, 0}, 1.5, []byte{0x00, 0x00, 0xC0, 0x3F}},
+		{"bcd", Property{Type: PropertyTypeBCD}, []byte{0, 0, 0}, 123456, []byte{0x12, 0x34, 0x56}},
+		{"enum", Property{Type: PropertyTypeEnum}, []byte{0, 0}, 0x0102, []byte{0x02, 0x01}},
+		{"enum keeps padding", Property{Type: PropertyTypeEnum}, []byte{0, 0, 0, 0, 0xAA, 0xBB}, 7, []byte{7, 0, 0, 0, 0xAA, 0xBB}},
+		{"bool", Property{Type: PropertyTypeBool}, []byte{0}, 1, []byte{1}},
+		{"bit keeps the byte", Property{Type: PropertyTypeBit, Position: uint32Ptr(3)}, []byte{0xF0}, 1, []byte{0xF8}},
+		{"bit clears", Property{Type: PropertyTypeBit, Position: uint32Ptr(7)}, []byte{0xF0}, 0, []byte{0x70}},
+		{"low nibble", Property{Type: PropertyTypeNibble, Position: uint32Ptr(0)}, []byte{0xA5}, 0x0C, []byte{0xAC}},
+		{"high nibble", Property{Type: PropertyTypeNibble, Position: uint32Ptr(1)}, []byte{0xA5}, 0x03, []byte{0x35}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mapper.encodeNumber(&tt.prop, tt.value, tt.current)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("encoded %v as % X, want % X", tt.value, got, tt.want)
+			}
+			if bytes.Equal(tt.current, tt.want) {
+				t.Fatal("test does not change the bytes")
+			}
+
+			decoded, err := mapper.decodeNumber(&tt.prop, got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded != tt.value {
+				t.Errorf("decoded % X as %v, want %v", got, decoded, tt.value)
+			}
+		})
+	}
+}
+
+func TestEncodeNumberRejectsValuesThatDoNotFit(t *testing.T) {
+	mapper := &Mapper{Platform: Platform{Endian: "little"}}
+
+	tests := []struct {
+		name    string
+		prop    Property
+		current []byte
+		value   float64
+	}{
+		{"uint8 above", Property{Type: PropertyTypeUint8}, []byte{0}, 256},
+		{"uint16 negative", Property{Type: PropertyTypeUint16}, []byte{0, 0}, -1},
+		{"int8 below", Property{Type: PropertyTypeInt8}, []byte{0}, -129},
+		{"int16 above", Property{Type: PropertyTypeInt16}, []byte{0, 0}, 32768},
+		{"bcd too many digits", Property{Type: PropertyTypeBCD}, []byte{0, 0}, 10000},
+		{"bcd negative", Property{Type: PropertyTypeBCD}, []byte{0, 0}, -1},
+		{"enum above", Property{Type: PropertyTypeEnum}, []byte{0}, 256},
+		{"bool", Property{Type: PropertyTypeBool}, []byte{0}, 2},
+		{"bit", Property{Type: PropertyTypeBit, Position: uint32Ptr(0)}, []byte{0}, 2},
+		{"bit without position", Property{Type: PropertyTypeBit}, []byte{0}, 1},
+		{"nibble", Property{Type: PropertyTypeNibble, Position: uint32Ptr(0)}, []byte{0}, 16},
+		{"short data", Property{Type: PropertyTypeUint32}, []byte{0, 0}, 1},
+		{"string", Property{Type: PropertyTypeString}, []byte{0}, 1},
+	}
+
+	for _, tt := range tests {
+		if got, err := mapper.encodeNumber(&tt.prop, tt.value, tt.current); err == nil {
+			t.Errorf("%s: encoded %v as % X, want an error", tt.name, tt.value, got)
+		}
+	}
+}
+
+func TestFreezeBound(t *testing.T) {
+	tests := []struct {
+		name    string
+		options FreezeOptions
+		held    float64
+		values  []float64
+		want    []float64
+	}{
+		{"min", FreezeOptions{Mode: FreezeModeMin, Min: floatPtr(10)}, 0, []float64{5, 10, 20}, []float64{10, 10, 20}},
+		{"max", FreezeOptions{Mode: FreezeModeMax, Max: floatPtr(10)}, 0, []float64{5, 10, 20}, []float64{5, 10, 10}},
+		{"range", FreezeOptions{Mode: FreezeModeRange, Min: floatPtr(3), Max: floatPtr(7)}, 0, []float64{1, 5, 9}, []float64{3, 5, 7}},
+		{"increment-only", FreezeOptions{Mode: FreezeModeIncrementOnly}, 5, []float64{3, 8, 6, 9}, []float64{5, 8, 8, 9}},
+		{"decrement-only", FreezeOptions{Mode: FreezeModeDecrementOnly}, 5, []float64{7, 2, 4, 1}, []float64{5, 2, 2, 1}},
+		{"exact", FreezeOptions{Mode: FreezeModeExact}, 0, []float64{1, 2}, []float64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		held := tt.held
+		for i, value := range tt.values {
+			if got := tt.options.bound(value, &held); got != tt.want[i] {
+				t.Errorf("%s: bound(%v) = %v, want %v", tt.name, value, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestBoundedFreezeUsesValueBeforeTransforms(t *testing.T) {
+	multiply := 10.0
+	mapper := &Mapper{
+		Platform: Platform{Endian: "little"},
+		Properties: map[string]*Property{
+			"money": {Name: "money", Type: PropertyTypeBCD, Address: 0xC000, Length: 3, Freezable: true},
+			"speed": {Name: "speed", Type: PropertyTypeUint16, Address: 0xC010, Length: 2, Freezable: true,
+				Transform: &Transform{Multiply: &multiply}},
+			"flag": {Name: "flag", Type: PropertyTypeNibble, Address: 0xC020, Length: 1, Position: uint32Ptr(1), Freezable: true},
+		},
+	}
+	manager := memory.NewManager()
+	manager.Update(map[uint32][]byte{
+		0xC000: {0x00, 0x12, 0x34},
+		0xC010: {0x28, 0x00},
+		0xC020: {0x5A},
+	})
+
+	freezes := map[string]*FreezeOptions{
+		"money": {Mode: FreezeModeMin, Min: floatPtr(5000)},
+		"speed": {Mode: FreezeModeMax, Max: floatPtr(30)}, // Displayed as 300
+		"flag":  {Mode: FreezeModeRange, Min: floatPtr(2), Max: floatPtr(4)},
+	}
+	for name, options := range freezes {
+		if err := mapper.FreezePropertyWithOptions(name, manager, options); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 1234 is below 5000, 40 is above 30 and the nibble's 5 is above 4
+	writes := mapper.FreezeCorrections(manager, manager.Snapshot())
+	got := make(map[uint32][]byte)
+	for _, write := range writes {
+		got[write.Address] = write.Data
+	}
+	want := map[uint32][]byte{
+		0xC000: {0x00, 0x50, 0x00},
+		0xC010: {30, 0x00},
+		0xC020: {0x4A},
+	}
+	for address, data := range want {
+		if !bytes.Equal(got[address], data) {
+			t.Errorf("write at 0x%X is % X, want % X", address, got[address], data)
+		}
+	}
+	if len(writes) != len(want) {
+		t.Errorf("got %d writes, want %d", len(writes), len(want))
+	}
+}
@@ -0,0 +1,296 @@
+package mappers
+
+import (
+	"fmt"
+	"gamehook/internal/drivers"
+	"gamehook/internal/types"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===== GAME DETECTION =====
+
+// Fingerprint identifies the content running in the emulator.
+//
+// No driver sees the ROM file itself, so content is matched by the CRC32 an
+// emulator reports or by the internal title read from emulated memory.
+type Fingerprint struct {
+	System      string `json:"system,omitempty"`
+	Content     string `json:"content,omitempty"`
+	CRC32       string `json:"crc32,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Contentless bool   `json:"contentless,omitempty"`
+}
+
+// Key returns a string that changes whenever the running content changes
+func (f Fingerprint) Key() string {
+	return strings.Join([]string{f.System, f.Content, f.CRC32, f.Title, fmt.Sprint(f.Contentless)}, "|")
+}
+
+// DetectionResult is the outcome of one detection pass
+type DetectionResult struct {
+	Fingerprint Fingerprint `json:"fingerprint"`
+	Mapper      string      `json:"mapper,omitempty"`     // Loader name of the matching mapper
+	MatchedBy   string      `json:"matched_by,omitempty"` // "crc32" or "title"
+	DetectedAt  time.Time   `json:"detected_at"`
+}
+
+// defaultHeaders are the internal title locations for platforms with a fixed ROM header
+var defaultHeaders = map[string]HeaderLocation{
+	"gb":   {Address: 0x0134, Length: 16},
+	"gbc":  {Address: 0x0134, Length: 16},
+	"gba":  {Address: 0x080000A0, Length: 12},
+	"snes": {Address: 0x00FFC0, Length: 21}, // LoROM
+}
+
+// systemAliases maps normalized platform and RetroArch system names to one canonical name
+var systemAliases = map[string]string{
+	"gb": "gb", "gameboy": "gb", "dmg": "gb",
+	"gbc": "gbc", "gameboycolor": "gbc", "cgb": "gbc",
+	"gba": "gba", "gameboyadvance": "gba",
+	"nes": "nes", "famicom": "nes", "nintendoentertainmentsystem": "nes",
+	"snes": "snes", "supernes": "snes", "supernintendo": "snes", "superfamicom": "snes",
+	"n64": "n64", "nintendo64": "n64",
+	"nds": "nds", "nintendods": "nds",
+}
+
+// detectionCandidate is a mapper that declares identification
+type detectionCandidate struct {
+	name           string
+	system         string
+	identification *GameIdentification
+}
+
+// Detector matches the running content against mappers that declare identification
+type Detector struct {
+	loader *Loader
+
+	mu         sync.Mutex
+	candidates []detectionCandidate
+	attempted  map[string]bool // Mapper names already loaded (or failed to load)
+	lastKey    string          // Fingerprint key of the previous pass
+	detected   bool            // Whether a pass has completed
+}
+
+// NewDetector creates a detector that searches the loader's mappers
+func NewDetector(loader *Loader) *Detector {
+	return &Detector{
+		loader:    loader,
+		attempted: make(map[string]bool),
+	}
+}
+
+// Reset forgets the inspected mappers so the next pass reads their files again
+func (d *Detector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resetLocked()
+}
+
+// resetLocked clears the candidate list and the loader's cached mappers
+func (d *Detector) resetLocked() {
+	d.candidates = nil
+	d.attempted = make(map[string]bool)
+	d.loader.Forget()
+}
+
+// Detect fingerprints the running content and finds the mapper that matches it.
+//
+// Drivers implementing drivers.EmulatorController report the system, content
+// name and CRC32; otherwise the internal title is read from the ROM header.
+// When the content changes, mappers are inspected again so identification
+// edited since the last pass is seen.
+func (d *Detector) Detect(driver drivers.Driver) (*DetectionResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result, err := d.detectLocked(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	key := result.Fingerprint.Key()
+	if d.detected && key != d.lastKey {
+		d.resetLocked()
+		if result.Mapper == "" && !result.Fingerprint.Contentless {
+			if result, err = d.detectLocked(driver); err != nil {
+				return nil, err
+			}
+			key = result.Fingerprint.Key()
+		}
+	}
+	d.lastKey = key
+	d.detected = true
+
+	return result, nil
+}
+
+// detectLocked runs one detection pass against the current candidates
+func (d *Detector) detectLocked(driver drivers.Driver) (*DetectionResult, error) {
+	result := &DetectionResult{DetectedAt: time.Now()}
+	fp := &result.Fingerprint
+
+	if controller, ok := driver.(drivers.EmulatorController); ok {
+		status, err := controller.EmulationStatus()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query emulator status: %w", err)
+		}
+		if status.State == "contentless" {
+			fp.Contentless = true
+			return result, nil
+		}
+		fp.System = status.System
+		fp.Content = status.Content
+		fp.CRC32 = strings.ToLower(status.CRC32)
+	}
+
+	d.refreshCandidates()
+	system := canonicalSystem(fp.System)
+
+	// The CRC32 identifies the exact ROM, so it wins over titles
+	for _, candidate := range d.candidates {
+		if fp.CRC32 != "" && containsString(candidate.identification.CRC32, fp.CRC32) {
+			result.Mapper, result.MatchedBy = candidate.name, "crc32"
+			return result, nil
+		}
+	}
+
+	// Fall back to the internal title, reading each header location once
+	titles := make(map[HeaderLocation]string)
+	for _, candidate := range d.candidates {
+		if len(candidate.identification.Titles) == 0 {
+			continue
+		}
+		if system != "" && !systemsCompatible(system, candidate.system) {
+			continue
+		}
+
+		header, ok := headerLocation(candidate)
+		if !ok {
+			continue
+		}
+
+		title, read := titles[header]
+		if !read {
+			title = readHeaderTitle(driver, header)
+			titles[header] = title
+		}
+		if title == "" {
+			continue
+		}
+		if fp.Title == "" {
+			fp.Title = title
+		}
+
+		for _, expected := range candidate.identification.Titles {
+			if strings.EqualFold(strings.TrimSpace(expected), title) {
+				fp.Title = title
+				result.Mapper, result.MatchedBy = candidate.name, "title"
+				return result, nil
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// refreshCandidates loads mappers that have not been inspected yet
+func (d *Detector) refreshCandidates() {
+	names := d.loader.List()
+	sort.Strings(names)
+
+	for _, name := range names {
+		if d.attempted[name] {
+			continue
+		}
+		d.attempted[name] = true
+
+		mapper, err := d.loader.Load(name)
+		if err != nil {
+			log.Printf("⚠️  Skipping mapper %s for game detection: %v", name, err)
+			continue
+		}
+		if mapper.Identification == nil {
+			continue
+		}
+
+		d.candidates = append(d.candidates, detectionCandidate{
+			name:           name,
+			system:         canonicalSystem(mapper.Platform.Name),
+			identification: mapper.Identification,
+		})
+	}
+}
+
+// headerLocation returns where a candidate's title is stored
+func headerLocation(candidate detectionCandidate) (HeaderLocation, bool) {
+	if candidate.identification.Header != nil {
+		return *candidate.identification.Header, true
+	}
+	header, ok := defaultHeaders[candidate.system]
+	return header, ok
+}
+
+// readHeaderTitle reads and normalizes an internal title, returning "" on failure
+func readHeaderTitle(driver drivers.Driver, header HeaderLocation) string {
+	block := types.MemoryBlock{
+		Name:  "header",
+		Start: header.Address,
+		End:   header.Address + header.Length - 1,
+	}
+
+	data, err := driver.ReadMemoryBlocks([]types.MemoryBlock{block})
+	if err != nil {
+		return ""
+	}
+
+	raw := data[header.Address]
+	var title strings.Builder
+	for _, b := range raw {
+		if b == 0 {
+			break
+		}
+		if b >= 0x20 && b < 0x7F {
+			title.WriteByte(b)
+		}
+	}
+
+	return strings.ToUpper(strings.TrimSpace(title.String()))
+}
+
+// canonicalSystem normalizes a platform or system name ("Game Boy", "game_boy" → "gb")
+func canonicalSystem(name string) string {
+	var normalized strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			normalized.WriteRune(r)
+		}
+	}
+
+	if canonical, ok := systemAliases[normalized.String()]; ok {
+		return canonical
+	}
+	return normalized.String()
+}
+
+// systemsCompatible reports whether content for one system can run a mapper for another.
+// Game Boy cores commonly report Game Boy Color content as Game Boy and vice versa.
+func systemsCompatible(a, b string) bool {
+	if a == b {
+		return true
+	}
+	return (a == "gb" || a == "gbc") && (b == "gb" || b == "gbc")
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
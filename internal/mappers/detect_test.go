@@ -0,0 +1,168 @@
+package mappers
+
+import (
+	"fmt"
+	"gamehook/internal/drivers"
+	"gamehook/internal/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// headerDriver serves a Game Boy header title and nothing else
+type headerDriver struct {
+	title string
+}
+
+func (d *headerDriver) Connect() error { return nil }
+func (d *headerDriver) Close() error   { return nil }
+
+func (d *headerDriver) WriteBytes(address uint32, data []byte) error {
+	return fmt.Errorf("read-only")
+}
+
+func (d *headerDriver) ReadMemoryBlocks(blocks []types.MemoryBlock) (map[uint32][]byte, error) {
+	result := make(map[uint32][]byte)
+	for _, block := range blocks {
+		data := make([]byte, block.End-block.Start+1)
+		if block.Start == 0x0134 {
+			copy(data, d.title)
+		}
+		result[block.Start] = data
+	}
+	return result, nil
+}
+
+// controllerDriver also reports the running content like RetroArch does
+type controllerDriver struct {
+	headerDriver
+	status drivers.EmulationStatus
+}
+
+func (d *controllerDriver) EmulationStatus() (drivers.EmulationStatus, error) { return d.status, nil }
+func (d *controllerDriver) Pause() error                                      { return nil }
+func (d *controllerDriver) Resume() error                                     { return nil }
+func (d *controllerDriver) TogglePause() error                                { return nil }
+func (d *controllerDriver) FrameAdvance(frames int) error                     { return nil }
+func (d *controllerDriver) Reset() error                                      { return nil }
+func (d *controllerDriver) SaveState() error                                  { return nil }
+func (d *controllerDriver) LoadState() error                                  { return nil }
+func (d *controllerDriver) ChangeStateSlot(delta int) error                   { return nil }
+
+func writeDetectionMapper(t *testing.T, dir, name string, titles string) {
+	t.Helper()
+	writeIdentifiedMapper(t, dir, name, "titles: ["+titles+"]")
+}
+
+func writeIdentifiedMapper(t *testing.T, dir, name string, identification string) {
+	t.Helper()
+	source := fmt.Sprintf(`name: %q
+game: "Test"
+identification: {
+    %s
+}
+platform: {
+    name: "Game Boy"
+    endian: "little"
+    memoryBlocks: [{name: "wram", start: "0xC000", end: "0xDFFF"}]
+}
+properties: {}
+`, name, identification)
+	if err := os.WriteFile(filepath.Join(dir, name+".cue"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetectorRereadsMappersWhenContentChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeDetectionMapper(t, dir, "red", `"POKEMON RED"`)
+	writeDetectionMapper(t, dir, "yellow", `"POKEMON YELLOW"`)
+
+	detector := NewDetector(NewLoader(dir))
+	driver := &headerDriver{title: "POKEMON RED"}
+
+	result, err := detector.Detect(driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mapper != "red" || result.MatchedBy != "title" {
+		t.Fatalf("detected %q by %q, want red by title", result.Mapper, result.MatchedBy)
+	}
+
+	// A mapper edited while its game isn't running
+	writeDetectionMapper(t, dir, "yellow", `"POKEMON YELLOW", "POKEMON YELLOW J"`)
+
+	// The same content doesn't re-read the mappers
+	driver.title = "POKEMON RED"
+	if result, err = detector.Detect(driver); err != nil || result.Mapper != "red" {
+		t.Fatalf("detected %q (%v), want red", result.Mapper, err)
+	}
+
+	// New content does, so the edit is seen
+	driver.title = "POKEMON YELLOW J"
+	if result, err = detector.Detect(driver); err != nil {
+		t.Fatal(err)
+	}
+	if result.Mapper != "yellow" || result.Fingerprint.Title != "POKEMON YELLOW J" {
+		t.Errorf("detected %q for %q, want yellow", result.Mapper, result.Fingerprint.Title)
+	}
+}
+
+func TestDetectorReset(t *testing.T) {
+	dir := t.TempDir()
+	writeDetectionMapper(t, dir, "red", `"POKEMON RED"`)
+
+	detector := NewDetector(NewLoader(dir))
+	driver := &headerDriver{title: "POKEMON RED"}
+
+	if result, err := detector.Detect(driver); err != nil || result.Mapper != "red" {
+		t.Fatalf("detected %q (%v), want red", result.Mapper, err)
+	}
+
+	// Without a content change, only a reset picks up the edited identification
+	writeDetectionMapper(t, dir, "red", `"POKEMON BLUE"`)
+	if result, _ := detector.Detect(driver); result.Mapper != "red" {
+		t.Fatalf("detected %q before reset, want the cached red", result.Mapper)
+	}
+
+	detector.Reset()
+	if result, err := detector.Detect(driver); err != nil || result.Mapper != "" {
+		t.Errorf("detected %q (%v) after reset, want no match", result.Mapper, err)
+	}
+}
+
+func TestDetectorPrefersCRC32OverTitle(t *testing.T) {
+	dir := t.TempDir()
+	// "a_title" sorts first, so a title match would win if it were tried first
+	writeIdentifiedMapper(t, dir, "a_title", `titles: ["POKEMON RED"]`)
+	writeIdentifiedMapper(t, dir, "b_crc", `crc32: ["0x9F7FDD53"]`)
+
+	detector := NewDetector(NewLoader(dir))
+	driver := &controllerDriver{
+		headerDriver: headerDriver{title: "POKEMON RED"},
+		status:       drivers.EmulationStatus{State: "playing", System: "game_boy", Content: "Pokemon Red", CRC32: "9F7FDD53"},
+	}
+
+	result, err := detector.Detect(driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mapper != "b_crc" || result.MatchedBy != "crc32" {
+		t.Errorf("detected %q by %q, want b_crc by crc32", result.Mapper, result.MatchedBy)
+	}
+
+	// An unknown CRC32 falls back to the title
+	driver.status.CRC32 = "00000000"
+	if result, err = detector.Detect(driver); err != nil {
+		t.Fatal(err)
+	}
+	if result.Mapper != "a_title" || result.MatchedBy != "title" {
+		t.Errorf("detected %q by %q, want a_title by title", result.Mapper, result.MatchedBy)
+	}
+
+	// Contentless emulators match nothing and read no header
+	driver.status = drivers.EmulationStatus{State: "contentless"}
+	if result, err = detector.Detect(driver); err != nil || result.Mapper != "" || !result.Fingerprint.Contentless {
+		t.Errorf("contentless detection returned %+v, %v", result, err)
+	}
+}
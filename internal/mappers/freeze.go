@@ -0,0 +1,501 @@
+package mappers
+
+import (
+	"bytes"
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/parser"
+	"fmt"
+	"gamehook/internal/memory"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===== FREEZE ENGINE =====
+
+// FreezeMode selects how a frozen property is held
+type FreezeMode string
+
+const (
+	FreezeModeExact         FreezeMode = "exact"          // Pin the bytes captured when frozen
+	FreezeModeMin           FreezeMode = "min"            // Never drop below Min
+	FreezeModeMax           FreezeMode = "max"            // Never exceed Max
+	FreezeModeRange         FreezeMode = "range"          // Stay between Min and Max
+	FreezeModeIncrementOnly FreezeMode = "increment-only" // Never drop below the highest value seen
+	FreezeModeDecrementOnly FreezeMode = "decrement-only" // Never rise above the lowest value seen
+)
+
+// FreezeOptions makes a freeze conditional, temporary or bounded
+type FreezeOptions struct {
+	Condition string        `json:"condition,omitempty"` // CUE expression over other properties, e.g. "inBattle"
+	Duration  time.Duration `json:"duration,omitempty"`  // Unfreeze after this long
+	Frames    uint64        `json:"frames,omitempty"`    // Unfreeze after this many memory reads
+	Source    string        `json:"source,omitempty"`
+
+	// Bounded modes work on the decoded value before transforms: for a
+	// property that multiplies by 10, min 50 holds a displayed 500
+	Mode FreezeMode `json:"mode,omitempty"`
+	Min  *float64   `json:"min,omitempty"`
+	Max  *float64   `json:"max,omitempty"`
+}
+
+// Bounded reports whether the options hold a decoded value rather than exact bytes
+func (o *FreezeOptions) Bounded() bool {
+	return o != nil && o.Mode != "" && o.Mode != FreezeModeExact
+}
+
+// Validate checks that the mode is known and has the bounds it needs
+func (o *FreezeOptions) Validate() error {
+	switch o.Mode {
+	case "", FreezeModeExact:
+		if o.Min != nil || o.Max != nil {
+			return fmt.Errorf("min and max need a min, max or range freeze mode")
+		}
+	case FreezeModeIncrementOnly, FreezeModeDecrementOnly:
+	case FreezeModeMin:
+		if o.Min == nil {
+			return fmt.Errorf("freeze mode %s needs min", o.Mode)
+		}
+	case FreezeModeMax:
+		if o.Max == nil {
+			return fmt.Errorf("freeze mode %s needs max", o.Mode)
+		}
+	case FreezeModeRange:
+		if o.Min == nil || o.Max == nil {
+			return fmt.Errorf("freeze mode %s needs min and max", o.Mode)
+		}
+		if *o.Min > *o.Max {
+			return fmt.Errorf("freeze range min %v is above max %v", *o.Min, *o.Max)
+		}
+	default:
+		return fmt.Errorf("unknown freeze mode %q", o.Mode)
+	}
+	return nil
+}
+
+// withDefaults fills the mode and bounds of o from the mapper's freeze
+// options for the property when o does not choose a mode itself
+func (o *FreezeOptions) withDefaults(defaults *FreezeOptions) *FreezeOptions {
+	if defaults == nil {
+		return o
+	}
+	if o == nil {
+		merged := *defaults
+		return &merged
+	}
+
+	merged := *o
+	if merged.Mode == "" && merged.Min == nil && merged.Max == nil {
+		merged.Mode, merged.Min, merged.Max = defaults.Mode, defaults.Min, defaults.Max
+	}
+	if merged.Condition == "" {
+		merged.Condition = defaults.Condition
+	}
+	return &merged
+}
+
+// bound returns the value a bounded freeze holds when the property reads
+// value. held tracks the high or low water mark of the one-way modes.
+func (o *FreezeOptions) bound(value float64, held *float64) float64 {
+	switch o.Mode {
+	case FreezeModeMin:
+		return math.Max(value, *o.Min)
+	case FreezeModeMax:
+		return math.Min(value, *o.Max)
+	case FreezeModeRange:
+		return math.Min(math.Max(value, *o.Min), *o.Max)
+	case FreezeModeIncrementOnly:
+		if value < *held {
+			return *held
+		}
+		*held = value
+	case FreezeModeDecrementOnly:
+		if value > *held {
+			return *held
+		}
+		*held = value
+	}
+	return value
+}
+
+// FreezeWrite is one coalesced write that restores drifted frozen properties
+type FreezeWrite struct {
+	Domain     string
+	Address    uint32
+	Data       []byte
+	Properties []string // Drifted properties the write corrects
+}
+
+// frozenState is a copy of one property's freeze state, taken under freezeMu so
+// enforcement never sees a freeze change halfway
+type frozenState struct {
+	name    string
+	prop    *Property
+	data    []byte
+	options *FreezeOptions // Copy of the freeze options; nil for a plain freeze
+	held    float64
+	current *FreezeOptions // The property's options when copied, to detect a refreeze
+}
+
+// IsFrozen reports whether the named property is frozen
+func (m *Mapper) IsFrozen(name string) bool {
+	prop, exists := m.Properties[name]
+	if !exists {
+		return false
+	}
+
+	m.freezeMu.Lock()
+	defer m.freezeMu.Unlock()
+	return prop.Frozen
+}
+
+// frozenStates copies the freeze state of every frozen property
+func (m *Mapper) frozenStates() []frozenState {
+	m.freezeMu.Lock()
+	defer m.freezeMu.Unlock()
+
+	var states []frozenState
+	for name, prop := range m.Properties {
+		if !prop.Frozen || len(prop.FrozenData) == 0 {
+			continue
+		}
+
+		state := frozenState{
+			name:    name,
+			prop:    prop,
+			data:    append([]byte(nil), prop.FrozenData...),
+			held:    prop.freezeHeld,
+			current: prop.FreezeOptions,
+		}
+		if prop.FreezeOptions != nil {
+			options := *prop.FreezeOptions
+			state.options = &options
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// storeHeld saves the water mark of a one-way freeze unless the property was
+// unfrozen or frozen again since its state was copied
+func (m *Mapper) storeHeld(state frozenState) {
+	m.freezeMu.Lock()
+	defer m.freezeMu.Unlock()
+
+	if state.prop.Frozen && state.prop.FreezeOptions == state.current {
+		state.prop.freezeHeld = state.held
+	}
+}
+
+// frozenSpan is the bytes a frozen property should hold in the current snapshot
+type frozenSpan struct {
+	domain  string
+	start   uint32
+	data    []byte
+	name    string
+	drifted bool
+}
+
+// FreezeCorrections compares every frozen property with snap and returns the
+// writes needed to restore the ones that drifted.
+//
+// Frozen properties whose bytes touch are merged into a single write, so a
+// frozen struct or a run of adjacent counters costs one driver call. Runs
+// where nothing drifted are not written at all. Bit and nibble properties only
+// restore their own bits; the rest of the byte keeps its current value.
+// Conditional freezes are skipped while their condition does not hold.
+func (m *Mapper) FreezeCorrections(memManager *memory.Manager, snap *memory.Snapshot) []FreezeWrite {
+	var spans []frozenSpan
+	for _, state := range m.frozenStates() {
+		prop := state.prop
+
+		// Banked values are only enforced while their bank is mapped in
+		if m.checkBankMappedAt(prop, snap) != nil {
+			continue
+		}
+
+		if state.options != nil && state.options.Condition != "" {
+			holds, err := m.EvaluateCondition(state.options.Condition, memManager, snap)
+			memManager.RecordFreezeCondition(state.name, holds, err)
+			if !holds {
+				continue
+			}
+		}
+
+		current, err := snap.ReadDomainBytes(prop.Domain, prop.Address, uint32(len(state.data)))
+		if err != nil {
+			continue
+		}
+
+		var want []byte
+		if state.options.Bounded() {
+			if want, err = m.boundedFreezeBytes(&state, current); err != nil {
+				memManager.RecordFreezeEnforcement(state.name, err)
+				continue
+			}
+		} else {
+			want = make([]byte, len(current))
+			mask := freezeMask(prop)
+			for i := range want {
+				keep := byte(0xFF)
+				if i < len(mask) {
+					keep = mask[i]
+				}
+				want[i] = current[i]&^keep | state.data[i]&keep
+			}
+		}
+
+		spans = append(spans, frozenSpan{
+			domain:  prop.Domain,
+			start:   prop.Address,
+			data:    want,
+			name:    state.name,
+			drifted: !bytes.Equal(want, current),
+		})
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].domain != spans[j].domain {
+			return spans[i].domain < spans[j].domain
+		}
+		return spans[i].start < spans[j].start
+	})
+
+	var writes []FreezeWrite
+	var run *FreezeWrite
+	flush := func() {
+		if run != nil && len(run.Properties) > 0 {
+			writes = append(writes, *run)
+		}
+		run = nil
+	}
+
+	for _, span := range spans {
+		runEnd := uint64(0)
+		if run != nil {
+			runEnd = uint64(run.Address) + uint64(len(run.Data))
+		}
+		if run == nil || run.Domain != span.domain || uint64(span.start) > runEnd {
+			flush()
+			run = &FreezeWrite{Domain: span.domain, Address: span.start}
+		}
+
+		// Extend the run, letting the later span win where frozen ranges overlap
+		offset := span.start - run.Address
+		if end := int(offset) + len(span.data); end > len(run.Data) {
+			run.Data = append(run.Data, make([]byte, end-len(run.Data))...)
+		}
+		copy(run.Data[offset:], span.data)
+
+		if span.drifted {
+			run.Properties = append(run.Properties, span.name)
+		}
+	}
+	flush()
+
+	return writes
+}
+
+// ApplyFreezeCorrections writes the corrections for snap through write and
+// records the outcome for every property a write covers, so one rejected
+// write marks each of its properties as failing. It returns the properties
+// that just started failing with the error, for callers to report once.
+func (m *Mapper) ApplyFreezeCorrections(memManager *memory.Manager, snap *memory.Snapshot, write func(domain string, address uint32, data []byte) error) map[string]error {
+	failed := make(map[string]error)
+	for _, correction := range m.FreezeCorrections(memManager, snap) {
+		err := write(correction.Domain, correction.Address, correction.Data)
+		if err == nil {
+			// Keep the local copy in step so this tick's evaluation sees the frozen value
+			memManager.WriteDomainBytes(correction.Domain, correction.Address, correction.Data)
+		}
+
+		for _, name := range correction.Properties {
+			if failures := memManager.RecordFreezeEnforcement(name, err); failures == 1 {
+				failed[name] = err
+			}
+		}
+	}
+	return failed
+}
+
+// boundedFreezeBytes returns the bytes a bounded freeze wants the property to
+// hold given its current bytes, going through the property's encoder
+func (m *Mapper) boundedFreezeBytes(state *frozenState, current []byte) ([]byte, error) {
+	value, err := m.decodeNumber(state.prop, current)
+	if err != nil {
+		return nil, err
+	}
+
+	held := state.held
+	target := state.options.bound(value, &state.held)
+	if state.held != held {
+		m.storeHeld(*state)
+	}
+	if target == value {
+		return current, nil
+	}
+	return m.encodeNumber(state.prop, target, current)
+}
+
+// ExpireFreezes unfreezes temporary freezes whose duration or frame limit ran
+// out by snap and returns their names
+func (m *Mapper) ExpireFreezes(memManager *memory.Manager, snap *memory.Snapshot) []string {
+	now := time.Now()
+	var due []string
+	m.freezeMu.Lock()
+	for name, prop := range m.Properties {
+		if !prop.Frozen {
+			continue
+		}
+
+		timedOut := !prop.freezeExpiresAt.IsZero() && !now.Before(prop.freezeExpiresAt)
+		framesUsed := prop.freezeExpiresFrame != 0 && snap.Frame >= prop.freezeExpiresFrame
+		if timedOut || framesUsed {
+			due = append(due, name)
+		}
+	}
+	m.freezeMu.Unlock()
+
+	var expired []string
+	for _, name := range due {
+		if err := m.UnfreezeProperty(name, memManager); err == nil {
+			expired = append(expired, name)
+		}
+	}
+
+	sort.Strings(expired)
+	return expired
+}
+
+// conditionCache holds compiled conditions by expression. Conditions are
+// evaluated every tick, so each is parsed and compiled once and only the
+// property values are filled in per evaluation.
+type conditionCache struct {
+	mu         sync.Mutex // Also serializes evaluation, since a CUE context is not safe for concurrent use
+	ctx        *cue.Context
+	conditions map[string]*compiledCondition
+}
+
+// compiledCondition is a condition compiled with a placeholder for each
+// property it references
+type compiledCondition struct {
+	references []string  // Properties the expression reads
+	value      cue.Value // "<property>: _" per reference and "result: (<expr>)"
+	err        error     // Parse or compile error, reported on every evaluation
+}
+
+// conditionResult is the field of a compiled condition holding its result
+var conditionResult = cue.ParsePath("result")
+
+// EvaluateCondition evaluates a CUE boolean expression over property values
+// read from snap. Each property the expression references is bound to its
+// current value, so "inBattle && playerHP < 10" reads inBattle and playerHP.
+func (m *Mapper) EvaluateCondition(expr string, memManager *memory.Manager, snap *memory.Snapshot) (bool, error) {
+	m.conditions.mu.Lock()
+	defer m.conditions.mu.Unlock()
+
+	condition := m.compileConditionLocked(expr)
+	if condition.err != nil {
+		return false, condition.err
+	}
+
+	value := condition.value
+	for _, name := range condition.references {
+		current, err := m.GetPropertyAt(name, memManager, snap)
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		value = value.FillPath(cue.MakePath(cue.Str(name)), current)
+	}
+
+	result := value.LookupPath(conditionResult)
+	if err := result.Err(); err != nil {
+		return false, err
+	}
+	return result.Bool()
+}
+
+// compileConditionLocked returns the compiled form of expr, compiling it on
+// first use. The caller must hold m.conditions.mu.
+func (m *Mapper) compileConditionLocked(expr string) *compiledCondition {
+	if condition, exists := m.conditions.conditions[expr]; exists {
+		return condition
+	}
+	if m.conditions.conditions == nil {
+		m.conditions.ctx = cuecontext.New()
+		m.conditions.conditions = make(map[string]*compiledCondition)
+	}
+
+	condition := &compiledCondition{}
+	m.conditions.conditions[expr] = condition
+
+	parsed, err := parser.ParseExpr("condition", expr)
+	if err != nil {
+		condition.err = fmt.Errorf("invalid condition %q: %w", expr, err)
+		return condition
+	}
+	condition.references = m.conditionReferences(parsed)
+
+	var source strings.Builder
+	for _, name := range condition.references {
+		fmt.Fprintf(&source, "%s: _\n", name)
+	}
+	fmt.Fprintf(&source, "result: (%s)\n", expr)
+
+	condition.value = m.conditions.ctx.CompileString(source.String())
+	if err := condition.value.Err(); err != nil {
+		condition.err = err
+	}
+	return condition
+}
+
+// conditionReferences returns the properties an expression references, in
+// order of first use. Identifiers inside strings, selected fields and struct
+// labels are not references.
+func (m *Mapper) conditionReferences(expr ast.Expr) []string {
+	var references []string
+	seen := make(map[string]bool)
+
+	var walk func(node ast.Node)
+	walk = func(node ast.Node) {
+		ast.Walk(node, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				walk(n.X)
+				return false
+			case *ast.Field:
+				walk(n.Value)
+				return false
+			case *ast.Ident:
+				if _, exists := m.Properties[n.Name]; exists && !seen[n.Name] && n.Name != "result" {
+					seen[n.Name] = true
+					references = append(references, n.Name)
+				}
+			}
+			return true
+		}, nil)
+	}
+	walk(expr)
+
+	return references
+}
+
+// freezeMask returns the bits of each byte a frozen property owns, or nil when
+// it owns its bytes entirely
+func freezeMask(prop *Property) []byte {
+	if prop.Position == nil {
+		return nil
+	}
+
+	switch prop.Type {
+	case PropertyTypeBit:
+		return []byte{1 << *prop.Position}
+	case PropertyTypeNibble:
+		return []byte{0x0F << (*prop.Position * 4)}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package mappers
+
+import (
+	"bytes"
+	"cuelang.org/go/cue/parser"
+	"errors"
+	"gamehook/internal/memory"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func conditionTestMapper() (*Mapper, *memory.Manager) {
+	mapper := &Mapper{
+		Platform: Platform{Endian: "little"},
+		Properties: map[string]*Property{
+			"hp":       {Name: "hp", Type: PropertyTypeUint8, Address: 0xC000, Length: 1},
+			"inBattle": {Name: "inBattle", Type: PropertyTypeBool, Address: 0xC001, Length: 1},
+			"state":    {Name: "state", Type: PropertyTypeUint8, Address: 0xC002, Length: 1},
+		},
+	}
+
+	manager := memory.NewManager()
+	manager.Update(map[uint32][]byte{0xC000: {5, 1, 2}})
+	return mapper, manager
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	mapper, manager := conditionTestMapper()
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"inBattle && hp < 10", true},
+		{"hp > 10", false},
+		{"state == 2 && !inBattle", false},
+		{`"hp" == "hp"`, true},
+	}
+	for _, test := range tests {
+		got, err := mapper.EvaluateCondition(test.expr, manager, manager.Snapshot())
+		if err != nil {
+			t.Errorf("%s: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	// Values are filled in per evaluation, not compiled in
+	manager.Update(map[uint32][]byte{0xC000: {50, 1, 2}})
+	if got, err := mapper.EvaluateCondition("inBattle && hp < 10", manager, manager.Snapshot()); err != nil || got {
+		t.Errorf("after hp changed: got %v, %v; want false", got, err)
+	}
+	if n := len(mapper.conditions.conditions); n != len(tests) {
+		t.Errorf("compiled %d conditions, want %d", n, len(tests))
+	}
+
+	if _, err := mapper.EvaluateCondition("hp <", manager, manager.Snapshot()); err == nil {
+		t.Error("invalid condition evaluated without error")
+	}
+}
+
+func TestConditionReferences(t *testing.T) {
+	mapper, _ := conditionTestMapper()
+
+	tests := map[string][]string{
+		"inBattle && hp < 10":        {"inBattle", "hp"},
+		`state == "hp"`:              {"state"},
+		"hp == hp":                   {"hp"},
+		"{inBattle: state}.inBattle": {"state"},
+		"unknown > 3":                nil,
+	}
+	for expr, want := range tests {
+		parsed, err := parser.ParseExpr("condition", expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		if got := mapper.conditionReferences(parsed); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s references %v, want %v", expr, got, want)
+		}
+	}
+}
+
+func TestFreezeConcurrentWithEnforcement(t *testing.T) {
+	mapper, manager := conditionTestMapper()
+	for _, prop := range mapper.Properties {
+		prop.Freezable = true
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, name := range []string{"hp", "state"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			options := []*FreezeOptions{
+				nil,
+				{Mode: FreezeModeIncrementOnly},
+				{Mode: FreezeModeRange, Min: floatPtr(1), Max: floatPtr(3)},
+				{Condition: "inBattle"},
+			}
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if err := mapper.FreezePropertyWithOptions(name, manager, options[i%len(options)]); err != nil {
+					t.Error(err)
+					return
+				}
+				if err := mapper.UnfreezeProperty(name, manager); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(name)
+	}
+
+	for i := 0; i < 2000; i++ {
+		manager.Update(map[uint32][]byte{0xC000: {byte(i), 1, byte(i >> 3)}})
+		snap := manager.Snapshot()
+		mapper.FreezeCorrections(manager, snap)
+		mapper.ExpireFreezes(manager, snap)
+	}
+	close(done)
+	wg.Wait()
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func TestFreezeCorrectionsCoalesceAndReportFailuresPerProperty(t *testing.T) {
+	mapper := &Mapper{
+		Platform: Platform{Endian: "little"},
+		Properties: map[string]*Property{
+			"hp":    {Name: "hp", Type: PropertyTypeUint8, Address: 0xC000, Length: 1, Freezable: true},
+			"money": {Name: "money", Type: PropertyTypeUint16, Address: 0xC001, Length: 2, Freezable: true},
+			"lives": {Name: "lives", Type: PropertyTypeUint8, Address: 0xC004, Length: 1, Freezable: true},
+			"level": {Name: "level", Type: PropertyTypeUint8, Address: 0xC005, Length: 1, Freezable: true},
+		},
+	}
+	manager := memory.NewManager()
+	manager.Update(map[uint32][]byte{0xC000: {10, 0x34, 0x12, 0, 3, 7}})
+	for name := range mapper.Properties {
+		if err := mapper.FreezeProperty(name, manager); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Everything but level drifts
+	manager.Update(map[uint32][]byte{0xC000: {1, 0, 0, 0, 0, 7}})
+
+	writes := mapper.FreezeCorrections(manager, manager.Snapshot())
+	want := []FreezeWrite{
+		{Address: 0xC000, Data: []byte{10, 0x34, 0x12}, Properties: []string{"hp", "money"}},
+		{Address: 0xC004, Data: []byte{3, 7}, Properties: []string{"lives"}},
+	}
+	if !reflect.DeepEqual(writes, want) {
+		t.Fatalf("corrections %+v, want %+v", writes, want)
+	}
+
+	// The driver rejects the coalesced write at 0xC000
+	rejected := errors.New("rejected")
+	write := func(domain string, address uint32, data []byte) error {
+		if address == 0xC000 {
+			return rejected
+		}
+		return nil
+	}
+
+	failed := mapper.ApplyFreezeCorrections(manager, manager.Snapshot(), write)
+	if len(failed) != 2 || failed["hp"] != rejected || failed["money"] != rejected {
+		t.Errorf("failed properties %v, want hp and money", failed)
+	}
+	for name, status := range map[string]string{"hp": "failing", "money": "failing", "lives": "holding", "level": "holding"} {
+		enforcement := manager.GetPropertyState(name).Freeze
+		if enforcement.Status != status {
+			t.Errorf("%s is %s, want %s", name, enforcement.Status, status)
+		}
+	}
+	if enforcement := manager.GetPropertyState("money").Freeze; enforcement.WriteFailures != 1 || enforcement.LastError != "rejected" {
+		t.Errorf("money recorded %d failures with %q", enforcement.WriteFailures, enforcement.LastError)
+	}
+	if enforcement := manager.GetPropertyState("lives").Freeze; enforcement.Enforcements != 1 {
+		t.Errorf("lives recorded %d enforcements, want 1", enforcement.Enforcements)
+	}
+	if enforcement := manager.GetPropertyState("level").Freeze; enforcement.Enforcements != 0 {
+		t.Errorf("level recorded %d enforcements without drifting", enforcement.Enforcements)
+	}
+	if got, _ := manager.ReadBytes(0xC000, 6); !bytes.Equal(got, []byte{1, 0, 0, 0, 3, 7}) {
+		t.Errorf("memory after corrections is % X", got)
+	}
+
+	// A repeated failure is recorded but not reported again
+	if failed := mapper.ApplyFreezeCorrections(manager, manager.Snapshot(), write); len(failed) != 0 {
+		t.Errorf("repeated failure reported again: %v", failed)
+	}
+	if enforcement := manager.GetPropertyState("hp").Freeze; enforcement.ConsecutiveFailures != 2 {
+		t.Errorf("hp has %d consecutive failures, want 2", enforcement.ConsecutiveFailures)
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // PropertyType represents the enhanced property types
@@ -291,10 +293,13 @@ type ComputedProperty struct {
 type Property struct {
 	Name        string
 	Type        PropertyType
+	Domain      string // Memory domain from a "domain:0xADDR" address; empty for the system bus
 	Address     uint32
 	Length      uint32
-	Position    *uint32 // for bit/nibble properties
-	Size        *uint32 // element size for arrays/structs
+	Bank        *uint32              // Bank holding the property in a bank-switched window
+	BankSelect  *memory.BankRegister // Register selecting Bank; nil unless the bank is register-selected
+	Position    *uint32              // for bit/nibble properties
+	Size        *uint32              // element size for arrays/structs
 	Endian      string
 	Description string
 	ReadOnly    bool
@@ -308,11 +313,18 @@ type Property struct {
 	Performance *PerformanceHints `json:"performance,omitempty"`
 	Debug       *DebugConfig      `json:"debug,omitempty"`
 
-	// Freezing support
+	// Freezing support. Frozen, FrozenData, FreezeOptions and the unexported
+	// freeze fields change at runtime and are guarded by the mapper's freezeMu.
 	Freezable     bool
 	DefaultFrozen bool
 	Frozen        bool
 	FrozenData    []byte
+	FreezeOptions *FreezeOptions // nil for a plain freeze
+	FreezeConfig  *FreezeOptions // Mapper's freeze options, used when a freeze does not choose a mode
+
+	freezeExpiresAt    time.Time // zero when the freeze has no duration
+	freezeExpiresFrame uint64    // zero when the freeze has no frame limit
+	freezeHeld         float64   // water mark of increment-only and decrement-only freezes
 
 	// Computed properties
 	DependsOn []string
@@ -355,19 +367,33 @@ type Platform struct {
 	Name          string
 	Endian        string
 	MemoryBlocks  []types.MemoryBlock
-	Constants     map[string]interface{} // Platform-specific constants
-	BaseAddresses map[string]string      // Named base addresses
-	Description   string                 `json:"description,omitempty"`
-	Version       string                 `json:"version,omitempty"`
-	Manufacturer  string                 `json:"manufacturer,omitempty"`
-	ReleaseYear   *uint                  `json:"release_year,omitempty"`
-	Capabilities  *PlatformCapabilities  `json:"capabilities,omitempty"`
-	Performance   *PlatformPerformance   `json:"performance,omitempty"`
+	Domains       map[string]*MemoryDomain // Named memory domains outside the system bus
+	BankedBlocks  []BankedBlock            // Memory blocks that are bank switched
+	Constants     map[string]interface{}   // Platform-specific constants
+	BaseAddresses map[string]string        // Named base addresses
+	Description   string                   `json:"description,omitempty"`
+	Version       string                   `json:"version,omitempty"`
+	Manufacturer  string                   `json:"manufacturer,omitempty"`
+	ReleaseYear   *uint                    `json:"release_year,omitempty"`
+	Capabilities  *PlatformCapabilities    `json:"capabilities,omitempty"`
+	Performance   *PlatformPerformance     `json:"performance,omitempty"`
+	ProcessMemory *ProcessMemoryLayout     `json:"process_memory,omitempty"`
+}
+
+// MemoryDomain describes a named memory domain such as save RAM.
+//
+// Drivers that address the domain natively (NWA, usb2snes) ignore BusAddress;
+// other drivers reach it on the system bus at BusAddress + offset.
+type MemoryDomain struct {
+	Name        string  `json:"name"`
+	BusAddress  *uint32 `json:"bus_address,omitempty"`
+	Description string  `json:"description,omitempty"`
 }
 
 // MemoryBlock represents enhanced memory block
 type MemoryBlock struct {
 	Name          string `json:"name"`
+	Domain        string `json:"domain,omitempty"`
 	Start         uint32 `json:"start"`
 	End           uint32 `json:"end"`
 	StartExpr     string `json:"start_expr,omitempty"`
@@ -379,6 +405,23 @@ type MemoryBlock struct {
 	AccessPattern string `json:"access_pattern,omitempty"` // "sequential", "random", "sparse"
 	Protected     *bool  `json:"protected,omitempty"`
 	Watchable     *bool  `json:"watchable,omitempty"`
+
+	Banking *BlockBanking `json:"banking,omitempty"`
+}
+
+// BlockBanking describes how a memory block is bank switched. Either Register
+// selects which bank is mapped into the block, or every bank is stored back to
+// back in Domain, BankSize bytes apart.
+type BlockBanking struct {
+	Register *memory.BankRegister `json:"register,omitempty"`
+	Domain   string               `json:"domain,omitempty"`
+	BankSize uint32               `json:"bank_size,omitempty"`
+}
+
+// BankedBlock is a platform memory block together with its banking
+type BankedBlock struct {
+	Block   types.MemoryBlock `json:"block"`
+	Banking *BlockBanking     `json:"banking"`
 }
 
 // PlatformCapabilities represents platform capabilities
@@ -397,6 +440,15 @@ type PlatformPerformance struct {
 	BatchSize    *uint `json:"batch_size,omitempty"`    // optimal batch size
 }
 
+// ProcessMemoryLayout describes where emulated RAM lives inside a native emulator process
+type ProcessMemoryLayout struct {
+	Module          string `json:"module,omitempty"`           // Mapping path substring to search
+	Signature       string `json:"signature,omitempty"`        // Hex bytes with ?? wildcards
+	SignatureOffset int64  `json:"signature_offset,omitempty"` // RAM base relative to the signature
+	Offset          int64  `json:"offset,omitempty"`           // RAM base relative to the module start
+	EmulatedStart   uint32 `json:"emulated_start"`             // Emulated address at the RAM base
+}
+
 // ===== EVENTS SYSTEM =====
 
 // EventsConfig represents the events configuration
@@ -470,6 +522,26 @@ type Mapper struct {
 	Events      *EventsConfig               // Events configuration
 	Validation  *GlobalValidation           // Global validation
 	Debug       *MapperDebugConfig          // Debug configuration
+
+	Identification *GameIdentification // How to recognise the running content
+
+	BankedWindows []memory.BankedWindow // Register-selected windows tracked per bank
+
+	conditions conditionCache // Compiled freeze and watchpoint conditions
+	freezeMu   sync.Mutex     // Guards the freeze state of every property
+}
+
+// GameIdentification declares the content a mapper targets for automatic detection
+type GameIdentification struct {
+	CRC32  []string        `json:"crc32,omitempty"`  // ROM CRC32s, lowercase hex
+	Titles []string        `json:"titles,omitempty"` // Internal header titles
+	Header *HeaderLocation `json:"header,omitempty"` // Overrides the platform's default header location
+}
+
+// HeaderLocation is where the internal title is read from emulated memory
+type HeaderLocation struct {
+	Address uint32 `json:"address"`
+	Length  uint32 `json:"length"`
 }
 
 // MapperMetadata represents mapper metadata
@@ -500,6 +572,7 @@ type MapperDebugConfig struct {
 type Loader struct {
 	mappersDir string
 	mappers    map[string]*Mapper
+	mu         sync.Mutex
 }
 
 // NewLoader creates a new enhanced mapper loader
@@ -542,6 +615,9 @@ func (l *Loader) List() []string {
 
 // Load loads a mapper by name
 func (l *Loader) Load(name string) (*Mapper, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	if mapper, exists := l.mappers[name]; exists {
 		return mapper, nil
 	}
@@ -565,6 +641,14 @@ func (l *Loader) Load(name string) (*Mapper, error) {
 	return mapper, nil
 }
 
+// Forget drops every cached mapper so the next Load reads its file again
+func (l *Loader) Forget() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.mappers = make(map[string]*Mapper)
+}
+
 // loadFromFile loads a mapper from a CUE file
 func (l *Loader) loadFromFile(filePath string) (*Mapper, error) {
 	log.Printf("🔍 Loading enhanced mapper from file: %s", filePath)
@@ -630,6 +714,11 @@ func (l *Loader) parseEnhancedMapper(value cue.Value) (*Mapper, error) {
 		return nil, fmt.Errorf("failed to parse platform: %w", err)
 	}
 
+	// Parse game identification
+	if err := l.parseIdentification(value, mapper); err != nil {
+		return nil, fmt.Errorf("failed to parse identification: %w", err)
+	}
+
 	// Parse constants
 	if err := l.parseConstants(value, mapper); err != nil {
 		return nil, fmt.Errorf("failed to parse constants: %w", err)
@@ -650,6 +739,11 @@ func (l *Loader) parseEnhancedMapper(value cue.Value) (*Mapper, error) {
 		return nil, fmt.Errorf("failed to parse properties: %w", err)
 	}
 
+	// Place banked properties in their bank-switched windows
+	if err := mapper.resolveBanking(); err != nil {
+		return nil, fmt.Errorf("failed to resolve banking: %w", err)
+	}
+
 	// Parse enhanced groups
 	if err := l.parseEnhancedPropertyGroups(value, mapper.Groups); err != nil {
 		return nil, fmt.Errorf("failed to parse groups: %w", err)
@@ -824,6 +918,11 @@ func (l *Loader) parseEnhancedPlatform(value cue.Value, mapper *Mapper) error {
 		}
 	}
 
+	// Parse named memory domains
+	if err := l.parseMemoryDomains(platformValue, &platform); err != nil {
+		return fmt.Errorf("failed to parse memory domains: %w", err)
+	}
+
 	// Parse enhanced memory blocks
 	if err := l.parseEnhancedMemoryBlocks(platformValue, &platform); err != nil {
 		return fmt.Errorf("failed to parse memory blocks: %w", err)
@@ -834,6 +933,11 @@ func (l *Loader) parseEnhancedPlatform(value cue.Value, mapper *Mapper) error {
 		return fmt.Errorf("failed to parse platform capabilities: %w", err)
 	}
 
+	// Parse host process memory layout
+	if err := l.parseProcessMemoryLayout(platformValue, &platform); err != nil {
+		return fmt.Errorf("failed to parse process memory layout: %w", err)
+	}
+
 	// Parse platform performance
 	if err := l.parsePlatformPerformance(platformValue, &platform); err != nil {
 		return fmt.Errorf("failed to parse platform performance: %w", err)
@@ -863,6 +967,13 @@ func (l *Loader) parseEnhancedMemoryBlocks(platformValue cue.Value, platform *Pl
 			block.Name = name
 		}
 
+		if domain, err := blockValue.LookupPath(cue.ParsePath("domain")).String(); err == nil {
+			if err := validateDomain(domain); err != nil {
+				return fmt.Errorf("memory block %s: %w", block.Name, err)
+			}
+			block.Domain = domain
+		}
+
 		if startStr, err := blockValue.LookupPath(cue.ParsePath("start")).String(); err == nil {
 			if start, err := parseAddress(startStr); err == nil {
 				block.Start = start
@@ -912,14 +1023,152 @@ func (l *Loader) parseEnhancedMemoryBlocks(platformValue cue.Value, platform *Pl
 			block.Watchable = &watchable
 		}
 
+		if bankingValue := blockValue.LookupPath(cue.ParsePath("banking")); bankingValue.Exists() {
+			banking, err := parseBlockBanking(bankingValue)
+			if err != nil {
+				return fmt.Errorf("memory block %s: %w", block.Name, err)
+			}
+			block.Banking = banking
+		}
+
 		// Convert to old MemoryBlock format for compatibility
 		oldBlock := types.MemoryBlock{ // ← Change from drivers.MemoryBlock to types.MemoryBlock
-			Name:  block.Name,
-			Start: block.Start,
-			End:   block.End,
+			Name:   block.Name,
+			Domain: block.Domain,
+			Start:  block.Start,
+			End:    block.End,
+		}
+		if block.Watchable != nil && !*block.Watchable {
+			oldBlock.Unwatchable = true
 		}
 
 		platform.MemoryBlocks = append(platform.MemoryBlocks, oldBlock)
+		if block.Banking != nil {
+			platform.BankedBlocks = append(platform.BankedBlocks, BankedBlock{Block: oldBlock, Banking: block.Banking})
+		}
+	}
+
+	return nil
+}
+
+// parseBlockBanking parses a memory block's banking configuration
+func parseBlockBanking(value cue.Value) (*BlockBanking, error) {
+	banking := &BlockBanking{}
+
+	if registerStr, err := value.LookupPath(cue.ParsePath("register")).String(); err == nil {
+		register, err := parseBankRegister(registerStr,
+			value.LookupPath(cue.ParsePath("mask")),
+			value.LookupPath(cue.ParsePath("zeroSelects")))
+		if err != nil {
+			return nil, err
+		}
+		banking.Register = register
+	}
+
+	if domain, err := value.LookupPath(cue.ParsePath("domain")).String(); err == nil {
+		if err := validateDomain(domain); err != nil {
+			return nil, err
+		}
+		banking.Domain = domain
+	}
+
+	if sizeStr, err := value.LookupPath(cue.ParsePath("bankSize")).String(); err == nil {
+		size, err := parseAddress(sizeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bank size %s: %w", sizeStr, err)
+		}
+		banking.BankSize = size
+	}
+
+	switch {
+	case banking.Register != nil && banking.Domain != "":
+		return nil, fmt.Errorf("banking declares both a register and a domain")
+	case banking.Register == nil && banking.Domain == "":
+		return nil, fmt.Errorf("banking needs a register or a domain")
+	case banking.Domain != "" && banking.BankSize == 0:
+		return nil, fmt.Errorf("banking through domain %s needs bankSize", banking.Domain)
+	}
+
+	return banking, nil
+}
+
+// parseBankRegister parses a bank-select register address with its optional
+// mask (default 0xFF) and the bank a zero register value selects
+func parseBankRegister(registerStr string, maskValue cue.Value, zeroValue cue.Value) (*memory.BankRegister, error) {
+	domain, addressStr := splitDomainAddress(registerStr)
+	address, err := parseAddress(addressStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bank register %s: %w", registerStr, err)
+	}
+
+	register := &memory.BankRegister{Domain: domain, Address: address, Mask: 0xFF}
+	if mask, err := maskValue.Uint64(); err == nil {
+		if mask == 0 || mask > 0xFF {
+			return nil, fmt.Errorf("bank mask %d must be between 1 and 255", mask)
+		}
+		register.Mask = uint8(mask)
+	}
+	if zeroSelects, err := zeroValue.Uint64(); err == nil {
+		register.ZeroSelects = uint32(zeroSelects)
+	}
+
+	return register, nil
+}
+
+// parseFreezeOptions parses a property's freeze options from the mapper
+func parseFreezeOptions(value cue.Value) (*FreezeOptions, error) {
+	options := &FreezeOptions{Source: "mapper"}
+
+	if mode, err := value.LookupPath(cue.ParsePath("mode")).String(); err == nil {
+		options.Mode = FreezeMode(mode)
+	}
+	if min, err := value.LookupPath(cue.ParsePath("min")).Float64(); err == nil {
+		options.Min = &min
+	}
+	if max, err := value.LookupPath(cue.ParsePath("max")).Float64(); err == nil {
+		options.Max = &max
+	}
+	if condition, err := value.LookupPath(cue.ParsePath("condition")).String(); err == nil {
+		options.Condition = condition
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// parseMemoryDomains parses platform.domains
+func (l *Loader) parseMemoryDomains(platformValue cue.Value, platform *Platform) error {
+	domainsValue := platformValue.LookupPath(cue.ParsePath("domains"))
+	if !domainsValue.Exists() {
+		return nil
+	}
+
+	platform.Domains = make(map[string]*MemoryDomain)
+	fields, _ := domainsValue.Fields()
+	for fields.Next() {
+		name := fields.Label()
+		if err := validateDomain(name); err != nil {
+			return err
+		}
+
+		domain := &MemoryDomain{Name: name}
+		domainValue := fields.Value()
+
+		if busStr, err := domainValue.LookupPath(cue.ParsePath("busAddress")).String(); err == nil {
+			busAddress, err := parseAddress(busStr)
+			if err != nil {
+				return fmt.Errorf("invalid bus address %s for domain %s: %w", busStr, name, err)
+			}
+			domain.BusAddress = &busAddress
+		}
+
+		if description, err := domainValue.LookupPath(cue.ParsePath("description")).String(); err == nil {
+			domain.Description = description
+		}
+
+		platform.Domains[name] = domain
 	}
 
 	return nil
@@ -989,6 +1238,109 @@ func (l *Loader) parsePlatformPerformance(platformValue cue.Value, platform *Pla
 	return nil
 }
 
+// parseProcessMemoryLayout parses the host process layout used by the process driver
+func (l *Loader) parseProcessMemoryLayout(platformValue cue.Value, platform *Platform) error {
+	layoutValue := platformValue.LookupPath(cue.ParsePath("processMemory"))
+	if !layoutValue.Exists() {
+		return nil
+	}
+
+	layout := &ProcessMemoryLayout{}
+
+	if module, err := layoutValue.LookupPath(cue.ParsePath("module")).String(); err == nil {
+		layout.Module = module
+	}
+
+	if signature, err := layoutValue.LookupPath(cue.ParsePath("signature")).String(); err == nil {
+		layout.Signature = signature
+	}
+
+	if signatureOffset, err := layoutValue.LookupPath(cue.ParsePath("signatureOffset")).Int64(); err == nil {
+		layout.SignatureOffset = signatureOffset
+	}
+
+	if offset, err := layoutValue.LookupPath(cue.ParsePath("offset")).Int64(); err == nil {
+		layout.Offset = offset
+	}
+
+	if len(platform.MemoryBlocks) > 0 {
+		layout.EmulatedStart = platform.MemoryBlocks[0].Start
+	}
+
+	if startStr, err := layoutValue.LookupPath(cue.ParsePath("emulatedStart")).String(); err == nil {
+		start, err := parseAddress(startStr)
+		if err != nil {
+			return fmt.Errorf("invalid emulatedStart: %w", err)
+		}
+		layout.EmulatedStart = start
+	}
+
+	if layout.Signature == "" && layout.Module == "" {
+		return fmt.Errorf("processMemory requires a signature or a module")
+	}
+
+	platform.ProcessMemory = layout
+	return nil
+}
+
+// parseIdentification parses the hashes and header titles used for game detection
+func (l *Loader) parseIdentification(value cue.Value, mapper *Mapper) error {
+	idValue := value.LookupPath(cue.ParsePath("identification"))
+	if !idValue.Exists() {
+		return nil
+	}
+
+	identification := &GameIdentification{
+		CRC32:  parseStringList(idValue.LookupPath(cue.ParsePath("crc32"))),
+		Titles: parseStringList(idValue.LookupPath(cue.ParsePath("titles"))),
+	}
+
+	for i, crc := range identification.CRC32 {
+		identification.CRC32[i] = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(crc, "0x"), "0X"))
+	}
+
+	headerValue := idValue.LookupPath(cue.ParsePath("header"))
+	if headerValue.Exists() {
+		header := &HeaderLocation{}
+		addressStr, err := headerValue.LookupPath(cue.ParsePath("address")).String()
+		if err != nil {
+			return fmt.Errorf("header requires an address")
+		}
+		if header.Address, err = parseAddress(addressStr); err != nil {
+			return fmt.Errorf("invalid header address: %w", err)
+		}
+		length, err := headerValue.LookupPath(cue.ParsePath("length")).Uint64()
+		if err != nil || length == 0 {
+			return fmt.Errorf("header requires a non-zero length")
+		}
+		header.Length = uint32(length)
+		identification.Header = header
+	}
+
+	if len(identification.CRC32) == 0 && len(identification.Titles) == 0 {
+		return fmt.Errorf("identification requires crc32 or titles")
+	}
+
+	mapper.Identification = identification
+	return nil
+}
+
+// parseStringList parses a CUE list of strings, ignoring missing values
+func parseStringList(listValue cue.Value) []string {
+	var values []string
+	if !listValue.Exists() {
+		return values
+	}
+	if list, err := listValue.List(); err == nil {
+		for list.Next() {
+			if str, err := list.Value().String(); err == nil {
+				values = append(values, str)
+			}
+		}
+	}
+	return values
+}
+
 // parseConstants parses constants from CUE value
 func (l *Loader) parseConstants(value cue.Value, mapper *Mapper) error {
 	constantsValue := value.LookupPath(cue.ParsePath("constants"))
@@ -1114,7 +1466,7 @@ func (l *Loader) parseEnhancedPropertiesWithDebug(value cue.Value, properties ma
 		propertyCount++
 
 		log.Printf("   ✅ Property %s: type=%s, address=%s",
-			label, property.Type, fmt.Sprintf("0x%X", property.Address))
+			label, property.Type, memory.FormatAddress(property.Domain, property.Address))
 	}
 
 	log.Printf("📊 Total enhanced properties parsed: %d", propertyCount)
@@ -1180,6 +1532,14 @@ func (l *Loader) parseBasicPropertyFields(value cue.Value, property *Property) e
 	}
 
 	if addressStr, err := value.LookupPath(cue.ParsePath("address")).String(); err == nil {
+		domain, addressStr := splitDomainAddress(addressStr)
+		if domain != "" {
+			if err := validateDomain(domain); err != nil {
+				return err
+			}
+			property.Domain = domain
+		}
+
 		if address, err := parseAddress(addressStr); err == nil {
 			property.Address = address
 		} else {
@@ -1194,6 +1554,21 @@ func (l *Loader) parseBasicPropertyFields(value cue.Value, property *Property) e
 		property.Length = 1
 	}
 
+	if bank, err := value.LookupPath(cue.ParsePath("bank")).Uint64(); err == nil {
+		b := uint32(bank)
+		property.Bank = &b
+
+		if registerStr, err := value.LookupPath(cue.ParsePath("bankRegister")).String(); err == nil {
+			register, err := parseBankRegister(registerStr,
+				value.LookupPath(cue.ParsePath("bankMask")),
+				value.LookupPath(cue.ParsePath("bankZeroSelects")))
+			if err != nil {
+				return err
+			}
+			property.BankSelect = register
+		}
+	}
+
 	if position, err := value.LookupPath(cue.ParsePath("position")).Uint64(); err == nil {
 		pos := uint32(position)
 		property.Position = &pos
@@ -1224,6 +1599,14 @@ func (l *Loader) parseBasicPropertyFields(value cue.Value, property *Property) e
 		property.DefaultFrozen = defaultFrozen
 	}
 
+	if freezeValue := value.LookupPath(cue.ParsePath("freeze")); freezeValue.Exists() {
+		options, err := parseFreezeOptions(freezeValue)
+		if err != nil {
+			return fmt.Errorf("invalid freeze options: %w", err)
+		}
+		property.FreezeConfig = options
+	}
+
 	if readExpr, err := value.LookupPath(cue.ParsePath("readExpression")).String(); err == nil {
 		property.ReadExpression = readExpr
 	}
@@ -2649,10 +3032,48 @@ func parseAddress(addressStr string) (uint32, error) {
 	return uint32(value), err
 }
 
+// splitDomainAddress splits "sram:0xA598" into its domain and address parts.
+// Addresses without a domain prefix return an empty domain.
+func splitDomainAddress(addressStr string) (string, string) {
+	if i := strings.Index(addressStr, ":"); i >= 0 {
+		return strings.TrimSpace(addressStr[:i]), strings.TrimSpace(addressStr[i+1:])
+	}
+	return "", addressStr
+}
+
+// validateDomain rejects domain names that cannot be used as memory namespaces
+func validateDomain(domain string) error {
+	if domain == "" {
+		return fmt.Errorf("memory domain name cannot be empty")
+	}
+	if domain == "default" {
+		return fmt.Errorf("memory domain name %q is reserved for the system bus", domain)
+	}
+	return nil
+}
+
+// DomainBusAddresses returns the bus address of each domain that declares one,
+// for drivers that cannot address the domain natively
+func (m *Mapper) DomainBusAddresses() map[string]uint32 {
+	busAddresses := make(map[string]uint32)
+	for name, domain := range m.Platform.Domains {
+		if domain.BusAddress != nil {
+			busAddresses[name] = *domain.BusAddress
+		}
+	}
+	return busAddresses
+}
+
 // ===== ENHANCED PROPERTY OPERATIONS =====
 
 // FreezeProperty freezes a property at its current value
 func (m *Mapper) FreezeProperty(name string, memManager *memory.Manager) error {
+	return m.FreezePropertyWithOptions(name, memManager, nil)
+}
+
+// FreezePropertyWithOptions freezes a property at its current value, optionally
+// only while a condition holds or until it expires
+func (m *Mapper) FreezePropertyWithOptions(name string, memManager *memory.Manager, options *FreezeOptions) error {
 	prop, exists := m.Properties[name]
 	if !exists {
 		return fmt.Errorf("property %s not found", name)
@@ -2662,21 +3083,89 @@ func (m *Mapper) FreezeProperty(name string, memManager *memory.Manager) error {
 		return fmt.Errorf("property %s is not freezable", name)
 	}
 
+	// A banked property can only be captured while its bank is mapped in
+	if err := m.CheckBankMapped(prop, memManager); err != nil {
+		return fmt.Errorf("cannot freeze %s: %w", name, err)
+	}
+
 	// Get current value as bytes
-	data, err := memManager.ReadBytes(prop.Address, prop.Length)
+	snap := memManager.Snapshot()
+	data, err := m.readPropertyBytes(prop, snap)
 	if err != nil {
 		return fmt.Errorf("failed to read current value: %w", err)
 	}
 
-	// Freeze in memory manager
-	if err := memManager.FreezeProperty(prop.Address, data); err != nil {
-		return err
+	options = options.withDefaults(prop.FreezeConfig)
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Bounded modes need a decodable value to start from
+	var held float64
+	if options.Bounded() {
+		if held, err = m.decodeNumber(prop, data); err != nil {
+			return fmt.Errorf("freeze mode %s is not supported for %s: %w", options.Mode, name, err)
+		}
+	}
+
+	var expiresAt time.Time
+	var expiresFrame uint64
+	managerOptions := make(map[string]interface{})
+	if options != nil {
+		if options.Mode != "" {
+			managerOptions["mode"] = string(options.Mode)
+		}
+		// Reject conditions that do not compile or reference unknown properties up front
+		if options.Condition != "" {
+			if _, err := m.EvaluateCondition(options.Condition, memManager, snap); err != nil {
+				return fmt.Errorf("invalid freeze condition %q: %w", options.Condition, err)
+			}
+			managerOptions["condition"] = options.Condition
+		}
+		if options.Duration > 0 {
+			expiresAt = time.Now().Add(options.Duration)
+			managerOptions["expiry"] = expiresAt
+		}
+		if options.Frames > 0 {
+			expiresFrame = snap.Frame + options.Frames
+			managerOptions["expiry_frame"] = expiresFrame
+		}
+		if options.Source != "" {
+			managerOptions["source"] = options.Source
+		}
+	}
+
+	// Freeze in memory manager; domain and banked properties are only written back to the driver
+	if prop.Domain == "" && prop.BankSelect == nil {
+		if err := memManager.FreezePropertyWithOptions(prop.Address, data, managerOptions); err != nil {
+			return err
+		}
 	}
 
 	// Update property state
+	m.freezeMu.Lock()
 	prop.Frozen = true
 	prop.FrozenData = make([]byte, len(data))
 	copy(prop.FrozenData, data)
+	prop.FreezeOptions = options
+	prop.freezeExpiresAt = expiresAt
+	prop.freezeExpiresFrame = expiresFrame
+	prop.freezeHeld = held
+	m.freezeMu.Unlock()
+
+	enforcement := memory.FreezeEnforcement{ExpiresAtFrame: expiresFrame}
+	if options != nil {
+		enforcement.Condition = options.Condition
+		enforcement.Mode = string(options.Mode)
+		enforcement.Min = options.Min
+		enforcement.Max = options.Max
+	}
+	if !expiresAt.IsZero() {
+		enforcement.ExpiresAt = &expiresAt
+	}
+	memManager.StartFreezeEnforcement(name, prop.Address, enforcement)
 
 	return nil
 }
@@ -2688,32 +3177,47 @@ func (m *Mapper) UnfreezeProperty(name string, memManager *memory.Manager) error
 		return fmt.Errorf("property %s not found", name)
 	}
 
-	if err := memManager.UnfreezeProperty(prop.Address); err != nil {
-		return err
+	if prop.Domain == "" && prop.BankSelect == nil {
+		if err := memManager.UnfreezeProperty(prop.Address); err != nil {
+			return err
+		}
 	}
 
+	m.freezeMu.Lock()
 	prop.Frozen = false
 	prop.FrozenData = nil
+	prop.FreezeOptions = nil
+	prop.freezeExpiresAt = time.Time{}
+	prop.freezeExpiresFrame = 0
+	prop.freezeHeld = 0
+	m.freezeMu.Unlock()
+	memManager.StopFreezeEnforcement(name)
 
 	return nil
 }
 
 // GetProperty gets a property value from memory with enhanced processing
 func (m *Mapper) GetProperty(name string, memManager *memory.Manager) (interface{}, error) {
+	return m.GetPropertyAt(name, memManager, memManager.Snapshot())
+}
+
+// GetPropertyAt gets a property value from a memory snapshot. Properties
+// evaluated against the same snapshot always see the same bytes.
+func (m *Mapper) GetPropertyAt(name string, memManager *memory.Manager, snap *memory.Snapshot) (interface{}, error) {
 	prop, exists := m.Properties[name]
 	if !exists {
 		return nil, fmt.Errorf("property %s not found", name)
 	}
 
 	if prop.Computed != nil {
-		return m.evaluateComputedProperty(prop, memManager)
+		return m.evaluateComputedProperty(prop, memManager, snap)
 	}
 
 	// Determine endianness once
 	littleEndian := prop.Endian == "little" || (prop.Endian == "" && m.Platform.Endian == "little")
 
 	// Read the raw bytes first
-	rawBytes, err := memManager.ReadBytes(prop.Address, prop.Length)
+	rawBytes, err := m.readPropertyBytes(prop, snap)
 	if err != nil {
 		// Return reasonable defaults instead of failing completely
 		return m.getDefaultValue(prop.Type), nil
@@ -3138,7 +3642,7 @@ func (m *Mapper) parseElementFromBytes(data []byte, elementType PropertyType, li
 }
 
 // evaluateComputedProperty evaluates a computed property
-func (m *Mapper) evaluateComputedProperty(prop *Property, memManager *memory.Manager) (interface{}, error) {
+func (m *Mapper) evaluateComputedProperty(prop *Property, memManager *memory.Manager, snap *memory.Snapshot) (interface{}, error) {
 	if prop.Computed == nil {
 		return nil, fmt.Errorf("property is not computed")
 	}
@@ -3146,7 +3650,7 @@ func (m *Mapper) evaluateComputedProperty(prop *Property, memManager *memory.Man
 	// Get dependency values
 	context := make(map[string]interface{})
 	for _, dep := range prop.Computed.Dependencies {
-		if value, err := m.GetProperty(dep, memManager); err == nil {
+		if value, err := m.GetPropertyAt(dep, memManager, snap); err == nil {
 			context[dep] = value
 		}
 	}
@@ -3283,38 +3787,34 @@ func (m *Mapper) SetProperty(name string, value interface{}, memManager *memory.
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Convert value to bytes based on type
-	var data []byte
+	// Convert value to bytes through the property's encoder
+	number, ok := numberFromValue(value)
+	if !ok {
+		return fmt.Errorf("invalid type for %s property", prop.Type)
+	}
 
-	switch prop.Type {
-	case PropertyTypeUint8:
-		if val, ok := value.(uint8); ok {
-			data = []byte{val}
-		} else if val, ok := value.(float64); ok {
-			data = []byte{uint8(val)}
-		} else if val, ok := value.(uint32); ok {
-			data = []byte{uint8(val)}
-		} else {
-			return fmt.Errorf("invalid type for uint8 property")
-		}
-	case PropertyTypeBool:
-		if val, ok := value.(bool); ok {
-			if val {
-				data = []byte{1}
-			} else {
-				data = []byte{0}
-			}
-		} else {
-			return fmt.Errorf("invalid type for bool property")
+	// Bits and nibbles share their byte, so the rest of it comes from memory
+	current, err := m.readPropertyBytes(prop, memManager.Snapshot())
+	if err != nil {
+		if prop.Position != nil {
+			return fmt.Errorf("failed to read current value: %w", err)
 		}
-	// Add more types as needed
-	default:
-		return fmt.Errorf("setting values for type %s not yet implemented", prop.Type)
+		current = make([]byte, propertyExtent(prop))
+	}
+
+	data, err := m.encodeNumber(prop, number, current)
+	if err != nil {
+		return err
+	}
+
+	if err := m.CheckBankMapped(prop, memManager); err != nil {
+		return err
 	}
 
 	// Write to emulator if not frozen
-	if !prop.Frozen {
-		if err := driver.WriteBytes(prop.Address, data); err != nil {
+	if !m.IsFrozen(name) {
+		router := drivers.NewDomainRouter(driver, m.DomainBusAddresses())
+		if err := router.WriteBytes(prop.Domain, prop.Address, data); err != nil {
 			return err
 		}
 	}
@@ -3324,8 +3824,9 @@ func (m *Mapper) SetProperty(name string, value interface{}, memManager *memory.
 
 // ProcessProperties processes all properties and updates their values
 func (m *Mapper) ProcessProperties(memManager *memory.Manager) error {
+	snap := memManager.Snapshot()
 	for name := range m.Properties {
-		_, err := m.GetProperty(name, memManager)
+		_, err := m.GetPropertyAt(name, memManager, snap)
 		if err != nil {
 			return fmt.Errorf("failed to process property %s: %w", name, err)
 		}
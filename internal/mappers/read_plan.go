@@ -0,0 +1,398 @@
+package mappers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"gamehook/internal/memory"
+	"gamehook/internal/types"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== READ PLANNING =====
+
+// ReadRange is one contiguous span fetched from the driver each tick
+type ReadRange struct {
+	Block      string   `json:"block"`
+	Domain     string   `json:"domain,omitempty"`
+	Start      uint32   `json:"start"`
+	End        uint32   `json:"end"` // inclusive
+	Size       uint32   `json:"size"`
+	Properties []string `json:"properties"`
+}
+
+// ReadPlan is the minimal set of ranges needed to evaluate a mapper's properties.
+//
+// Ranges never cross platform memory block boundaries, and ranges in the same
+// block are merged when the gap between them is at most Gap bytes.
+type ReadPlan struct {
+	Gap            uint32            `json:"gap"`
+	Ranges         []ReadRange       `json:"ranges"`
+	PlannedBytes   uint32            `json:"planned_bytes"`
+	FullBytes      uint32            `json:"full_bytes"`
+	PointerTargets map[string]uint32 `json:"pointer_targets,omitempty"`
+	Unplanned      []string          `json:"unplanned,omitempty"` // Properties outside every memory block
+	CreatedAt      time.Time         `json:"created_at"`
+
+	blocks      []types.MemoryBlock
+	spans       []planSpan // Static property extents
+	targetSpans []planSpan // Pointer target extents from the last refresh
+	pointers    []*Property
+}
+
+// planSpan is a property's byte extent before clipping and merging
+type planSpan struct {
+	domain   string
+	start    uint32
+	end      uint32 // inclusive
+	property string
+}
+
+// BuildReadPlan computes the read plan for the mapper's properties.
+// Pointer targets are added by RefreshReadPlan once pointer values are known.
+func (m *Mapper) BuildReadPlan(gap uint32) *ReadPlan {
+	var spans []planSpan
+	var pointers []*Property
+
+	for name, prop := range m.Properties {
+		if prop.Computed != nil {
+			continue
+		}
+
+		size := propertyExtent(prop)
+		if size == 0 {
+			continue
+		}
+		spans = append(spans, planSpan{domain: prop.Domain, start: prop.Address, end: prop.Address + size - 1, property: name})
+
+		// The bank register decides which bank the property's bytes belong to
+		if register := prop.BankSelect; register != nil {
+			spans = append(spans, planSpan{domain: register.Domain, start: register.Address, end: register.Address, property: name})
+		}
+
+		if prop.Type == PropertyTypePointer {
+			pointers = append(pointers, prop)
+		}
+	}
+
+	return newReadPlan(m.Platform.MemoryBlocks, gap, spans, nil, pointers, nil)
+}
+
+// RefreshReadPlan follows pointer properties through the current memory
+// contents and returns a new plan when any target moved. It returns plan
+// unchanged when targets are stable or the mapper has no pointers.
+func (m *Mapper) RefreshReadPlan(plan *ReadPlan, memManager *memory.Manager) *ReadPlan {
+	if plan == nil || len(plan.pointers) == 0 {
+		return plan
+	}
+
+	littleEndian := m.Platform.Endian == "little"
+	targets := make(map[string]uint32)
+	var targetSpans []planSpan
+
+	for _, prop := range plan.pointers {
+		raw, err := memManager.ReadDomainBytes(prop.Domain, prop.Address, 4)
+		if err != nil {
+			continue
+		}
+		pointer := binary.BigEndian.Uint32(raw)
+		if littleEndian {
+			pointer = binary.LittleEndian.Uint32(raw)
+		}
+
+		nullValue := uint32(0)
+		if prop.Advanced != nil && prop.Advanced.NullValue != nil {
+			nullValue = *prop.Advanced.NullValue
+		}
+		if pointer == nullValue {
+			continue
+		}
+		targets[prop.Name] = pointer
+
+		targetType := PropertyTypeUint32
+		maxDeref := uint(1)
+		if prop.Advanced != nil {
+			if prop.Advanced.TargetType != nil {
+				targetType = *prop.Advanced.TargetType
+			}
+			if prop.Advanced.MaxDereferences != nil {
+				maxDeref = *prop.Advanced.MaxDereferences
+			}
+		}
+
+		// Pointers hold system bus addresses, so targets are planned in the default
+		// domain. Chains are followed as far as the data read so far allows; deeper
+		// levels are planned on later ticks once their parents are read
+		address := pointer
+		for level := uint(1); level <= maxDeref; level++ {
+			size := targetSize(targetType)
+			if targetType == PropertyTypePointer && level < maxDeref {
+				size = 4
+			}
+			targetSpans = append(targetSpans, planSpan{start: address, end: address + size - 1, property: prop.Name})
+
+			if targetType != PropertyTypePointer || level == maxDeref {
+				break
+			}
+			next, err := memManager.ReadUint32(address, littleEndian)
+			if err != nil {
+				break
+			}
+			address = next
+			targetType = PropertyTypeUint32
+		}
+	}
+
+	if sameSpans(plan.targetSpans, targetSpans) {
+		return plan
+	}
+
+	return newReadPlan(plan.blocks, plan.Gap, plan.spans, targetSpans, plan.pointers, targets)
+}
+
+// watchSpanPrefix marks the plan spans that come from watchpoints rather than properties
+const watchSpanPrefix = "watch:"
+
+// WithWatchpoints returns a plan that also reads the ranges of watchpoints,
+// replacing the watched ranges of plan
+func (p *ReadPlan) WithWatchpoints(watchpoints []memory.Watchpoint) *ReadPlan {
+	spans := make([]planSpan, 0, len(p.spans)+len(watchpoints))
+	for _, span := range p.spans {
+		if !strings.HasPrefix(span.property, watchSpanPrefix) {
+			spans = append(spans, span)
+		}
+	}
+	for _, wp := range watchpoints {
+		spans = append(spans, planSpan{
+			domain:   wp.Domain,
+			start:    wp.Address,
+			end:      wp.Address + wp.Length - 1,
+			property: watchSpanPrefix + wp.ID,
+		})
+	}
+
+	return newReadPlan(p.blocks, p.Gap, spans, p.targetSpans, p.pointers, p.PointerTargets)
+}
+
+// Blocks returns the plan's ranges as memory blocks for ReadMemoryBlocks.
+// Each carries the name and start of the declared block it lies within.
+func (p *ReadPlan) Blocks() []types.MemoryBlock {
+	blocks := make([]types.MemoryBlock, 0, len(p.Ranges))
+	for _, r := range p.Ranges {
+		block := types.MemoryBlock{
+			Name:   fmt.Sprintf("%s@0x%X", r.Block, r.Start),
+			Domain: r.Domain,
+			Start:  r.Start,
+			End:    r.End,
+			Parent: r.Block,
+		}
+		for _, parent := range p.blocks {
+			if parent.Domain == r.Domain && parent.Start <= r.Start && r.End <= parent.End {
+				block.ParentStart = parent.Start
+				break
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// Lengths returns the planned ranges in domain as start address → length
+func (p *ReadPlan) Lengths(domain string) map[uint32]uint32 {
+	lengths := make(map[uint32]uint32, len(p.Ranges))
+	for _, r := range p.Ranges {
+		if r.Domain == domain {
+			lengths[r.Start] = r.Size
+		}
+	}
+	return lengths
+}
+
+// Domains returns the memory domains the plan reads, system bus first
+func (p *ReadPlan) Domains() []string {
+	domains := []string{""}
+	for _, block := range p.blocks {
+		domains = appendUnique(domains, block.Domain)
+	}
+	sort.Strings(domains[1:])
+	return domains
+}
+
+// newReadPlan clips spans to memory blocks and merges them within each block
+func newReadPlan(blocks []types.MemoryBlock, gap uint32, static, targets []planSpan, pointers []*Property, pointerTargets map[string]uint32) *ReadPlan {
+	plan := &ReadPlan{
+		Gap:            gap,
+		PointerTargets: pointerTargets,
+		CreatedAt:      time.Now(),
+		blocks:         blocks,
+		spans:          static,
+		targetSpans:    targets,
+		pointers:       pointers,
+	}
+
+	spans := make([]planSpan, 0, len(static)+len(targets))
+	spans = append(spans, static...)
+	spans = append(spans, targets...)
+
+	for _, block := range blocks {
+		plan.FullBytes += block.End - block.Start + 1
+	}
+
+	covered := make(map[string]bool)
+	for _, block := range blocks {
+		var clipped []planSpan
+		for _, span := range spans {
+			if span.domain != block.Domain || span.end < block.Start || span.start > block.End {
+				continue
+			}
+			if span.start < block.Start {
+				span.start = block.Start
+			}
+			if span.end > block.End {
+				span.end = block.End
+			}
+			clipped = append(clipped, span)
+			covered[span.property] = true
+		}
+
+		sort.Slice(clipped, func(i, j int) bool {
+			return clipped[i].start < clipped[j].start
+		})
+
+		var current *ReadRange
+		for _, span := range clipped {
+			if current != nil && uint64(span.start) <= uint64(current.End)+uint64(gap)+1 {
+				if span.end > current.End {
+					current.End = span.end
+				}
+				current.Properties = appendUnique(current.Properties, span.property)
+				continue
+			}
+
+			plan.Ranges = append(plan.Ranges, ReadRange{
+				Block:      block.Name,
+				Domain:     block.Domain,
+				Start:      span.start,
+				End:        span.end,
+				Properties: []string{span.property},
+			})
+			current = &plan.Ranges[len(plan.Ranges)-1]
+		}
+	}
+
+	for i := range plan.Ranges {
+		r := &plan.Ranges[i]
+		r.Size = r.End - r.Start + 1
+		sort.Strings(r.Properties)
+		plan.PlannedBytes += r.Size
+	}
+
+	for _, span := range spans {
+		if !covered[span.property] {
+			plan.Unplanned = appendUnique(plan.Unplanned, span.property)
+		}
+	}
+	sort.Strings(plan.Unplanned)
+
+	return plan
+}
+
+// propertyExtent returns how many bytes from prop.Address a property may touch
+func propertyExtent(prop *Property) uint32 {
+	size := prop.Length
+	if size == 0 {
+		size = targetSize(prop.Type)
+	}
+
+	switch prop.Type {
+	case PropertyTypePointer:
+		if size < 4 {
+			size = 4
+		}
+	case PropertyTypeArray:
+		if prop.Advanced == nil {
+			break
+		}
+		elementSize := uint32(1)
+		if prop.Advanced.ElementSize != nil {
+			elementSize = uint32(*prop.Advanced.ElementSize)
+		}
+		stride := elementSize
+		if prop.Advanced.Stride != nil {
+			stride = uint32(*prop.Advanced.Stride)
+		}
+		indexOffset := uint32(0)
+		if prop.Advanced.IndexOffset != nil {
+			indexOffset = uint32(*prop.Advanced.IndexOffset)
+		}
+
+		// Length may be an element count for the advanced processor
+		count := prop.Length
+		if prop.Advanced.MaxElements != nil {
+			maxElements := uint32(*prop.Advanced.MaxElements)
+			if count > maxElements || (prop.Advanced.DynamicLength != nil && *prop.Advanced.DynamicLength) {
+				count = maxElements
+			}
+		}
+		if count > 0 {
+			if extent := indexOffset + (count-1)*stride + elementSize; extent > size {
+				size = extent
+			}
+		}
+	case PropertyTypeStruct:
+		if prop.Advanced == nil {
+			break
+		}
+		for _, field := range prop.Advanced.Fields {
+			fieldSize := uint32(1)
+			if field.Size != nil {
+				fieldSize = uint32(*field.Size)
+			}
+			if extent := uint32(field.Offset) + fieldSize; extent > size {
+				size = extent
+			}
+		}
+	}
+
+	return size
+}
+
+// targetSize returns the byte size read for a pointer target type
+func targetSize(propType PropertyType) uint32 {
+	switch propType {
+	case PropertyTypeUint8, PropertyTypeInt8, PropertyTypeBool, PropertyTypeBit, PropertyTypeNibble:
+		return 1
+	case PropertyTypeUint16, PropertyTypeInt16:
+		return 2
+	case PropertyTypeFloat64:
+		return 8
+	case PropertyTypeString:
+		return 256 // Matches the advanced processor's default string length
+	default:
+		return 4
+	}
+}
+
+// sameSpans reports whether two span lists are identical
+func sameSpans(a, b []planSpan) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// appendUnique appends value unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,239 @@
+package mappers
+
+import (
+	"encoding/binary"
+	"gamehook/internal/drivers"
+	"gamehook/internal/memory"
+	"gamehook/internal/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlannedReadFromDumpDirectory(t *testing.T) {
+	dir := t.TempDir()
+	wram := make([]byte, 0x2000)
+	for i := range wram {
+		wram[i] = byte(i*7 + 3)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "wram.bin"), wram, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mapper := &Mapper{
+		Platform: Platform{
+			Endian:       "little",
+			MemoryBlocks: []types.MemoryBlock{{Name: "wram", Start: 0xC000, End: 0xDFFF}},
+		},
+		Properties: map[string]*Property{
+			"hp":    {Name: "hp", Type: PropertyTypeUint8, Address: 0xD158, Length: 1},
+			"money": {Name: "money", Type: PropertyTypeUint16, Address: 0xD347, Length: 2, Endian: "little"},
+		},
+	}
+
+	plan := mapper.BuildReadPlan(16)
+	if len(plan.Ranges) != 2 {
+		t.Fatalf("expected 2 planned ranges, got %+v", plan.Ranges)
+	}
+
+	driver := drivers.NewFileDriver(dir, 0, false)
+	defer driver.Close()
+
+	data, err := driver.ReadMemoryBlocks(plan.Blocks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range plan.Ranges {
+		got := data[r.Start]
+		want := wram[r.Start-0xC000 : r.End-0xC000+1]
+		if string(got) != string(want) {
+			t.Errorf("range 0x%X-0x%X read % X, want % X", r.Start, r.End, got, want)
+		}
+	}
+
+	manager := memory.NewManager()
+	manager.Update(data)
+
+	hp, err := mapper.GetPropertyAt("hp", manager, manager.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := uint8(wram[0x1158]); hp != want {
+		t.Errorf("hp = %v, want %v", hp, want)
+	}
+
+	money, err := mapper.GetPropertyAt("money", manager, manager.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := uint16(wram[0x1347]) | uint16(wram[0x1348])<<8; money != want {
+		t.Errorf("money = %v, want %v", money, want)
+	}
+}
+
+func uintPtr(value uint) *uint {
+	return &value
+}
+
+// planRanges summarizes a plan's ranges as start-end pairs
+func planRanges(plan *ReadPlan) [][2]uint32 {
+	ranges := make([][2]uint32, 0, len(plan.Ranges))
+	for _, r := range plan.Ranges {
+		ranges = append(ranges, [2]uint32{r.Start, r.End})
+	}
+	return ranges
+}
+
+func TestReadPlanMergesGapsWithinBlocks(t *testing.T) {
+	mapper := &Mapper{
+		Platform: Platform{
+			Endian: "little",
+			MemoryBlocks: []types.MemoryBlock{
+				{Name: "wram0", Start: 0xC000, End: 0xCFFF},
+				{Name: "wram1", Start: 0xD000, End: 0xDFFF},
+			},
+		},
+	}
+	plan := func(properties ...*Property) *ReadPlan {
+		mapper.Properties = make(map[string]*Property)
+		for _, prop := range properties {
+			mapper.Properties[prop.Name] = prop
+		}
+		return mapper.BuildReadPlan(32)
+	}
+	uint8At := func(name string, address uint32) *Property {
+		return &Property{Name: name, Type: PropertyTypeUint8, Address: address, Length: 1}
+	}
+
+	tests := []struct {
+		name       string
+		properties []*Property
+		want       [][2]uint32
+	}{
+		{"gap of exactly 32 bytes merges", []*Property{uint8At("a", 0xC000), uint8At("b", 0xC021)},
+			[][2]uint32{{0xC000, 0xC021}}},
+		{"gap of 33 bytes splits", []*Property{uint8At("a", 0xC000), uint8At("b", 0xC022)},
+			[][2]uint32{{0xC000, 0xC000}, {0xC022, 0xC022}}},
+		{"merges chain", []*Property{uint8At("a", 0xC000), uint8At("b", 0xC021), uint8At("c", 0xC042)},
+			[][2]uint32{{0xC000, 0xC042}}},
+		{"overlapping properties", []*Property{
+			{Name: "word", Type: PropertyTypeUint16, Address: 0xC100, Length: 2}, uint8At("low", 0xC100)},
+			[][2]uint32{{0xC100, 0xC101}}},
+		{"never across blocks", []*Property{uint8At("a", 0xCFFF), uint8At("b", 0xD000)},
+			[][2]uint32{{0xCFFF, 0xCFFF}, {0xD000, 0xD000}}},
+		{"clipped at the block end", []*Property{{Name: "wide", Type: PropertyTypeUint32, Address: 0xCFFE, Length: 4}},
+			[][2]uint32{{0xCFFE, 0xCFFF}, {0xD000, 0xD001}}},
+	}
+
+	for _, tt := range tests {
+		if got := planRanges(plan(tt.properties...)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ranges %X, want %X", tt.name, got, tt.want)
+		}
+	}
+
+	p := plan(uint8At("a", 0xC000), uint8At("b", 0xC021), uint8At("outside", 0x8000))
+	if len(p.Ranges) != 1 || !reflect.DeepEqual(p.Ranges[0].Properties, []string{"a", "b"}) || p.Ranges[0].Size != 0x22 {
+		t.Errorf("merged range %+v", p.Ranges)
+	}
+	if !reflect.DeepEqual(p.Unplanned, []string{"outside"}) {
+		t.Errorf("unplanned %v, want [outside]", p.Unplanned)
+	}
+	if p.PlannedBytes != 0x22 || p.FullBytes != 0x2000 {
+		t.Errorf("planned %d of %d bytes", p.PlannedBytes, p.FullBytes)
+	}
+}
+
+func TestReadPlanFollowsPointerTargets(t *testing.T) {
+	pointerType, uint16Type := PropertyTypePointer, PropertyTypeUint16
+	mapper := &Mapper{
+		Platform: Platform{
+			Endian:       "little",
+			MemoryBlocks: []types.MemoryBlock{{Name: "wram", Start: 0xC000, End: 0xDFFF}},
+		},
+		Properties: map[string]*Property{
+			"party": {Name: "party", Type: PropertyTypePointer, Address: 0xC000, Length: 4,
+				Advanced: &AdvancedConfig{TargetType: &uint16Type}},
+			// A pointer to a pointer to a uint32
+			"chain": {Name: "chain", Type: PropertyTypePointer, Address: 0xC010, Length: 4,
+				Advanced: &AdvancedConfig{TargetType: &pointerType, MaxDereferences: uintPtr(2)}},
+		},
+	}
+
+	plan := mapper.BuildReadPlan(0)
+	if got := planRanges(plan); !reflect.DeepEqual(got, [][2]uint32{{0xC000, 0xC003}, {0xC010, 0xC013}}) {
+		t.Fatalf("ranges before refresh %X", got)
+	}
+
+	manager := memory.NewManager()
+	update := func(party, chain, chainTarget uint32) {
+		wram := make([]byte, 0x2000)
+		binary.LittleEndian.PutUint32(wram[0x0000:], party)
+		binary.LittleEndian.PutUint32(wram[0x0010:], chain)
+		binary.LittleEndian.PutUint32(wram[0x1800:], chainTarget)
+		manager.Update(map[uint32][]byte{0xC000: wram})
+	}
+
+	update(0xD100, 0xD800, 0xD900)
+	refreshed := mapper.RefreshReadPlan(plan, manager)
+	want := [][2]uint32{{0xC000, 0xC003}, {0xC010, 0xC013}, {0xD100, 0xD101}, {0xD800, 0xD803}, {0xD900, 0xD903}}
+	if got := planRanges(refreshed); !reflect.DeepEqual(got, want) {
+		t.Errorf("ranges after refresh %X, want %X", got, want)
+	}
+	if !reflect.DeepEqual(refreshed.PointerTargets, map[string]uint32{"party": 0xD100, "chain": 0xD800}) {
+		t.Errorf("pointer targets %X", refreshed.PointerTargets)
+	}
+
+	// Stable targets keep the plan
+	if again := mapper.RefreshReadPlan(refreshed, manager); again != refreshed {
+		t.Error("refresh with unchanged targets built a new plan")
+	}
+
+	// A moved target replaces the old one; a null pointer plans nothing
+	update(0xD200, 0, 0)
+	moved := mapper.RefreshReadPlan(refreshed, manager)
+	if got := planRanges(moved); !reflect.DeepEqual(got, [][2]uint32{{0xC000, 0xC003}, {0xC010, 0xC013}, {0xD200, 0xD201}}) {
+		t.Errorf("ranges after the target moved %X", got)
+	}
+}
+
+func TestPropertyExtent(t *testing.T) {
+	uint8Type := PropertyTypeUint8
+	dynamic := true
+
+	tests := []struct {
+		name string
+		prop Property
+		want uint32
+	}{
+		{"length", Property{Type: PropertyTypeUint16, Length: 2}, 2},
+		{"type size without length", Property{Type: PropertyTypeUint16}, 2},
+		{"pointer reads at least 4 bytes", Property{Type: PropertyTypePointer, Length: 2}, 4},
+		{"array of elements", Property{Type: PropertyTypeArray, Length: 6,
+			Advanced: &AdvancedConfig{ElementType: &uint8Type, ElementSize: uintPtr(2)}}, 12},
+		{"array with stride and offset", Property{Type: PropertyTypeArray, Length: 6,
+			Advanced: &AdvancedConfig{ElementSize: uintPtr(2), Stride: uintPtr(44), IndexOffset: uintPtr(8)}}, 8 + 5*44 + 2},
+		{"array capped by max elements", Property{Type: PropertyTypeArray, Length: 20,
+			Advanced: &AdvancedConfig{ElementSize: uintPtr(4), MaxElements: uintPtr(3)}}, 20},
+		{"dynamic array reads max elements", Property{Type: PropertyTypeArray, Length: 1,
+			Advanced: &AdvancedConfig{ElementSize: uintPtr(4), MaxElements: uintPtr(6), DynamicLength: &dynamic}}, 24},
+		{"array in bytes already covering its elements", Property{Type: PropertyTypeArray, Length: 64,
+			Advanced: &AdvancedConfig{ElementSize: uintPtr(1)}}, 64},
+		{"struct extends to its last field", Property{Type: PropertyTypeStruct, Length: 4,
+			Advanced: &AdvancedConfig{Fields: map[string]*StructField{
+				"species": {Type: PropertyTypeUint8, Offset: 0},
+				"hp":      {Type: PropertyTypeUint16, Offset: 1, Size: uintPtr(2)},
+				"moves":   {Type: PropertyTypeUint32, Offset: 8, Size: uintPtr(4)},
+			}}}, 12},
+		{"struct field without size is one byte", Property{Type: PropertyTypeStruct,
+			Advanced: &AdvancedConfig{Fields: map[string]*StructField{"level": {Offset: 32}}}}, 33},
+		{"struct inside its length", Property{Type: PropertyTypeStruct, Length: 44,
+			Advanced: &AdvancedConfig{Fields: map[string]*StructField{"level": {Offset: 33}}}}, 44},
+	}
+
+	for _, tt := range tests {
+		if got := propertyExtent(&tt.prop); got != tt.want {
+			t.Errorf("%s: extent %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
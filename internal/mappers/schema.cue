@@ -205,7 +205,8 @@ package mappers
 // Enhanced memory block with validation
 #MemoryBlock: {
     name: string
-    start: string // hex address like "0x0000"
+    domain?: string // named memory domain; omitted for the system bus
+    start: string // hex address like "0x0000" (offset within domain)
     end: string   // hex address like "0x07FF"
 
     // Optional CUE expression for dynamic addresses
@@ -224,6 +225,35 @@ package mappers
     // Memory protection
     protected?: bool   // prevent accidental writes
     watchable?: bool   // trigger events on changes
+
+    // Bank switching: which bank is mapped into this block
+    banking?: #Banking
+}
+
+// Bank switching for a memory block. Either a register selects the bank mapped
+// into the block, or every bank is stored back to back in a memory domain.
+#Banking: {
+    register?: string    // bank-select register, e.g. "0xFF70" (domain prefix allowed)
+    mask?: uint          // bits of the register holding the bank number (default 0xFF)
+    zeroSelects?: uint   // bank mapped when the register reads 0 (GBC SVBK: 1)
+    domain?: string      // domain holding all banks
+    bankSize?: string    // size of one bank in domain, e.g. "0x1000"
+}
+
+// How a frozen property is held. Bounds apply to the decoded value (before
+// transforms) and are written back through the property's type, so they work
+// for BCD, enums and multi-byte values.
+#FreezeOptions: {
+    mode?: *"exact" | "min" | "max" | "range" | "increment-only" | "decrement-only"
+    min?: number       // for min and range, compared with the value before transforms
+    max?: number       // for max and range, compared with the value before transforms
+    condition?: string // only hold the value while this expression over other properties is true
+}
+
+// Named memory domain
+#MemoryDomain: {
+    busAddress?: string   // where offset 0 appears on the system bus, for drivers without native domains
+    description?: string
 }
 
 // Enhanced platform with rich configuration
@@ -238,6 +268,10 @@ package mappers
     // Platform base addresses for easier property definition
     baseAddresses?: [string]: string
 
+    // Named memory domains outside the system bus, e.g. save RAM.
+    // Properties address them as "sram:0xA598".
+    domains?: [string]: #MemoryDomain
+
     // Platform metadata
     description?: string
     version?: string
@@ -259,6 +293,15 @@ package mappers
         writeLatency?: number
         batchSize?: number   // optimal batch read size
     }
+
+    // Host process layout for the Linux process-memory driver
+    processMemory?: {
+        module?: string          // /proc/<pid>/maps path substring to search, e.g. "[heap]" or "libmgba"
+        signature?: string       // hex bytes located at or near emulated RAM, "??" for wildcards
+        signatureOffset?: int    // RAM base relative to the signature match
+        offset?: int             // RAM base relative to the module's first mapping (no signature)
+        emulatedStart?: string   // emulated address found at the RAM base (default: first memory block)
+    }
 }
 
 // ===== UI ORGANIZATION SYSTEM =====
@@ -296,11 +339,15 @@ package mappers
 #Property: {
     name: string
     type: #PropertyType
-    address: string // hex address or CUE expression
+    address: string // hex address or CUE expression, optionally domain-prefixed ("sram:0xA598")
 
     // Optional attributes
     length?: uint | string // can be number or CUE expression
     position?: uint        // for bit/nibble properties (0-7 for bits, 0-1 for nibbles)
+    bank?: uint            // bank holding the property in a bank-switched block
+    bankRegister?: string  // bank-select register when the block does not declare banking
+    bankMask?: uint
+    bankZeroSelects?: uint
     size?: uint           // element size for arrays/structs
     endian?: #Endian
     description?: string
@@ -318,6 +365,7 @@ package mappers
     // Freezing support
     freezable?: bool
     defaultFrozen?: bool
+    freeze?: #FreezeOptions // how a freeze holds the value
 
     // Custom read/write logic as CUE expressions
     readExpression?: string  // CUE expression to process raw bytes
@@ -614,6 +662,16 @@ package mappers
     // Platform configuration
     platform: #Platform
 
+    // Content identification for automatic mapper selection
+    identification?: {
+        crc32?: [...string]   // ROM CRC32s as hex, e.g. "9f7fdd53"
+        titles?: [...string]  // Internal header titles, e.g. "POKEMON RED"
+        header?: {            // Where the title lives; defaults per platform (GB, GBA, SNES)
+            address: string   // hex address like "0x0134"
+            length: uint
+        }
+    }
+
     // Global constants accessible in all property expressions
     constants?: [string]: _
 
@@ -0,0 +1,67 @@
+package mappers
+
+import (
+	"fmt"
+	"gamehook/internal/memory"
+	"regexp"
+	"strings"
+)
+
+// propertyNamePattern matches names usable as a CUE field label without quoting
+var propertyNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// FormatPropertySnippet renders a property as a CUE snippet ready to paste into
+// a mapper's properties block
+func FormatPropertySnippet(prop *Property) (string, error) {
+	if !propertyNamePattern.MatchString(prop.Name) {
+		return "", fmt.Errorf("property name %q must start with a letter and contain only letters, digits and underscores", prop.Name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: {\n", prop.Name)
+	fmt.Fprintf(&b, "    name: %q\n", prop.Name)
+	fmt.Fprintf(&b, "    type: %q\n", string(prop.Type))
+	fmt.Fprintf(&b, "    address: %q\n", memory.FormatAddress(prop.Domain, prop.Address))
+	fmt.Fprintf(&b, "    length: %d\n", prop.Length)
+	if prop.Endian != "" {
+		fmt.Fprintf(&b, "    endian: %q\n", prop.Endian)
+	}
+	if prop.Description != "" {
+		fmt.Fprintf(&b, "    description: %q\n", prop.Description)
+	}
+	if prop.Freezable {
+		b.WriteString("    freezable: true\n")
+	}
+	b.WriteString("}\n")
+
+	return b.String(), nil
+}
+
+// SearchCandidateProperty builds the property a RAM search candidate found at
+// address would be mapped as, using the value interpretation of the search
+func SearchCandidateProperty(name string, address uint32, options memory.SearchOptions) *Property {
+	prop := &Property{
+		Name:        name,
+		Domain:      options.Domain,
+		Address:     address,
+		Length:      uint32(options.Size),
+		Description: "Found by RAM search",
+		Freezable:   true,
+	}
+
+	switch options.Format {
+	case "bcd":
+		prop.Type = PropertyTypeBCD
+	case "signed":
+		prop.Type = map[int]PropertyType{1: PropertyTypeInt8, 2: PropertyTypeInt16, 4: PropertyTypeInt32}[options.Size]
+	default:
+		prop.Type = map[int]PropertyType{1: PropertyTypeUint8, 2: PropertyTypeUint16, 4: PropertyTypeUint32}[options.Size]
+	}
+
+	// Multi-byte BCD is always stored most significant digits first
+	if options.Size > 1 && prop.Type != PropertyTypeBCD {
+		prop.Endian = options.Endian
+	}
+
+	return prop
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+)
+
+// ===== BANK-SWITCHED MEMORY =====
+
+// BankRegister is the register that selects which bank is mapped into a window
+type BankRegister struct {
+	Domain      string `json:"domain,omitempty"` // Domain holding the register; empty for the system bus
+	Address     uint32 `json:"address"`
+	Mask        uint8  `json:"mask"`         // Bits of the register that hold the bank number
+	ZeroSelects uint32 `json:"zero_selects"` // Bank mapped when the masked register reads 0 (GBC SVBK maps 0 to 1)
+}
+
+// BankedWindow is an address range whose contents depend on a bank register
+type BankedWindow struct {
+	Domain   string       `json:"domain,omitempty"`
+	Start    uint32       `json:"start"`
+	End      uint32       `json:"end"` // inclusive
+	Register BankRegister `json:"register"`
+}
+
+// BankedRead is the result of reading an address inside a banked window
+type BankedRead struct {
+	Data       []byte
+	Bank       uint32    // Bank that was requested
+	ActiveBank uint32    // Bank currently mapped into the window
+	Stale      bool      // Data comes from the bank cache because Bank is not mapped in
+	CachedAt   time.Time // When Bank was last seen mapped in
+}
+
+// bankKey identifies the cache of one bank of one window
+type bankKey struct {
+	window int
+	bank   uint32
+}
+
+// bankCache holds the last bytes seen while a bank was mapped in
+type bankCache struct {
+	fragments map[uint32][]byte // start address -> data
+	updated   time.Time
+}
+
+// SetBankedWindows replaces the banked windows tracked by the manager and
+// clears every bank cache
+func (m *Manager) SetBankedWindows(windows []BankedWindow) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bankWindows = append([]BankedWindow(nil), windows...)
+	m.bankCaches = make(map[bankKey]*bankCache)
+	m.publishLocked()
+}
+
+// ReadBankedBytes reads bytes from bank of the window containing the address
+// in the current snapshot. See Snapshot.ReadBankedBytes.
+func (m *Manager) ReadBankedBytes(domain string, address uint32, length uint32, bank uint32) (*BankedRead, error) {
+	return m.Snapshot().ReadBankedBytes(domain, address, length, bank)
+}
+
+// updateBankCachesLocked copies the mapped-in bank of every window in domain
+// into its cache. Caches of banks that are not mapped in are left untouched.
+func (m *Manager) updateBankCachesLocked(domain string) {
+	for i, window := range m.bankWindows {
+		if window.Domain != domain {
+			continue
+		}
+
+		active, err := activeBank(m.indexes, window.Register)
+		if err != nil {
+			continue
+		}
+
+		key := bankKey{window: i, bank: active}
+		cache := m.bankCaches[key]
+		if cache == nil {
+			cache = &bankCache{fragments: make(map[uint32][]byte)}
+			m.bankCaches[key] = cache
+		}
+
+		idx := m.indexes[domain]
+		if idx == nil {
+			continue
+		}
+		for pos, start := range idx.starts {
+			data := idx.blocks[pos]
+			end := start + uint32(len(data)) - 1
+			if len(data) == 0 || end < window.Start || start > window.End {
+				continue
+			}
+
+			from, to := start, end
+			if from < window.Start {
+				from = window.Start
+			}
+			if to > window.End {
+				to = window.End
+			}
+			cached := make([]byte, to-from+1)
+			copy(cached, data[from-start:to-start+1])
+			cache.fragments[from] = cached
+		}
+		cache.updated = time.Now()
+	}
+}
+
+// activeBank reads a bank register and returns the bank it selects
+func activeBank(indexes map[string]*blockIndex, register BankRegister) (uint32, error) {
+	data, ok := lookup(indexes, register.Domain, register.Address, 1)
+	if !ok {
+		return 0, fmt.Errorf("bank register %s not found in loaded memory",
+			FormatAddress(register.Domain, register.Address))
+	}
+
+	bank := uint32(data[0] & register.Mask)
+	if bank == 0 {
+		bank = register.ZeroSelects
+	}
+	return bank, nil
+}
+
+// findBankedWindow returns the first window containing the whole range
+func findBankedWindow(windows []BankedWindow, domain string, address uint32, length uint32) (int, bool) {
+	end := address + length - 1
+	for i, window := range windows {
+		if window.Domain == domain && address >= window.Start && end <= window.End {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// lookup copies length bytes at address from the indexed blocks of domain
+func lookup(indexes map[string]*blockIndex, domain string, address uint32, length uint32) ([]byte, bool) {
+	idx := indexes[domain]
+	if idx == nil {
+		return nil, false
+	}
+	result := make([]byte, length)
+	if !idx.read(result, address) {
+		return nil, false
+	}
+	return result, true
+}
+
+// lookupFragment copies length bytes at address from the fragment containing them
+func lookupFragment(fragments map[uint32][]byte, address uint32, length uint32) ([]byte, bool) {
+	for start, data := range fragments {
+		if address < start || uint64(address)+uint64(length) > uint64(start)+uint64(len(data)) {
+			continue
+		}
+		offset := address - start
+		result := make([]byte, length)
+		copy(result, data[offset:offset+length])
+		return result, true
+	}
+	return nil, false
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"bytes"
+	"testing"
+)
+
+// bankedTestManager maps a 16-byte window at 0xD000 selected by the register
+// at 0xFF70, which like the GBC's SVBK maps bank 1 when it reads 0
+func bankedTestManager() *Manager {
+	m := NewManager()
+	m.SetBankedWindows([]BankedWindow{{
+		Start:    0xD000,
+		End:      0xD00F,
+		Register: BankRegister{Address: 0xFF70, Mask: 0x07, ZeroSelects: 1},
+	}})
+	return m
+}
+
+// bankContents fills a window's worth of bytes with value
+func bankContents(value byte) []byte {
+	return bytes.Repeat([]byte{value}, 16)
+}
+
+func TestBankCachesFollowTheRegister(t *testing.T) {
+	m := bankedTestManager()
+
+	// Register 0 selects bank 1
+	m.Update(map[uint32][]byte{0xD000: bankContents(0x11), 0xFF70: {0x00}})
+
+	read, err := m.ReadBankedBytes("", 0xD004, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Stale || read.ActiveBank != 1 || !bytes.Equal(read.Data, []byte{0x11, 0x11}) {
+		t.Errorf("bank 1 while mapped in: %+v", read)
+	}
+
+	// Bank 2 has never been seen
+	read, err = m.ReadBankedBytes("", 0xD004, 2, 2)
+	if err == nil || read == nil || !read.Stale || read.ActiveBank != 1 || read.Data != nil {
+		t.Errorf("bank 2 before it was mapped in: %+v, %v", read, err)
+	}
+
+	// Switching to bank 2 keeps bank 1's bytes in its cache
+	m.Update(map[uint32][]byte{0xD000: bankContents(0x22), 0xFF70: {0x02}})
+	inBank2 := m.Snapshot()
+
+	read, err = m.ReadBankedBytes("", 0xD004, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read.Stale || read.ActiveBank != 2 || !bytes.Equal(read.Data, []byte{0x11, 0x11}) || read.CachedAt.IsZero() {
+		t.Errorf("bank 1 after switching out: %+v", read)
+	}
+	if read, err = m.ReadBankedBytes("", 0xD00E, 2, 2); err != nil || read.Stale || !bytes.Equal(read.Data, []byte{0x22, 0x22}) {
+		t.Errorf("bank 2 after switching in: %+v, %v", read, err)
+	}
+
+	// Switching back in refreshes bank 1 and leaves bank 2 cached. Only the
+	// register's masked bits count.
+	m.Update(map[uint32][]byte{0xD000: bankContents(0x33), 0xFF70: {0xF9}})
+	if read, err = m.ReadBankedBytes("", 0xD000, 16, 1); err != nil || read.Stale || !bytes.Equal(read.Data, bankContents(0x33)) {
+		t.Errorf("bank 1 after switching back in: %+v, %v", read, err)
+	}
+	if read, err = m.ReadBankedBytes("", 0xD000, 16, 2); err != nil || !read.Stale || !bytes.Equal(read.Data, bankContents(0x22)) {
+		t.Errorf("bank 2 after switching out: %+v, %v", read, err)
+	}
+
+	// Older snapshots keep the cache they were published with
+	if read, err = inBank2.ReadBankedBytes("", 0xD000, 1, 1); err != nil || !bytes.Equal(read.Data, []byte{0x11}) {
+		t.Errorf("bank 1 in an older snapshot: %+v, %v", read, err)
+	}
+}
+
+func TestBankCachesOnlyCoverWhatWasRead(t *testing.T) {
+	m := bankedTestManager()
+
+	// Only the first half of bank 3 is read before it is switched out
+	m.Update(map[uint32][]byte{0xD000: bankContents(0x33)[:8], 0xFF70: {0x03}})
+	m.RetainBlocks("", map[uint32]uint32{0xFF70: 1})
+	m.Update(map[uint32][]byte{0xD000: bankContents(0x44), 0xFF70: {0x04}})
+
+	if read, err := m.ReadBankedBytes("", 0xD006, 2, 3); err != nil || !read.Stale || !bytes.Equal(read.Data, []byte{0x33, 0x33}) {
+		t.Errorf("cached half of bank 3: %+v, %v", read, err)
+	}
+	if read, err := m.ReadBankedBytes("", 0xD007, 2, 3); err == nil {
+		t.Errorf("read past the cached half of bank 3: %+v", read)
+	}
+}
+
+func TestBankedReadErrors(t *testing.T) {
+	m := bankedTestManager()
+	m.Update(map[uint32][]byte{0xD000: bankContents(0x11)})
+
+	// Without the register there is no way to tell which bank is mapped in
+	if _, err := m.ReadBankedBytes("", 0xD000, 1, 1); err == nil {
+		t.Error("read without the bank register succeeded")
+	}
+
+	m.Update(map[uint32][]byte{0xFF70: {0x01}})
+	if _, err := m.ReadBankedBytes("", 0xD00F, 2, 1); err == nil {
+		t.Error("read running out of the window succeeded")
+	}
+	if _, err := m.ReadBankedBytes("sram", 0xD000, 1, 1); err == nil {
+		t.Error("read in another domain succeeded")
+	}
+
+	// Replacing the windows drops the caches
+	m.Update(map[uint32][]byte{0xFF70: {0x02}})
+	if _, err := m.ReadBankedBytes("", 0xD000, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	m.SetBankedWindows([]BankedWindow{{
+		Start:    0xD000,
+		End:      0xD00F,
+		Register: BankRegister{Address: 0xFF70, Mask: 0x07, ZeroSelects: 1},
+	}})
+	if read, err := m.ReadBankedBytes("", 0xD000, 1, 1); err == nil {
+		t.Errorf("bank 1 cache survived new windows: %+v", read)
+	}
+}
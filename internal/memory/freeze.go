@@ -0,0 +1,112 @@
+package memory
+
+import "time"
+
+// ===== FREEZE ENFORCEMENT =====
+
+// FreezeEnforcement tracks how a frozen property is being held in emulator memory
+type FreezeEnforcement struct {
+	Status              string     `json:"status"`               // "holding", "waiting" (condition false) or "failing"
+	Enforcements        uint64     `json:"enforcements"`         // Corrections written back to the emulator
+	WriteFailures       uint64     `json:"write_failures"`       // Corrections the driver rejected
+	ConsecutiveFailures uint64     `json:"consecutive_failures"` // Failures since the last successful correction
+	FrozenAt            time.Time  `json:"frozen_at"`
+	LastEnforced        *time.Time `json:"last_enforced,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+
+	// Conditional and expiring freezes
+	Condition      string     `json:"condition,omitempty"` // Expression that must hold for the value to be enforced
+	ConditionError string     `json:"condition_error,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ExpiresAtFrame uint64     `json:"expires_at_frame,omitempty"`
+
+	// Bounded freezes
+	Mode string   `json:"mode,omitempty"`
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+}
+
+// StartFreezeEnforcement resets the enforcement tracking of a property that
+// was just frozen. Condition and expiry are taken from enforcement.
+func (m *Manager) StartFreezeEnforcement(name string, address uint32, enforcement FreezeEnforcement) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	m.propertyStateLocked(name, address).Freeze = &FreezeEnforcement{
+		Status:         "holding",
+		FrozenAt:       time.Now(),
+		Condition:      enforcement.Condition,
+		ExpiresAt:      enforcement.ExpiresAt,
+		ExpiresAtFrame: enforcement.ExpiresAtFrame,
+		Mode:           enforcement.Mode,
+		Min:            enforcement.Min,
+		Max:            enforcement.Max,
+	}
+}
+
+// RecordFreezeCondition records whether a conditional freeze's condition held
+// in the latest read. A failing freeze stays failing until a write succeeds.
+func (m *Manager) RecordFreezeCondition(name string, holds bool, err error) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	state := m.propertyStates[name]
+	if state == nil || state.Freeze == nil {
+		return
+	}
+
+	enforcement := state.Freeze
+	enforcement.ConditionError = ""
+	if err != nil {
+		enforcement.ConditionError = err.Error()
+	}
+
+	switch {
+	case !holds:
+		enforcement.Status = "waiting"
+	case enforcement.Status == "waiting":
+		enforcement.Status = "holding"
+	}
+}
+
+// StopFreezeEnforcement drops the enforcement tracking of a property that was unfrozen
+func (m *Manager) StopFreezeEnforcement(name string) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	if state := m.propertyStates[name]; state != nil {
+		state.Freeze = nil
+	}
+}
+
+// RecordFreezeEnforcement records the outcome of writing a correction for a
+// drifted frozen property. It returns the number of consecutive failures so
+// callers can report only the first one.
+func (m *Manager) RecordFreezeEnforcement(name string, err error) uint64 {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	state := m.propertyStates[name]
+	if state == nil || state.Freeze == nil {
+		return 0
+	}
+
+	now := time.Now()
+	enforcement := state.Freeze
+	if err != nil {
+		enforcement.Status = "failing"
+		enforcement.WriteFailures++
+		enforcement.ConsecutiveFailures++
+		enforcement.LastError = err.Error()
+		enforcement.LastErrorAt = &now
+		return enforcement.ConsecutiveFailures
+	}
+
+	enforcement.Status = "holding"
+	enforcement.Enforcements++
+	enforcement.ConsecutiveFailures = 0
+	enforcement.LastEnforced = &now
+	state.LastWrite = now
+	return 0
+}
@@ -0,0 +1,213 @@
+package memory
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ===== CHANGE HEATMAP =====
+
+// byteHeat counts how often each byte of a fragment changed between reads
+type byteHeat struct {
+	counts      []uint32
+	lastChanged []int64 // Unix nanoseconds, 0 while the byte never changed
+	hot         int     // Bytes that changed at least once
+}
+
+// HeatBucket summarizes the changes of one aligned range of bytes
+type HeatBucket struct {
+	Address     uint32     `json:"address"`
+	AddressHex  string     `json:"address_hex"`
+	Length      uint32     `json:"length"`      // Loaded bytes in the bucket
+	Changes     uint64     `json:"changes"`     // Byte changes summed over the bucket
+	MaxChanges  uint32     `json:"max_changes"` // Changes of the bucket's busiest byte
+	HotBytes    int        `json:"hot_bytes"`   // Bytes that changed at least once
+	LastChanged *time.Time `json:"last_changed,omitempty"`
+	Properties  []string   `json:"properties,omitempty"` // Mapper properties overlapping the bucket
+}
+
+// Heatmap is the change frequency of a domain's loaded bytes, in buckets
+type Heatmap struct {
+	Domain     string       `json:"domain,omitempty"`
+	BucketSize uint32       `json:"bucket_size"`
+	Reads      uint64       `json:"reads"` // System bus reads since the manager started
+	Buckets    []HeatBucket `json:"buckets"`
+}
+
+// recordHeatLocked counts the bytes of fragment that differ in data, which
+// must be the same length. Unchanged 8-byte words are skipped whole, so a
+// mostly static block costs little more than a comparison.
+func (m *Manager) recordHeatLocked(fragment *MemoryFragment, data []byte) {
+	old := fragment.Data
+	heat := fragment.heat
+	if heat == nil {
+		heat = &byteHeat{
+			counts:      make([]uint32, len(data)),
+			lastChanged: make([]int64, len(data)),
+		}
+		fragment.heat = heat
+	}
+
+	var now int64
+	changed := uint64(0)
+	newlyHot := false
+	for i := 0; i < len(data); {
+		if i+8 <= len(data) && binary.LittleEndian.Uint64(old[i:]) == binary.LittleEndian.Uint64(data[i:]) {
+			i += 8
+			continue
+		}
+
+		for end := min(i+8, len(data)); i < end; i++ {
+			if old[i] == data[i] {
+				continue
+			}
+			if now == 0 {
+				now = time.Now().UnixNano()
+			}
+			if heat.counts[i] == 0 {
+				heat.hot++
+				newlyHot = true
+			}
+			heat.counts[i]++
+			heat.lastChanged[i] = now
+			changed++
+		}
+	}
+
+	fragment.AccessCount += changed
+
+	// The pattern only depends on which bytes ever changed
+	if newlyHot {
+		fragment.AccessPattern = heat.pattern()
+	}
+}
+
+// pattern classifies where a fragment's changing bytes are: "static" when
+// none changed, "sparse" when few did, "sequential" when they form a few
+// contiguous runs and "random" when they are scattered
+func (h *byteHeat) pattern() string {
+	if h.hot == 0 {
+		return "static"
+	}
+	if h.hot*8 < len(h.counts) {
+		return "sparse"
+	}
+
+	runs := 0
+	for i, count := range h.counts {
+		if count > 0 && (i == 0 || h.counts[i-1] == 0) {
+			runs++
+		}
+	}
+	if runs <= h.hot/16+1 {
+		return "sequential"
+	}
+	return "random"
+}
+
+// maxHeatBuckets caps the buckets of one heatmap so a small bucket size over
+// a large domain can't hold the memory lock for long
+const maxHeatBuckets = 1 << 16
+
+// heatAccumulator collects a bucket's counts while a heatmap is built
+type heatAccumulator struct {
+	bucket      HeatBucket
+	lastChanged int64
+}
+
+// Heatmap buckets the change counts of a domain's loaded bytes into aligned
+// ranges of bucketSize bytes. Only buckets holding loaded bytes are returned,
+// and more than maxHeatBuckets of them is an error.
+func (m *Manager) Heatmap(domain string, bucketSize uint32) (*Heatmap, error) {
+	if bucketSize == 0 {
+		return nil, fmt.Errorf("bucket size must be at least 1 byte")
+	}
+
+	namespace := domain
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	heatmap := &Heatmap{
+		Domain:     domain,
+		BucketSize: bucketSize,
+		Reads:      m.frame,
+		Buckets:    make([]HeatBucket, 0),
+	}
+
+	ns := m.namespaces[namespace]
+	if ns == nil {
+		return heatmap, nil
+	}
+
+	// Each fragment is walked a bucket-sized span at a time, and fragments
+	// that never changed are only measured
+	buckets := make(map[uint32]*heatAccumulator)
+	for start, fragment := range ns.Fragments {
+		length := uint32(len(fragment.Data))
+		heat := fragment.heat
+		for offset := uint32(0); offset < length; {
+			address := start + offset
+			key := address - address%bucketSize
+			end := uint32(min(uint64(length), uint64(offset)+uint64(key)+uint64(bucketSize)-uint64(address)))
+
+			acc := buckets[key]
+			if acc == nil {
+				if len(buckets) == maxHeatBuckets {
+					return nil, fmt.Errorf("more than %d buckets of %d bytes are loaded, use a larger bucket size", maxHeatBuckets, bucketSize)
+				}
+				acc = &heatAccumulator{bucket: HeatBucket{Address: key, AddressHex: FormatAddress(domain, key)}}
+				buckets[key] = acc
+			}
+			acc.bucket.Length += end - offset
+
+			if heat != nil && heat.hot > 0 {
+				for i := offset; i < end; i++ {
+					count := heat.counts[i]
+					if count == 0 {
+						continue
+					}
+					acc.bucket.Changes += uint64(count)
+					acc.bucket.HotBytes++
+					if count > acc.bucket.MaxChanges {
+						acc.bucket.MaxChanges = count
+					}
+					if changedAt := heat.lastChanged[i]; changedAt > acc.lastChanged {
+						acc.lastChanged = changedAt
+					}
+				}
+			}
+			offset = end
+		}
+	}
+
+	for _, acc := range buckets {
+		if acc.lastChanged != 0 {
+			t := time.Unix(0, acc.lastChanged)
+			acc.bucket.LastChanged = &t
+		}
+		heatmap.Buckets = append(heatmap.Buckets, acc.bucket)
+	}
+	sort.Slice(heatmap.Buckets, func(i, j int) bool { return heatmap.Buckets[i].Address < heatmap.Buckets[j].Address })
+
+	return heatmap, nil
+}
+
+// ResetHeatmap clears the change counts of every loaded byte
+func (m *Manager) ResetHeatmap() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ns := range m.namespaces {
+		for _, fragment := range ns.Fragments {
+			fragment.heat = nil
+			fragment.AccessCount = 0
+			fragment.AccessPattern = "static"
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeatmapBucketsChanges(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: make([]byte, 0x20), 0xC028: make([]byte, 0x10)})
+
+	// 0xC001 changes twice, 0xC012 once and the second block's first byte once
+	first := make([]byte, 0x20)
+	first[0x01] = 1
+	m.Update(map[uint32][]byte{0xC000: append([]byte(nil), first...), 0xC028: make([]byte, 0x10)})
+	first[0x01] = 2
+	first[0x12] = 1
+	second := make([]byte, 0x10)
+	second[0] = 1
+	m.Update(map[uint32][]byte{0xC000: first, 0xC028: second})
+
+	heatmap, err := m.Heatmap("", 0x10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []HeatBucket{
+		{Address: 0xC000, Length: 0x10, Changes: 2, MaxChanges: 2, HotBytes: 1},
+		{Address: 0xC010, Length: 0x10, Changes: 1, MaxChanges: 1, HotBytes: 1},
+		{Address: 0xC020, Length: 0x08, Changes: 1, MaxChanges: 1, HotBytes: 1}, // Second block starts mid-bucket
+		{Address: 0xC030, Length: 0x08},
+	}
+	if len(heatmap.Buckets) != len(want) {
+		t.Fatalf("got %d buckets, want %d: %+v", len(heatmap.Buckets), len(want), heatmap.Buckets)
+	}
+	for i, bucket := range heatmap.Buckets {
+		w := want[i]
+		if bucket.Address != w.Address || bucket.Length != w.Length || bucket.Changes != w.Changes ||
+			bucket.MaxChanges != w.MaxChanges || bucket.HotBytes != w.HotBytes {
+			t.Errorf("bucket %d is %+v, want %+v", i, bucket, w)
+		}
+		if (bucket.LastChanged != nil) != (w.Changes > 0) {
+			t.Errorf("bucket 0x%X last changed %v with %d changes", bucket.Address, bucket.LastChanged, bucket.Changes)
+		}
+	}
+	if heatmap.Reads != 3 {
+		t.Errorf("heatmap counts %d reads, want 3", heatmap.Reads)
+	}
+
+	m.ResetHeatmap()
+	if heatmap, _ = m.Heatmap("", 0x100); len(heatmap.Buckets) != 1 || heatmap.Buckets[0].Changes != 0 || heatmap.Buckets[0].Length != 0x30 {
+		t.Errorf("heatmap after reset is %+v", heatmap.Buckets)
+	}
+}
+
+func TestHeatmapCountsWholeBlocksAfterPlannedRanges(t *testing.T) {
+	m := NewManager()
+
+	// A planned range, then the whole block read for the heatmap
+	m.Update(map[uint32][]byte{0xC010: {1, 2}})
+	m.RetainBlocks("", map[uint32]uint32{0xC000: 0x40})
+	block := make([]byte, 0x40)
+	m.Update(map[uint32][]byte{0xC000: append([]byte(nil), block...)})
+	block[0x30] = 9
+	m.Update(map[uint32][]byte{0xC000: block})
+
+	heatmap, err := m.Heatmap("", 0x10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heatmap.Buckets) != 4 {
+		t.Fatalf("got %d buckets, want the whole block in 4: %+v", len(heatmap.Buckets), heatmap.Buckets)
+	}
+	if bucket := heatmap.Buckets[3]; bucket.Address != 0xC030 || bucket.Changes != 1 {
+		t.Errorf("unmapped bucket is %+v, want one change at 0xC030", bucket)
+	}
+}
+
+func TestHeatmapLimits(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0: make([]byte, maxHeatBuckets+1)})
+
+	if _, err := m.Heatmap("", 0); err == nil {
+		t.Error("zero bucket size accepted")
+	}
+	if _, err := m.Heatmap("", 1); err == nil || !strings.Contains(err.Error(), "larger bucket size") {
+		t.Errorf("heatmap of %d buckets returned %v", maxHeatBuckets+1, err)
+	}
+	if heatmap, err := m.Heatmap("", 2); err != nil || len(heatmap.Buckets) != maxHeatBuckets/2+1 {
+		t.Errorf("heatmap of 2-byte buckets returned %v", err)
+	}
+	if heatmap, err := m.Heatmap("sram", 16); err != nil || len(heatmap.Buckets) != 0 {
+		t.Errorf("heatmap of an unloaded domain returned %+v, %v", heatmap, err)
+	}
+}
@@ -0,0 +1,612 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Property history format
+//
+// Each series, one property of one mapper, is a directory of daily segment
+// files named YYYYMMDD.ghts after their UTC day. A segment starts with the
+// magic "GHTS" and a format version byte, followed by fixed-size points:
+//
+//	timestamp  int64    unix nanoseconds, little endian
+//	value      float64  IEEE 754 bits, little endian
+//
+// A point is written whenever the property's value changes, in time order,
+// so segments can be binary searched and retention drops whole segments.
+const (
+	historyMagic       = "GHTS"
+	historyVersion     = 1
+	historyHeaderSize  = len(historyMagic) + 1
+	historyPointSize   = 16
+	historySegmentExt  = ".ghts"
+	historyDayLayout   = "20060102"
+	historyPrunePeriod = time.Hour
+	historyReadChunk   = 4096 // Points read from disk at a time
+	maxHistoryResults  = 10000
+)
+
+// HistoryRetention bounds how much property history is kept on disk
+type HistoryRetention struct {
+	MaxAge   time.Duration // Segments entirely older than this are deleted; 0 keeps everything
+	MaxBytes int64         // Oldest days are deleted past this total size; 0 for no limit
+}
+
+// HistoryStore persists property values as time series under a directory
+type HistoryStore struct {
+	dir       string
+	retention HistoryRetention
+
+	mu      sync.Mutex // Guards pending
+	pending map[historySeries][]historyPoint
+
+	// flushMu serializes flushes with queries, so a query never misses points
+	// that left pending but are not on disk yet
+	flushMu   sync.Mutex
+	lastPrune time.Time
+}
+
+// historySeries identifies one property of one mapper
+type historySeries struct {
+	mapper   string
+	property string
+}
+
+// historyPoint is one stored value change
+type historyPoint struct {
+	at    int64 // unix nanoseconds
+	value float64
+}
+
+// HistoryPoint is one recorded value change
+type HistoryPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// HistoryBucket summarizes a property's value over one step of a query.
+// The value is held between changes, so a bucket without changes reports the
+// value carried in from before it.
+type HistoryBucket struct {
+	Start   time.Time `json:"start"`
+	Changes int       `json:"changes"`
+	Min     float64   `json:"min"`
+	Max     float64   `json:"max"`
+	Avg     float64   `json:"avg"` // Weighted by how long each value was held
+	Last    float64   `json:"last"`
+}
+
+// PropertyHistory is the result of a history query. Without a step it lists
+// the raw changes, otherwise one bucket per step.
+type PropertyHistory struct {
+	Mapper    string          `json:"mapper"`
+	Property  string          `json:"property"`
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	Step      time.Duration   `json:"step,omitempty"`
+	ChartType string          `json:"chart_type,omitempty"` // UI hint from the mapper
+	Points    []HistoryPoint  `json:"points,omitempty"`
+	Buckets   []HistoryBucket `json:"buckets,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"` // Only the latest points are listed
+}
+
+// NewHistoryStore opens the history under dir, creating it if needed, and
+// applies the retention policy
+func NewHistoryStore(dir string, retention HistoryRetention) (*HistoryStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	store := &HistoryStore{
+		dir:       dir,
+		retention: retention,
+		pending:   make(map[historySeries][]historyPoint),
+	}
+	if err := store.prune(time.Now()); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Record queues a property value for the next flush. Bools are stored as 0
+// and 1; values that are not numbers are ignored.
+func (h *HistoryStore) Record(mapper, property string, at time.Time, value interface{}) {
+	number, ok := historyNumber(value)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	series := historySeries{mapper: mapper, property: property}
+	h.pending[series] = append(h.pending[series], historyPoint{at: at.UnixNano(), value: number})
+}
+
+// Flush appends the queued values to their segments and applies the
+// retention policy when it is due
+func (h *HistoryStore) Flush() error {
+	h.flushMu.Lock()
+	defer h.flushMu.Unlock()
+
+	// Swap the queue out so recording is not blocked by disk writes
+	h.mu.Lock()
+	pending := h.pending
+	h.pending = make(map[historySeries][]historyPoint)
+	h.mu.Unlock()
+
+	var firstErr error
+	for series, points := range pending {
+		if err := h.appendPoints(series, points); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if now := time.Now(); now.Sub(h.lastPrune) >= historyPrunePeriod {
+		if err := h.prune(now); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close flushes the queued values
+func (h *HistoryStore) Close() error {
+	return h.Flush()
+}
+
+// appendPoints writes points to the segments of their days
+func (h *HistoryStore) appendPoints(series historySeries, points []historyPoint) error {
+	dir := h.seriesDir(series)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create history for %s: %w", series.property, err)
+	}
+
+	for len(points) > 0 {
+		day := time.Unix(0, points[0].at).UTC().Format(historyDayLayout)
+		n := 1
+		for n < len(points) && time.Unix(0, points[n].at).UTC().Format(historyDayLayout) == day {
+			n++
+		}
+		if err := appendSegment(filepath.Join(dir, day+historySegmentExt), points[:n]); err != nil {
+			return fmt.Errorf("failed to write history for %s: %w", series.property, err)
+		}
+		points = points[n:]
+	}
+	return nil
+}
+
+// appendSegment appends points to a segment file, writing the header first
+// when the file is new
+func appendSegment(path string, points []historyPoint) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, 0, historyHeaderSize+len(points)*historyPointSize)
+	if info.Size() == 0 {
+		buf = append(buf, historyMagic...)
+		buf = append(buf, historyVersion)
+	}
+	for _, point := range points {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(point.at))
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(point.value))
+	}
+
+	_, err = file.Write(buf)
+	return err
+}
+
+// Query returns a property's history between from and to. With a step the
+// values are downsampled into buckets of that length.
+func (h *HistoryStore) Query(mapper, property string, from, to time.Time, step time.Duration) (*PropertyHistory, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("history range end %v is not after its start %v", to, from)
+	}
+	if step < 0 {
+		return nil, fmt.Errorf("history step cannot be negative: %v", step)
+	}
+	if step > 0 && int64(to.Sub(from)/step) >= maxHistoryResults {
+		return nil, fmt.Errorf("history step %v gives more than %d buckets, use a larger step", step, maxHistoryResults)
+	}
+
+	result := &PropertyHistory{
+		Mapper:   mapper,
+		Property: property,
+		From:     from,
+		To:       to,
+		Step:     step,
+	}
+	series := historySeries{mapper: mapper, property: property}
+
+	h.flushMu.Lock()
+	defer h.flushMu.Unlock()
+
+	if step == 0 {
+		err := h.scan(series, from.UnixNano(), to.UnixNano(), func(point historyPoint) {
+			result.Points = append(result.Points, HistoryPoint{Timestamp: time.Unix(0, point.at), Value: point.value})
+			if len(result.Points) >= 2*maxHistoryResults {
+				result.Points = append(result.Points[:0], result.Points[maxHistoryResults:]...)
+				result.Truncated = true
+			}
+		})
+		if len(result.Points) > maxHistoryResults {
+			result.Points = result.Points[len(result.Points)-maxHistoryResults:]
+			result.Truncated = true
+		}
+		return result, err
+	}
+
+	held, hasHeld, err := h.valueBefore(series, from.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+
+	aggregator := &bucketAggregator{
+		from:    from.UnixNano(),
+		end:     to.UnixNano(),
+		step:    int64(step),
+		held:    held,
+		hasHeld: hasHeld,
+		index:   -1,
+	}
+	if err := h.scan(series, from.UnixNano(), to.UnixNano(), aggregator.add); err != nil {
+		return nil, err
+	}
+	result.Buckets = aggregator.finish()
+	return result, nil
+}
+
+// bucketAggregator downsamples a step function into fixed-length buckets
+// covering [from, end). Points must arrive in time order.
+type bucketAggregator struct {
+	from    int64
+	end     int64
+	step    int64
+	held    float64 // Value in effect at cursor
+	hasHeld bool
+
+	index   int64 // Current bucket, -1 before the first
+	bucket  HistoryBucket
+	valid   bool    // A value is known somewhere in the current bucket
+	known   int64   // When the value first became known in the current bucket
+	cursor  int64   // Time up to which the held value has been weighted
+	weight  float64 // Sum of value times nanoseconds held in the current bucket
+	buckets []HistoryBucket
+}
+
+// add applies one value change
+func (a *bucketAggregator) add(point historyPoint) {
+	if point.at < a.from || point.at >= a.end {
+		return
+	}
+	a.moveTo((point.at - a.from) / a.step)
+
+	if a.valid {
+		a.weight += a.held * float64(point.at-a.cursor)
+		a.bucket.Min = math.Min(a.bucket.Min, point.value)
+		a.bucket.Max = math.Max(a.bucket.Max, point.value)
+	} else {
+		a.valid, a.known = true, point.at
+		a.bucket.Min, a.bucket.Max = point.value, point.value
+	}
+	a.bucket.Last = point.value
+	a.bucket.Changes++
+	a.cursor = point.at
+	a.held, a.hasHeld = point.value, true
+}
+
+// moveTo closes buckets up to index and opens it
+func (a *bucketAggregator) moveTo(index int64) {
+	for a.index < index {
+		if a.index >= 0 {
+			a.close()
+		}
+		a.index++
+
+		start := a.from + a.index*a.step
+		a.bucket = HistoryBucket{Start: time.Unix(0, start)}
+		a.valid, a.known, a.cursor, a.weight = a.hasHeld, start, start, 0
+		if a.hasHeld {
+			a.bucket.Min, a.bucket.Max, a.bucket.Last = a.held, a.held, a.held
+		}
+	}
+}
+
+// close weights the held value to the end of the current bucket and keeps
+// the bucket. Buckets before the first known value are dropped.
+func (a *bucketAggregator) close() {
+	if !a.valid {
+		return
+	}
+
+	end := min(a.from+(a.index+1)*a.step, a.end)
+	a.weight += a.held * float64(end-a.cursor)
+	a.bucket.Avg = a.bucket.Last
+	if span := end - a.known; span > 0 {
+		a.bucket.Avg = a.weight / float64(span)
+	}
+	a.buckets = append(a.buckets, a.bucket)
+}
+
+// finish closes the remaining buckets and returns them
+func (a *bucketAggregator) finish() []HistoryBucket {
+	a.moveTo((a.end - 1 - a.from) / a.step)
+	a.close()
+	return a.buckets
+}
+
+// scan calls fn with the points of a series in [from, to], flushed segments
+// first and then values still queued
+func (h *HistoryStore) scan(series historySeries, from, to int64, fn func(historyPoint)) error {
+	segments, err := h.segments(series)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if segment.end <= from || segment.start > to {
+			continue
+		}
+		if err := scanSegment(segment.path, from, to, fn); err != nil {
+			return fmt.Errorf("failed to read history for %s: %w", series.property, err)
+		}
+	}
+
+	h.mu.Lock()
+	pending := append([]historyPoint(nil), h.pending[series]...)
+	h.mu.Unlock()
+	for _, point := range pending {
+		if point.at >= from && point.at <= to {
+			fn(point)
+		}
+	}
+	return nil
+}
+
+// valueBefore returns the last value of a series recorded before at
+func (h *HistoryStore) valueBefore(series historySeries, at int64) (float64, bool, error) {
+	h.mu.Lock()
+	pending := h.pending[series]
+	for i := len(pending) - 1; i >= 0; i-- {
+		if pending[i].at < at {
+			value := pending[i].value
+			h.mu.Unlock()
+			return value, true, nil
+		}
+	}
+	h.mu.Unlock()
+
+	segments, err := h.segments(series)
+	if err != nil {
+		return 0, false, err
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i].start >= at {
+			continue
+		}
+		var last historyPoint
+		found := false
+		if err := scanSegment(segments[i].path, math.MinInt64, at-1, func(point historyPoint) {
+			last, found = point, true
+		}); err != nil {
+			return 0, false, fmt.Errorf("failed to read history for %s: %w", series.property, err)
+		}
+		if found {
+			return last.value, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// scanSegment calls fn with the points of a segment file in [from, to]
+func scanSegment(path string, from, to int64, fn func(historyPoint)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	header := make([]byte, historyHeaderSize)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return fmt.Errorf("%s: truncated header", filepath.Base(path))
+	}
+	if !bytes.Equal(header[:len(historyMagic)], []byte(historyMagic)) || header[len(historyMagic)] != historyVersion {
+		return fmt.Errorf("%s: not a history segment", filepath.Base(path))
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	count := (info.Size() - int64(historyHeaderSize)) / historyPointSize
+
+	// Binary search for the first point at or after from
+	var stamp [8]byte
+	var readErr error
+	first := sort.Search(int(count), func(i int) bool {
+		if _, err := file.ReadAt(stamp[:], int64(historyHeaderSize)+int64(i)*historyPointSize); err != nil {
+			readErr = err
+			return true
+		}
+		return int64(binary.LittleEndian.Uint64(stamp[:])) >= from
+	})
+	if readErr != nil {
+		return readErr
+	}
+
+	buf := make([]byte, historyReadChunk*historyPointSize)
+	for index := int64(first); index < count; {
+		n := min(count-index, historyReadChunk)
+		chunk := buf[:n*historyPointSize]
+		if _, err := file.ReadAt(chunk, int64(historyHeaderSize)+index*historyPointSize); err != nil {
+			return err
+		}
+		for offset := 0; offset < len(chunk); offset += historyPointSize {
+			point := historyPoint{
+				at:    int64(binary.LittleEndian.Uint64(chunk[offset:])),
+				value: math.Float64frombits(binary.LittleEndian.Uint64(chunk[offset+8:])),
+			}
+			if point.at > to {
+				return nil
+			}
+			fn(point)
+		}
+		index += n
+	}
+	return nil
+}
+
+// historySegment is one daily segment file
+type historySegment struct {
+	path  string
+	start int64 // Start of the segment's day, unix nanoseconds
+	end   int64 // Start of the next day
+	size  int64
+}
+
+// segments lists the segments of a series in day order
+func (h *HistoryStore) segments(series historySeries) ([]historySegment, error) {
+	return listSegments(h.seriesDir(series))
+}
+
+// listSegments lists the segment files in dir in day order
+func listSegments(dir string) ([]historySegment, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	var segments []historySegment
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), historySegmentExt)
+		if entry.IsDir() || name == entry.Name() {
+			continue
+		}
+		day, err := time.Parse(historyDayLayout, name)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		segments = append(segments, historySegment{
+			path:  filepath.Join(dir, entry.Name()),
+			start: day.UnixNano(),
+			end:   day.AddDate(0, 0, 1).UnixNano(),
+			size:  info.Size(),
+		})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].start < segments[j].start })
+	return segments, nil
+}
+
+// prune deletes segments past the retention age, then the oldest days until
+// the history fits the size limit
+func (h *HistoryStore) prune(now time.Time) error {
+	h.lastPrune = now
+	if h.retention.MaxAge <= 0 && h.retention.MaxBytes <= 0 {
+		return nil
+	}
+
+	var all []historySegment
+	err := filepath.WalkDir(h.dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() || path == h.dir {
+			return err
+		}
+		segments, err := listSegments(path)
+		all = append(all, segments...)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan history: %w", err)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].start < all[j].start })
+
+	total := int64(0)
+	for _, segment := range all {
+		total += segment.size
+	}
+
+	cutoff := now.Add(-h.retention.MaxAge).UnixNano()
+	for _, segment := range all {
+		expired := h.retention.MaxAge > 0 && segment.end <= cutoff
+		oversized := h.retention.MaxBytes > 0 && total > h.retention.MaxBytes
+		if !expired && !oversized {
+			break
+		}
+		if err := os.Remove(segment.path); err != nil {
+			return fmt.Errorf("failed to delete history segment: %w", err)
+		}
+		total -= segment.size
+	}
+	return nil
+}
+
+// seriesDir returns the directory holding a series' segments
+func (h *HistoryStore) seriesDir(series historySeries) string {
+	return filepath.Join(h.dir, historyPathName(series.mapper), historyPathName(series.property))
+}
+
+// historyPathName turns a mapper or property name into a safe path element.
+// Names made only of letters, digits, '_', '-' and '.' are used as they are;
+// any other name is sanitized and gets a '~' and a hash of the original, so
+// "a b" and "a_b" keep separate histories.
+func historyPathName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	if safe == name && strings.Trim(name, ".") != "" {
+		return name
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+	return fmt.Sprintf("%s~%016x", safe, hash.Sum64())
+}
+
+// historyNumber converts a property value to the number stored for it
+func historyNumber(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, true
+		}
+		return 0, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
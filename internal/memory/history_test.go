@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBucketAggregatorWeightsByTimeHeld(t *testing.T) {
+	tests := []struct {
+		name    string
+		end     int64
+		held    *float64 // Value carried in from before the range
+		points  []historyPoint
+		buckets []HistoryBucket
+	}{
+		{
+			name:   "starts unknown",
+			end:    40,
+			points: []historyPoint{{5, 10}, {8, 20}, {25, 0}},
+			buckets: []HistoryBucket{
+				// Averaged only over the 5ns the value was known
+				{Start: time.Unix(0, 0), Changes: 2, Min: 10, Max: 20, Avg: 14, Last: 20},
+				{Start: time.Unix(0, 10), Changes: 0, Min: 20, Max: 20, Avg: 20, Last: 20},
+				{Start: time.Unix(0, 20), Changes: 1, Min: 0, Max: 20, Avg: 10, Last: 0},
+				{Start: time.Unix(0, 30), Changes: 0, Min: 0, Max: 0, Avg: 0, Last: 0},
+			},
+		},
+		{
+			name:   "carries a value in",
+			end:    15,
+			held:   floatPtr(50),
+			points: []historyPoint{{5, 100}},
+			buckets: []HistoryBucket{
+				{Start: time.Unix(0, 0), Changes: 1, Min: 50, Max: 100, Avg: 75, Last: 100},
+				// The last bucket is cut short by the end of the range
+				{Start: time.Unix(0, 10), Changes: 0, Min: 100, Max: 100, Avg: 100, Last: 100},
+			},
+		},
+		{
+			name:   "drops buckets before the first value",
+			end:    30,
+			points: []historyPoint{{-5, 1}, {24, 6}, {30, 9}},
+			buckets: []HistoryBucket{
+				{Start: time.Unix(0, 20), Changes: 1, Min: 6, Max: 6, Avg: 6, Last: 6},
+			},
+		},
+		{
+			name:    "nothing known",
+			end:     30,
+			buckets: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		aggregator := &bucketAggregator{from: 0, end: tt.end, step: 10, index: -1}
+		if tt.held != nil {
+			aggregator.held, aggregator.hasHeld = *tt.held, true
+		}
+		for _, point := range tt.points {
+			aggregator.add(point)
+		}
+		if got := aggregator.finish(); !reflect.DeepEqual(got, tt.buckets) {
+			t.Errorf("%s: buckets\n%+v\nwant\n%+v", tt.name, got, tt.buckets)
+		}
+	}
+}
+
+func TestHistoryQueryCarriesTheValueBefore(t *testing.T) {
+	store, err := NewHistoryStore(t.TempDir(), HistoryRetention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	midnight := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	// The value in effect at the start of the query was written on the previous day
+	store.Record("red", "hp", midnight.Add(-2*time.Hour), 3)
+	store.Record("red", "hp", midnight.Add(-time.Hour), 7)
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	store.Record("red", "hp", midnight.Add(90*time.Second), uint8(1))
+
+	history, err := store.Query("red", "hp", midnight, midnight.Add(2*time.Minute), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []HistoryBucket{
+		{Start: midnight, Changes: 0, Min: 7, Max: 7, Avg: 7, Last: 7},
+		{Start: midnight.Add(time.Minute), Changes: 1, Min: 1, Max: 7, Avg: 4, Last: 1},
+	}
+	if !bucketsEqual(history.Buckets, want) {
+		t.Errorf("buckets carrying a flushed value\n%+v\nwant\n%+v", history.Buckets, want)
+	}
+
+	// A value still queued is newer than anything on disk
+	store.Record("red", "hp", midnight.Add(-time.Second), true)
+	if history, err = store.Query("red", "hp", midnight, midnight.Add(time.Minute), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Buckets) != 1 || history.Buckets[0].Avg != 1 {
+		t.Errorf("buckets carrying a queued value %+v, want an average of 1", history.Buckets)
+	}
+
+	// Raw queries list only the changes inside the range
+	if history, err = store.Query("red", "hp", midnight.Add(-90*time.Minute), midnight.Add(time.Hour), 0); err != nil {
+		t.Fatal(err)
+	}
+	var values []float64
+	for _, point := range history.Points {
+		values = append(values, point.Value)
+	}
+	if !reflect.DeepEqual(values, []float64{7, 1, 1}) {
+		t.Errorf("raw values %v, want [7 1 1]", values)
+	}
+}
+
+// bucketsEqual compares buckets, ignoring the location of their start times
+func bucketsEqual(got, want []HistoryBucket) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		a, b := got[i], want[i]
+		if !a.Start.Equal(b.Start) {
+			return false
+		}
+		a.Start, b.Start = time.Time{}, time.Time{}
+		if a != b {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHistoryPathNamesAreDistinct(t *testing.T) {
+	names := []string{
+		"hp", "a_b", "a b", "a/b", `a\b`, "a:b", "a__b", "a~b", "", ".", "..", "...", "_",
+		"pokemon_red", "playerHP", "party.0.hp", "ポケモン",
+	}
+
+	seen := make(map[string]string)
+	for _, name := range names {
+		path := historyPathName(name)
+		if other, exists := seen[path]; exists {
+			t.Errorf("%q and %q share the path name %q", name, other, path)
+		}
+		seen[path] = name
+
+		if path == "" || path == "." || path == ".." || strings.ContainsAny(path, `/\:`) || filepath.Base(path) != path {
+			t.Errorf("%q has the unsafe path name %q", name, path)
+		}
+	}
+
+	// Names that are already safe keep their existing directories
+	for _, name := range []string{"hp", "a_b", "pokemon_red", "party.0.hp", "_"} {
+		if path := historyPathName(name); path != name {
+			t.Errorf("safe name %q stored as %q", name, path)
+		}
+	}
+
+	// Properties whose names only differ in unsafe characters keep separate histories
+	store, err := NewHistoryStore(t.TempDir(), HistoryRetention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	store.Record("red", "a b", at, 1)
+	store.Record("red", "a_b", at, 2)
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]float64{"a b": 1, "a_b": 2} {
+		history, err := store.Query("red", name, at.Add(-time.Minute), at.Add(time.Minute), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history.Points) != 1 || history.Points[0].Value != want {
+			t.Errorf("history of %q is %+v, want one point of %v", name, history.Points, want)
+		}
+	}
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
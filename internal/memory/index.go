@@ -0,0 +1,174 @@
+package memory
+
+import "sort"
+
+// ===== INTERVAL INDEX =====
+
+// blockIndex is a sorted interval index over the loaded blocks of one domain.
+//
+// Lookups are a binary search over block start addresses, so the cost of a
+// read does not grow with the number of blocks. Reads copy only the requested
+// bytes and may span adjacent blocks.
+//
+// Published snapshots share block data with the live index. A block is only
+// copied when it is written while a snapshot still holds it.
+type blockIndex struct {
+	starts    []uint32       // Block start addresses, ascending
+	blocks    [][]byte       // Block data in the same order as starts
+	maxEnds   []uint64       // Highest exclusive end among blocks[0..i], bounds overlap scans
+	positions map[uint32]int // Start address -> position in starts
+	shared    []bool         // Blocks a snapshot may still hold, copied before they are written
+}
+
+// newBlockIndex indexes blocks, a map of start address to data
+func newBlockIndex(blocks map[uint32][]byte) *blockIndex {
+	idx := &blockIndex{
+		starts:    make([]uint32, 0, len(blocks)),
+		blocks:    make([][]byte, len(blocks)),
+		maxEnds:   make([]uint64, len(blocks)),
+		positions: make(map[uint32]int, len(blocks)),
+		shared:    make([]bool, len(blocks)),
+	}
+
+	for start := range blocks {
+		idx.starts = append(idx.starts, start)
+	}
+	sort.Slice(idx.starts, func(i, j int) bool { return idx.starts[i] < idx.starts[j] })
+
+	maxEnd := uint64(0)
+	for i, start := range idx.starts {
+		data := blocks[start]
+		idx.blocks[i] = data
+		idx.positions[start] = i
+		idx.shared[i] = true // The data may already be published
+		if end := uint64(start) + uint64(len(data)); end > maxEnd {
+			maxEnd = end
+		}
+		idx.maxEnds[i] = maxEnd
+	}
+
+	return idx
+}
+
+// reindexLocked rebuilds the interval index of domain after blocks were added,
+// removed or resized
+func (m *Manager) reindexLocked(domain string) {
+	m.indexes[domain] = newBlockIndex(m.domainDataLocked(domain))
+}
+
+// domainDataLocked returns the loaded blocks of domain as start address -> data
+func (m *Manager) domainDataLocked(domain string) map[uint32][]byte {
+	if domain == "" {
+		return m.blocks
+	}
+
+	data := make(map[uint32][]byte)
+	if ns := m.namespaces[domain]; ns != nil {
+		for start, fragment := range ns.Fragments {
+			data[start] = fragment.Data
+		}
+	}
+	return data
+}
+
+// share returns a read-only view of the index for a snapshot. Block data is
+// not copied; instead every block is marked shared so the live index copies
+// it before its next write. Layout slices are shared since they are only
+// ever replaced.
+func (idx *blockIndex) share() *blockIndex {
+	for i := range idx.shared {
+		idx.shared[i] = true
+	}
+
+	return &blockIndex{
+		starts:    idx.starts,
+		blocks:    append([][]byte(nil), idx.blocks...),
+		maxEnds:   idx.maxEnds,
+		positions: idx.positions,
+	}
+}
+
+// set points the block at start to new data of the same length, which must
+// not be shared with a snapshot. It reports false when the block is not
+// indexed or changed size, so the caller must rebuild the index.
+func (idx *blockIndex) set(start uint32, data []byte) bool {
+	pos, ok := idx.positions[start]
+	if !ok || len(idx.blocks[pos]) != len(data) {
+		return false
+	}
+	idx.blocks[pos] = data
+	idx.shared[pos] = false
+	return true
+}
+
+// locate returns the position of the block containing address, or -1. When
+// blocks overlap, the one starting closest to address wins.
+func (idx *blockIndex) locate(address uint32) int {
+	i := sort.Search(len(idx.starts), func(i int) bool { return idx.starts[i] > address })
+	for j := i - 1; j >= 0 && idx.maxEnds[j] > uint64(address); j-- {
+		if uint64(address) < uint64(idx.starts[j])+uint64(len(idx.blocks[j])) {
+			return j
+		}
+	}
+	return -1
+}
+
+// read copies len(dst) bytes starting at address into dst, crossing into
+// adjacent blocks as needed. It reports false if any byte is not loaded.
+func (idx *blockIndex) read(dst []byte, address uint32) bool {
+	for len(dst) > 0 {
+		pos := idx.locate(address)
+		if pos < 0 {
+			return false
+		}
+		n := copy(dst, idx.blocks[pos][address-idx.starts[pos]:])
+		dst = dst[n:]
+		if len(dst) > 0 && uint64(address)+uint64(n) > 0xFFFFFFFF {
+			return false
+		}
+		address += uint32(n)
+	}
+	return true
+}
+
+// write copies data into the loaded blocks starting at address, crossing into
+// adjacent blocks as needed. Blocks still held by a snapshot are copied first
+// and passed to copied so the caller can repoint its own references. It
+// reports false if it stopped at unloaded bytes.
+func (idx *blockIndex) write(address uint32, data []byte, copied func(start uint32, block []byte)) bool {
+	for len(data) > 0 {
+		pos := idx.locate(address)
+		if pos < 0 {
+			return false
+		}
+		if idx.shared[pos] {
+			idx.blocks[pos] = append([]byte(nil), idx.blocks[pos]...)
+			idx.shared[pos] = false
+			copied(idx.starts[pos], idx.blocks[pos])
+		}
+		n := copy(idx.blocks[pos][address-idx.starts[pos]:], data)
+		data = data[n:]
+		if len(data) > 0 && uint64(address)+uint64(n) > 0xFFFFFFFF {
+			return false
+		}
+		address += uint32(n)
+	}
+	return true
+}
+
+// available returns how many contiguous bytes are loaded from address onwards
+func (idx *blockIndex) available(address uint32) uint64 {
+	total := uint64(0)
+	for {
+		pos := idx.locate(address)
+		if pos < 0 {
+			return total
+		}
+		n := uint64(idx.starts[pos]) + uint64(len(idx.blocks[pos])) - uint64(address)
+		total += n
+		if uint64(address)+n > 0xFFFFFFFF {
+			return total
+		}
+		address += uint32(n)
+	}
+}
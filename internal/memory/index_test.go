@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+const benchmarkBlockSize = 0x100
+
+// benchmarkMemory returns blocks of benchmarkBlockSize bytes laid out with gaps
+// between them, and property addresses spread evenly across all of them
+func benchmarkMemory(blockCount, propertyCount int) (map[uint32][]byte, []uint32) {
+	blocks := make(map[uint32][]byte, blockCount)
+	for i := 0; i < blockCount; i++ {
+		data := make([]byte, benchmarkBlockSize)
+		for j := range data {
+			data[j] = byte(i + j)
+		}
+		blocks[uint32(i)*0x1000] = data
+	}
+
+	addresses := make([]uint32, propertyCount)
+	for i := range addresses {
+		block := i % blockCount
+		offset := (i * 7) % (benchmarkBlockSize - 4)
+		addresses[i] = uint32(block)*0x1000 + uint32(offset)
+	}
+	return blocks, addresses
+}
+
+// linearRead is the lookup ReadBytes used before the interval index: scan
+// every block for the one containing address and copy it whole
+func linearRead(blocks map[uint32][]byte, address uint32, length uint32) ([]byte, error) {
+	for start, data := range blocks {
+		if address >= start && address <= start+uint32(len(data))-1 {
+			block := make([]byte, len(data))
+			copy(block, data)
+
+			offset := address - start
+			if offset+length > uint32(len(block)) {
+				return nil, fmt.Errorf("not enough data at address 0x%X", address)
+			}
+			result := make([]byte, length)
+			copy(result, block[offset:offset+length])
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("address 0x%X not found in loaded memory", address)
+}
+
+func TestIndexedReadsMatchLinearScan(t *testing.T) {
+	blocks, addresses := benchmarkMemory(64, 1000)
+	m := NewManager()
+	m.Update(blocks)
+
+	for _, address := range addresses {
+		want, err := linearRead(blocks, address, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := m.ReadBytes(address, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("read at 0x%X got % X, want % X", address, got, want)
+		}
+	}
+
+	// A read inside a block succeeds; one in the gap after it fails
+	if _, err := m.ReadBytes(0x40, 4); err != nil {
+		t.Errorf("read inside a block failed: %v", err)
+	}
+	if _, err := m.ReadBytes(0x100, 1); err == nil {
+		t.Error("read in the gap between blocks succeeded")
+	}
+}
+
+func TestIndexCrossesAdjacentBlocks(t *testing.T) {
+	block := func(first byte) []byte {
+		data := make([]byte, 16)
+		for i := range data {
+			data[i] = first + byte(i)
+		}
+		return data
+	}
+
+	m := NewManager()
+	m.Update(map[uint32][]byte{
+		0xC000:     block(0x00),
+		0xC010:     block(0x10), // Adjacent to the first block
+		0xC030:     block(0x30), // After a gap
+		0xFFFFFFF0: block(0xF0),
+	})
+	m.UpdateDomain("sram", map[uint32][]byte{0x0000: block(0x80), 0x0010: block(0x90)})
+
+	reads := []struct {
+		domain  string
+		address uint32
+		length  uint32
+		want    []byte // nil when the read must fail
+	}{
+		{"", 0xC00E, 4, []byte{0x0E, 0x0F, 0x10, 0x11}},
+		{"", 0xC000, 32, append(block(0x00), block(0x10)...)},
+		{"", 0xC01F, 1, []byte{0x1F}},
+		{"", 0xC01E, 4, nil}, // Runs into the gap
+		{"", 0xC02F, 2, nil}, // Starts in the gap
+		{"", 0xFFFFFFFE, 2, []byte{0xFE, 0xFF}},
+		{"", 0xFFFFFFFE, 3, nil}, // Past the end of the address space
+		{"sram", 0x000F, 2, []byte{0x8F, 0x90}},
+		{"sram", 0x001F, 2, nil},
+	}
+	for _, r := range reads {
+		got, err := m.ReadDomainBytes(r.domain, r.address, r.length)
+		if r.want == nil {
+			if err == nil {
+				t.Errorf("read of %d bytes at %s succeeded: % X", r.length, FormatAddress(r.domain, r.address), got)
+			}
+			continue
+		}
+		if err != nil || !bytes.Equal(got, r.want) {
+			t.Errorf("read of %d bytes at %s got % X (%v), want % X", r.length, FormatAddress(r.domain, r.address), got, err, r.want)
+		}
+	}
+
+	// Writes across a boundary land in both blocks and leave older snapshots alone
+	before := m.Snapshot()
+	m.WriteBytes(0xC00F, []byte{0xAA, 0xBB})
+	m.WriteDomainBytes("sram", 0x000E, []byte{0xCC, 0xDD, 0xEE})
+
+	if got, _ := m.ReadBytes(0xC00E, 4); !bytes.Equal(got, []byte{0x0E, 0xAA, 0xBB, 0x11}) {
+		t.Errorf("bus write across blocks read back % X", got)
+	}
+	if got, _ := m.ReadDomainBytes("sram", 0x000D, 5); !bytes.Equal(got, []byte{0x8D, 0xCC, 0xDD, 0xEE, 0x91}) {
+		t.Errorf("sram write across blocks read back % X", got)
+	}
+	if got, _ := before.ReadBytes(0xC00E, 4); !bytes.Equal(got, []byte{0x0E, 0x0F, 0x10, 0x11}) {
+		t.Errorf("snapshot changed by a write across blocks: % X", got)
+	}
+
+	// A write running into the gap keeps the loaded part
+	m.WriteBytes(0xC01F, []byte{0x77, 0x78})
+	if got, _ := m.ReadBytes(0xC01F, 1); !bytes.Equal(got, []byte{0x77}) {
+		t.Errorf("write into the gap left % X", got)
+	}
+}
+
+// BenchmarkPropertyReads reads every property once per iteration and reports
+// the cost per property read. With the interval index it stays flat as blocks
+// and properties grow; the linear scan grows with the number of blocks.
+func BenchmarkPropertyReads(b *testing.B) {
+	for _, blockCount := range []int{8, 64, 512} {
+		for _, propertyCount := range []int{100, 1000} {
+			blocks, addresses := benchmarkMemory(blockCount, propertyCount)
+			name := fmt.Sprintf("blocks=%d/properties=%d", blockCount, propertyCount)
+
+			b.Run(name+"/index", func(b *testing.B) {
+				m := NewManager()
+				m.Update(blocks)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for _, address := range addresses {
+						if _, err := m.ReadBytes(address, 4); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(addresses)), "ns/property")
+			})
+
+			b.Run(name+"/linear", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					for _, address := range addresses {
+						if _, err := linearRead(blocks, address, 4); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(addresses)), "ns/property")
+			})
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -122,6 +123,9 @@ type PropertyState struct {
 	WatchEnabled   bool   `json:"watch_enabled"`
 	WatchCondition string `json:"watch_condition,omitempty"`
 
+	// Freeze enforcement, nil while the property is not frozen
+	Freeze *FreezeEnforcement `json:"freeze,omitempty"`
+
 	// Statistics
 	Statistics *PropertyStatistics `json:"statistics,omitempty"`
 }
@@ -154,14 +158,16 @@ type MemoryFragment struct {
 	StartAddress     uint32                 `json:"start_address"`
 	Data             []byte                 `json:"data"`
 	LastUpdated      time.Time              `json:"last_updated"`
-	AccessCount      uint64                 `json:"access_count"`
-	AccessPattern    string                 `json:"access_pattern"` // "sequential", "random", "sparse"
+	AccessCount      uint64                 `json:"access_count"`   // Byte changes seen between reads
+	AccessPattern    string                 `json:"access_pattern"` // "static", "sparse", "sequential" or "random"
 	CompressionRatio float64                `json:"compression_ratio"`
 	Checksum         uint32                 `json:"checksum"`
 	Dirty            bool                   `json:"dirty"`
 	Protected        bool                   `json:"protected"`
 	Cached           bool                   `json:"cached"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+
+	heat *byteHeat // Per-byte change counts, nil until a byte changes
 }
 
 // MemoryNamespace groups related memory fragments with enhanced organization
@@ -201,6 +207,16 @@ type Manager struct {
 	propertyStates map[string]*PropertyState   // property name -> state
 	namespaces     map[string]*MemoryNamespace // namespace -> fragments
 	propertyCache  map[string]*PropertyCache   // property name -> cache
+	indexes        map[string]*blockIndex      // domain -> interval index over its loaded blocks
+	bankWindows    []BankedWindow              // Bank-switched address ranges
+	bankCaches     map[bankKey]*bankCache      // Last contents of each bank seen mapped in
+
+	// Published snapshots; readers load the pointer and only take mu to
+	// publish writes made since the last update
+	snapshot    atomic.Pointer[Snapshot]
+	unpublished atomic.Bool // Set by writes, cleared when a snapshot is published
+	sequence    uint64
+	frame       uint64 // System bus reads, counted by Update
 
 	// Enhanced tracking
 	changeListeners   []func(address uint32, oldData, newData []byte)
@@ -217,10 +233,25 @@ type Manager struct {
 	globalStats     *GlobalStatistics
 	alertThresholds map[string]interface{}
 
+	// Read statistics are kept outside globalStats so reads only need the read lock
+	readCount  uint64 // atomic
+	readErrors uint64 // atomic
+	readNanos  int64  // atomic
+
 	// Thread safety for different operations
 	stateMu  sync.RWMutex // Separate mutex for property states
 	cacheMu  sync.RWMutex // Separate mutex for cache operations
 	frozenMu sync.RWMutex // Separate mutex for frozen properties
+
+	// RAM search sessions
+	searches     map[string]*SearchSession
+	nextSearchID uint64
+	searchMu     sync.Mutex
+
+	// Address watchpoints
+	watchpoints map[string]*Watchpoint
+	nextWatchID uint64
+	watchMu     sync.Mutex
 }
 
 // BatchOperation represents a batch memory operation
@@ -283,6 +314,10 @@ func NewManager() *Manager {
 		propertyStates:     make(map[string]*PropertyState),
 		namespaces:         make(map[string]*MemoryNamespace),
 		propertyCache:      make(map[string]*PropertyCache),
+		indexes:            make(map[string]*blockIndex),
+		bankCaches:         make(map[bankKey]*bankCache),
+		searches:           make(map[string]*SearchSession),
+		watchpoints:        make(map[string]*Watchpoint),
 		changeListeners:    make([]func(address uint32, oldData, newData []byte), 0),
 		propertyListeners:  make([]func(name string, event *PropertyEvent), 0),
 		validationEnabled:  true,
@@ -297,6 +332,8 @@ func NewManager() *Manager {
 		},
 	}
 
+	manager.publishLocked()
+
 	// Start batch processor
 	go manager.processBatchOperations()
 
@@ -358,14 +395,17 @@ func (m *Manager) AddPropertyListener(listener func(name string, event *Property
 
 // ===== ENHANCED MEMORY OPERATIONS =====
 
-// Update updates memory blocks with enhanced processing and frozen properties management
+// Update updates memory blocks with enhanced processing. Blocks are stored as
+// read, so frozen properties that drifted stay visible until they are corrected.
 func (m *Manager) Update(memoryData map[uint32][]byte) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	updateStart := time.Now()
 	m.globalStats.TotalReads++
+	m.frame++
 
+	reindex := false
 	for address, data := range memoryData {
 		oldData := make([]byte, len(data))
 		if existingData, exists := m.blocks[address]; exists {
@@ -376,15 +416,15 @@ func (m *Manager) Update(memoryData map[uint32][]byte) {
 		newData := make([]byte, len(data))
 		copy(newData, data)
 
-		// Update memory block
+		// Update memory block; the index only needs rebuilding when the block layout changes
 		m.blocks[address] = newData
+		if idx := m.indexes[""]; idx == nil || !idx.set(address, newData) {
+			reindex = true
+		}
 
 		// Update namespace fragments
 		m.updateFragment("default", address, newData)
 
-		// Apply frozen properties
-		m.applyFrozenProperties(address, newData)
-
 		// Detect changes and notify listeners
 		if !m.bytesEqual(oldData, newData) {
 			// Notify change listeners without holding lock
@@ -392,14 +432,22 @@ func (m *Manager) Update(memoryData map[uint32][]byte) {
 				go listener(address, oldData, newData)
 			}
 		}
+	}
 
-		// Update global statistics
-		m.globalStats.CurrentMemoryUsage = m.calculateCurrentMemoryUsage()
-		if m.globalStats.CurrentMemoryUsage > m.globalStats.PeakMemoryUsage {
-			m.globalStats.PeakMemoryUsage = m.globalStats.CurrentMemoryUsage
-		}
+	if reindex {
+		m.reindexLocked("")
+	}
+
+	// Update global statistics
+	m.globalStats.CurrentMemoryUsage = m.calculateCurrentMemoryUsage()
+	if m.globalStats.CurrentMemoryUsage > m.globalStats.PeakMemoryUsage {
+		m.globalStats.PeakMemoryUsage = m.globalStats.CurrentMemoryUsage
 	}
 
+	// Refresh the caches of banks that are mapped in
+	m.updateBankCachesLocked("")
+	m.publishLocked()
+
 	// Update average operation time
 	duration := time.Since(updateStart)
 	m.updateAverageOperationTime(duration)
@@ -407,56 +455,44 @@ func (m *Manager) Update(memoryData map[uint32][]byte) {
 	m.debugLog("Updated %d memory blocks in %v", len(memoryData), duration)
 }
 
-// applyFrozenProperties writes back frozen values to memory with enhanced tracking
-func (m *Manager) applyFrozenProperties(blockAddress uint32, blockData []byte) {
-	m.frozenMu.RLock()
-	frozenProps := make(map[uint32]*FrozenProperty)
-	for addr, prop := range m.frozenProps {
-		frozenProps[addr] = prop
+// UpdateDomain stores blocks read from a named memory domain in the domain's
+// namespace. The empty domain is the system bus and goes through Update.
+func (m *Manager) UpdateDomain(domain string, memoryData map[uint32][]byte) {
+	if domain == "" {
+		m.Update(memoryData)
+		return
 	}
-	m.frozenMu.RUnlock()
 
-	for frozenAddr, frozen := range frozenProps {
-		// Check if this frozen property is within the current block
-		blockEnd := blockAddress + uint32(len(blockData)) - 1
-		frozenEnd := frozenAddr + uint32(len(frozen.Data)) - 1
-
-		if frozenAddr >= blockAddress && frozenEnd <= blockEnd {
-			// Calculate offset within the block
-			offset := frozenAddr - blockAddress
-
-			// Check if the data has changed from frozen value
-			changed := false
-			for i, b := range frozen.Data {
-				if offset+uint32(i) < uint32(len(blockData)) && blockData[offset+uint32(i)] != b {
-					changed = true
-					break
-				}
-			}
-
-			// If changed, restore frozen value and track attempt
-			if changed {
-				m.frozenMu.Lock()
-				if frozen, exists := m.frozenProps[frozenAddr]; exists {
-					copy(blockData[offset:offset+uint32(len(frozen.Data))], frozen.Data)
-					frozen.LastWritten = time.Now()
-					frozen.WriteAttempts++
-					frozen.LastWriteAttempt = time.Now()
-				}
-				m.frozenMu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-				m.debugLog("Restored frozen property at 0x%X", frozenAddr)
-			}
+	m.globalStats.TotalReads++
+	reindex := false
+	for address, data := range memoryData {
+		m.updateFragment(domain, address, data)
+		if idx := m.indexes[domain]; idx == nil || !idx.set(address, m.namespaces[domain].Fragments[address].Data) {
+			reindex = true
 		}
 	}
+	if reindex {
+		m.reindexLocked(domain)
+	}
+	m.updateBankCachesLocked(domain)
+	m.publishLocked()
+
+	m.debugLog("Updated %d blocks in domain %s", len(memoryData), domain)
 }
 
 // updateFragment updates a memory fragment in a namespace with enhanced metadata
 func (m *Manager) updateFragment(namespace string, address uint32, data []byte) {
 	if m.namespaces[namespace] == nil {
+		description := "Default namespace"
+		if namespace != "default" {
+			description = fmt.Sprintf("Memory domain %s", namespace)
+		}
 		m.namespaces[namespace] = &MemoryNamespace{
 			Name:         namespace,
-			Description:  "Default namespace",
+			Description:  description,
 			Fragments:    make(map[uint32]*MemoryFragment),
 			AccessPolicy: "read_write",
 			Created:      time.Now(),
@@ -466,27 +502,30 @@ func (m *Manager) updateFragment(namespace string, address uint32, data []byte)
 
 	ns := m.namespaces[namespace]
 	fragment := ns.Fragments[address]
-	if fragment == nil {
+	if fragment == nil || len(fragment.Data) != len(data) {
 		fragment = &MemoryFragment{
-			StartAddress: address,
-			Data:         make([]byte, len(data)),
-			Cached:       true,
-			Metadata:     make(map[string]interface{}),
+			StartAddress:  address,
+			Data:          make([]byte, len(data)),
+			AccessPattern: "static",
+			Cached:        true,
+			Metadata:      make(map[string]interface{}),
 		}
 		ns.Fragments[address] = fragment
+		ns.TotalSize = m.calculateNamespaceSize(ns)
+		ns.UsedSize = ns.TotalSize // Simplified calculation
+	} else {
+		m.recordHeatLocked(fragment, data)
 	}
 
-	// Update fragment data and metadata
-	copy(fragment.Data, data)
+	// Update fragment data and metadata. The data is replaced rather than
+	// overwritten since published snapshots may share the old bytes.
+	fragment.Data = append([]byte(nil), data...)
 	fragment.LastUpdated = time.Now()
-	fragment.AccessCount++
 	fragment.Checksum = m.calculateChecksum(data)
 	fragment.Dirty = true
 
 	// Update namespace statistics
 	ns.LastAccessed = time.Now()
-	ns.TotalSize = m.calculateNamespaceSize(ns)
-	ns.UsedSize = ns.TotalSize // Simplified calculation
 }
 
 // ===== ENHANCED FROZEN PROPERTIES =====
@@ -647,22 +686,7 @@ func (m *Manager) UpdatePropertyState(name string, value interface{}, bytes []by
 
 	updateStart := time.Now()
 
-	state := m.propertyStates[name]
-	if state == nil {
-		state = &PropertyState{
-			Name:           name,
-			Address:        address,
-			Performance:    &PerformanceMetrics{FirstAccess: time.Now()},
-			Events:         make([]PropertyEvent, 0),
-			ValueHistory:   make([]ValueHistoryEntry, 0),
-			MaxHistorySize: 100, // Default history size
-			Dependencies:   make([]string, 0),
-			Dependents:     make([]string, 0),
-			UIHints:        make(map[string]interface{}),
-			Statistics:     &PropertyStatistics{},
-		}
-		m.propertyStates[name] = state
-	}
+	state := m.propertyStateLocked(name, address)
 
 	// Check if value actually changed
 	changed := false
@@ -716,7 +740,7 @@ func (m *Manager) UpdatePropertyState(name string, value interface{}, bytes []by
 	state.Performance.AvgReadTime = (totalReadTime + duration) / time.Duration(state.Performance.ReadCount)
 
 	// Check frozen status without holding main mutex
-	state.Frozen = m.isFrozenNoLock(address)
+	state.Frozen = m.isFrozenNoLock(address) || state.Freeze != nil
 
 	if changed {
 		state.Performance.WriteCount++
@@ -725,6 +749,27 @@ func (m *Manager) UpdatePropertyState(name string, value interface{}, bytes []by
 	m.debugLog("Updated property state for %s: value=%v, changed=%t", name, value, changed)
 }
 
+// propertyStateLocked returns the state of a property, creating it on first use
+func (m *Manager) propertyStateLocked(name string, address uint32) *PropertyState {
+	state := m.propertyStates[name]
+	if state == nil {
+		state = &PropertyState{
+			Name:           name,
+			Address:        address,
+			Performance:    &PerformanceMetrics{FirstAccess: time.Now()},
+			Events:         make([]PropertyEvent, 0),
+			ValueHistory:   make([]ValueHistoryEntry, 0),
+			MaxHistorySize: 100, // Default history size
+			Dependencies:   make([]string, 0),
+			Dependents:     make([]string, 0),
+			UIHints:        make(map[string]interface{}),
+			Statistics:     &PropertyStatistics{},
+		}
+		m.propertyStates[name] = state
+	}
+	return state
+}
+
 // addValueToHistory adds a value to property history with size management
 func (m *Manager) addValueToHistory(state *PropertyState, value interface{}, source string) {
 	entry := ValueHistoryEntry{
@@ -852,59 +897,40 @@ func (m *Manager) InvalidateCache(pattern string) int {
 
 // ReadBytes reads bytes from a specific address with enhanced error handling and performance tracking
 func (m *Manager) ReadBytes(address uint32, length uint32) ([]byte, error) {
+	return m.ReadDomainBytes("", address, length)
+}
+
+// ReadDomainBytes reads bytes from an address inside a named memory domain.
+// The empty domain is the system bus.
+func (m *Manager) ReadDomainBytes(domain string, address uint32, length uint32) ([]byte, error) {
 	readStart := time.Now()
-	m.debugLog("📖 ReadBytes: address=0x%X, length=%d", address, length)
+	if m.debugMode {
+		m.debugLog("📖 ReadBytes: address=%s, length=%d", FormatAddress(domain, address), length)
+	}
 
-	// Update global statistics
-	m.mu.Lock()
-	m.globalStats.TotalReads++
-	m.mu.Unlock()
+	// Reads go to the published snapshot and never wait on an update
+	result, err := m.Snapshot().ReadDomainBytes(domain, address, length)
 
-	// Create a local copy to work with (prevents holding lock too long)
-	var targetBlock []byte
-	var blockStart uint32
-	var found bool
+	atomic.AddUint64(&m.readCount, 1)
+	atomic.AddInt64(&m.readNanos, int64(time.Since(readStart)))
 
-	// Very fast critical section - just find and copy the block
-	m.mu.RLock()
-	for bs, bd := range m.blocks {
-		blockEnd := bs + uint32(len(bd)) - 1
-		if address >= bs && address <= blockEnd {
-			blockStart = bs
-			targetBlock = make([]byte, len(bd))
-			copy(targetBlock, bd)
-			found = true
-			break
-		}
+	if err != nil {
+		atomic.AddUint64(&m.readErrors, 1)
+		return nil, err
 	}
-	m.mu.RUnlock()
 
-	if !found {
-		m.mu.Lock()
-		m.globalStats.TotalErrors++
-		m.mu.Unlock()
-		return nil, fmt.Errorf("address 0x%X not found in loaded memory", address)
+	if m.debugMode {
+		m.debugLog("✅ Read %d bytes from %s in %v", length, FormatAddress(domain, address), time.Since(readStart))
 	}
+	return result, nil
+}
 
-	// Work with copied data (no locks needed)
-	offset := address - blockStart
-	if offset+length > uint32(len(targetBlock)) {
-		m.mu.Lock()
-		m.globalStats.TotalErrors++
-		m.mu.Unlock()
-		return nil, fmt.Errorf("not enough data at address 0x%X (requested %d bytes, available %d)",
-			address, length, uint32(len(targetBlock))-offset)
+// FormatAddress renders an address with its domain prefix, e.g. "sram:0xA598"
+func FormatAddress(domain string, address uint32) string {
+	if domain == "" {
+		return fmt.Sprintf("0x%X", address)
 	}
-
-	result := make([]byte, length)
-	copy(result, targetBlock[offset:offset+length])
-
-	// Update performance statistics
-	duration := time.Since(readStart)
-	m.updateAverageOperationTime(duration)
-
-	m.debugLog("✅ Read %d bytes from 0x%X in %v", length, address, duration)
-	return result, nil
+	return fmt.Sprintf("%s:0x%X", domain, address)
 }
 
 // ===== BATCH OPERATIONS =====
@@ -1050,6 +1076,11 @@ func (m *Manager) copyPropertyState(state *PropertyState) *PropertyState {
 		WatchCondition:  state.WatchCondition,
 	}
 
+	if state.Freeze != nil {
+		freeze := *state.Freeze
+		copy.Freeze = &freeze
+	}
+
 	// Copy performance metrics
 	if state.Performance != nil {
 		copy.Performance = &PerformanceMetrics{
@@ -1367,6 +1398,31 @@ func (m *Manager) WriteBytes(address uint32, data []byte) []byte {
 	return m.writeBytesInternal(address, data)
 }
 
+// WriteDomainBytes updates the local copy of an address inside a named memory
+// domain. The empty domain is the system bus and goes through WriteBytes.
+func (m *Manager) WriteDomainBytes(domain string, address uint32, data []byte) []byte {
+	if domain == "" {
+		return m.writeBytesInternal(address, data)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.globalStats.TotalWrites++
+	if idx := m.indexes[domain]; idx != nil {
+		idx.write(address, data, func(start uint32, block []byte) {
+			m.namespaces[domain].Fragments[start].Data = block
+		})
+	}
+	m.unpublished.Store(true)
+
+	result := make([]byte, len(data))
+	copy(result, data)
+
+	m.debugLog("✍️ Wrote %d bytes to %s", len(data), FormatAddress(domain, address))
+	return result
+}
+
 // writeBytesInternal is the internal implementation of WriteBytes
 func (m *Manager) writeBytesInternal(address uint32, data []byte) []byte {
 	writeStart := time.Now()
@@ -1376,20 +1432,13 @@ func (m *Manager) writeBytesInternal(address uint32, data []byte) []byte {
 	// Update global statistics
 	m.globalStats.TotalWrites++
 
-	// Find the block containing this address and update it
-	for blockStart, blockData := range m.blocks {
-		blockEnd := blockStart + uint32(len(blockData)) - 1
-
-		if address >= blockStart && address <= blockEnd {
-			offset := address - blockStart
-
-			// Update our internal copy
-			if offset+uint32(len(data)) <= uint32(len(blockData)) {
-				copy(blockData[offset:], data)
-			}
-			break
-		}
+	// Update our internal copy, crossing into adjacent blocks as needed
+	if idx := m.indexes[""]; idx != nil {
+		idx.write(address, data, func(start uint32, block []byte) {
+			m.blocks[start] = block
+		})
 	}
+	m.unpublished.Store(true)
 
 	// Update performance statistics
 	duration := time.Since(writeStart)
@@ -1451,6 +1500,56 @@ func (m *Manager) GetLoadedBlocks() map[uint32]int {
 	return result
 }
 
+// RetainBlocks drops loaded blocks (and their namespace fragments) in domain that
+// do not match ranges, a map of start address to length. It is used when the
+// set of blocks being read changes so stale data cannot shadow fresh reads.
+func (m *Manager) RetainBlocks(domain string, ranges map[uint32]uint32) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	if domain != "" {
+		ns := m.namespaces[domain]
+		if ns == nil {
+			return 0
+		}
+		for address, fragment := range ns.Fragments {
+			if length, ok := ranges[address]; ok && length == uint32(len(fragment.Data)) {
+				continue
+			}
+			delete(ns.Fragments, address)
+			removed++
+		}
+		ns.TotalSize = m.calculateNamespaceSize(ns)
+		ns.UsedSize = ns.TotalSize
+		if removed > 0 {
+			m.reindexLocked(domain)
+			m.publishLocked()
+		}
+		return removed
+	}
+
+	for address, data := range m.blocks {
+		if length, ok := ranges[address]; ok && length == uint32(len(data)) {
+			continue
+		}
+		delete(m.blocks, address)
+		if ns := m.namespaces["default"]; ns != nil {
+			delete(ns.Fragments, address)
+			ns.TotalSize = m.calculateNamespaceSize(ns)
+			ns.UsedSize = ns.TotalSize
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		m.reindexLocked("")
+		m.publishLocked()
+	}
+	m.globalStats.CurrentMemoryUsage = m.calculateCurrentMemoryUsage()
+	return removed
+}
+
 // GetMemoryNamespaces returns all memory namespaces with enhanced metadata
 func (m *Manager) GetMemoryNamespaces() map[string]*MemoryNamespace {
 	m.mu.RLock()
@@ -1521,12 +1620,20 @@ func (m *Manager) GetGlobalStatistics() *GlobalStatistics {
 		m.globalStats.CacheEfficiency = float64(totalCacheHits) / float64(totalCacheOps) * 100
 	}
 
+	// Fold in the lock-free read statistics
+	reads := atomic.LoadUint64(&m.readCount)
+	avgOperationTime := m.globalStats.AvgOperationTime
+	if ops := m.globalStats.TotalReads + m.globalStats.TotalWrites + reads; ops > 0 {
+		otherTime := time.Duration(m.globalStats.TotalReads+m.globalStats.TotalWrites) * avgOperationTime
+		avgOperationTime = (otherTime + time.Duration(atomic.LoadInt64(&m.readNanos))) / time.Duration(ops)
+	}
+
 	// Return copy to prevent modification
 	return &GlobalStatistics{
-		TotalReads:            m.globalStats.TotalReads,
+		TotalReads:            m.globalStats.TotalReads + reads,
 		TotalWrites:           m.globalStats.TotalWrites,
-		TotalErrors:           m.globalStats.TotalErrors,
-		AvgOperationTime:      m.globalStats.AvgOperationTime,
+		TotalErrors:           m.globalStats.TotalErrors + atomic.LoadUint64(&m.readErrors),
+		AvgOperationTime:      avgOperationTime,
 		PeakMemoryUsage:       m.globalStats.PeakMemoryUsage,
 		CurrentMemoryUsage:    m.globalStats.CurrentMemoryUsage,
 		CacheEfficiency:       m.globalStats.CacheEfficiency,
@@ -1541,6 +1648,10 @@ func (m *Manager) ResetStatistics() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	atomic.StoreUint64(&m.readCount, 0)
+	atomic.StoreUint64(&m.readErrors, 0)
+	atomic.StoreInt64(&m.readNanos, 0)
+
 	m.globalStats = &GlobalStatistics{
 		UptimeStart: m.globalStats.UptimeStart, // Keep original uptime start
 		LastReset:   time.Now(),
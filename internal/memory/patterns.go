@@ -0,0 +1,323 @@
+package memory
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== CHANGE PATTERN DETECTION =====
+
+const (
+	minPatternConfidence = 0.5                   // Patterns below this are not reported at all
+	minPatternSamples    = 6                     // Changes needed before any pattern is considered
+	coChangeWindow       = 10 * time.Millisecond // Changes this close belong to the same read
+)
+
+// patternHistory is a copy of one property's value history taken for analysis
+type patternHistory struct {
+	name    string
+	times   []time.Time
+	values  []interface{}
+	numbers []float64 // nil unless every value is numeric
+}
+
+// AnalyzePatterns detects periodic changes, thresholds, repeating sequences
+// and correlated properties in the value history of every property and
+// stores them in the property states. It returns the patterns that reached
+// confirmConfidence in this pass and had not before.
+func (m *Manager) AnalyzePatterns(confirmConfidence float64) []PropertyChangePattern {
+	// Copy the histories so detection runs without holding the state lock
+	m.stateMu.RLock()
+	histories := make([]*patternHistory, 0, len(m.propertyStates))
+	for name, state := range m.propertyStates {
+		if len(state.ValueHistory) < minPatternSamples {
+			continue
+		}
+		// Entries from different sources may be appended out of order
+		entries := append([]ValueHistoryEntry(nil), state.ValueHistory...)
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.Before(entries[j].Timestamp) })
+
+		history := &patternHistory{name: name, numbers: make([]float64, 0, len(entries))}
+		for _, entry := range entries {
+			history.times = append(history.times, entry.Timestamp)
+			history.values = append(history.values, entry.Value)
+			if number, ok := m.convertToFloat64(entry.Value); ok && history.numbers != nil {
+				history.numbers = append(history.numbers, number)
+			} else {
+				history.numbers = nil
+			}
+		}
+		histories = append(histories, history)
+	}
+	m.stateMu.RUnlock()
+
+	sort.Slice(histories, func(i, j int) bool { return histories[i].name < histories[j].name })
+
+	now := time.Now()
+	detected := make(map[string][]PropertyChangePattern)
+	add := func(pattern *PropertyChangePattern) {
+		if pattern == nil || pattern.Confidence < minPatternConfidence {
+			return
+		}
+		pattern.LastDetected = now
+		detected[pattern.PropertyName] = append(detected[pattern.PropertyName], *pattern)
+	}
+
+	for _, history := range histories {
+		add(detectPeriodic(history))
+		add(detectThreshold(history))
+		add(detectSequence(history))
+	}
+	for i, a := range histories {
+		for _, b := range histories[i+1:] {
+			if pattern := detectCorrelation(a, b); pattern != nil && pattern.Confidence >= minPatternConfidence {
+				add(pattern)
+				mirrored := *pattern
+				mirrored.PropertyName, mirrored.RelatedProperty = b.name, a.name
+				mirrored.Description = fmt.Sprintf("Changes together with %s", a.name)
+				add(&mirrored)
+			}
+		}
+	}
+
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	// Patterns found before a history shrank below the minimum no longer hold
+	for _, state := range m.propertyStates {
+		if len(state.ValueHistory) < minPatternSamples {
+			state.Patterns = nil
+		}
+	}
+
+	var confirmed []PropertyChangePattern
+	for _, history := range histories {
+		state := m.propertyStates[history.name]
+		if state == nil {
+			continue
+		}
+
+		previous := make(map[string]PropertyChangePattern, len(state.Patterns))
+		for _, pattern := range state.Patterns {
+			previous[patternKey(pattern)] = pattern
+		}
+
+		patterns := detected[history.name]
+		for i := range patterns {
+			before, seen := previous[patternKey(patterns[i])]
+			patterns[i].DetectionCount = before.DetectionCount + 1
+			if patterns[i].Confidence >= confirmConfidence && (!seen || before.Confidence < confirmConfidence) {
+				confirmed = append(confirmed, patterns[i])
+			}
+		}
+		state.Patterns = patterns
+	}
+
+	return confirmed
+}
+
+// patternKey identifies a pattern across analysis passes
+func patternKey(pattern PropertyChangePattern) string {
+	return pattern.PatternType + "|" + pattern.RelatedProperty
+}
+
+// detectPeriodic finds properties that change at a steady interval, like
+// frame counters and timers. Confidence falls as the intervals vary.
+func detectPeriodic(history *patternHistory) *PropertyChangePattern {
+	intervals := make([]float64, 0, len(history.times)-1)
+	for i := 1; i < len(history.times); i++ {
+		intervals = append(intervals, float64(history.times[i].Sub(history.times[i-1])))
+	}
+
+	mean, stddev := meanStddev(intervals)
+	if mean <= 0 {
+		return nil
+	}
+
+	period := time.Duration(mean)
+	description := fmt.Sprintf("Changes every %v", period.Round(time.Millisecond))
+	if step, ok := constantStep(history.numbers); ok {
+		description = fmt.Sprintf("Counts by %v every %v", step, period.Round(time.Millisecond))
+	}
+
+	return &PropertyChangePattern{
+		PropertyName: history.name,
+		PatternType:  "periodic",
+		Confidence:   clamp01(1-stddev/mean) * sampleWeight(len(intervals)),
+		Period:       period,
+		Description:  description,
+	}
+}
+
+// detectThreshold finds a floor or ceiling the value keeps returning to but
+// never passes, like health capped at its maximum or a timer stopping at 0
+func detectThreshold(history *patternHistory) *PropertyChangePattern {
+	values := history.numbers
+	if len(values) == 0 {
+		return nil
+	}
+
+	low, high := values[0], values[0]
+	for _, value := range values {
+		low, high = math.Min(low, value), math.Max(high, value)
+	}
+	if low == high {
+		return nil
+	}
+
+	// The first sample may predate the bound being reached, so only later visits count
+	atLow, atHigh := 0, 0
+	for _, value := range values[1:] {
+		if value == low {
+			atLow++
+		}
+		if value == high {
+			atHigh++
+		}
+	}
+
+	bound, visits, kind := high, atHigh, "ceiling"
+	if atLow > atHigh {
+		bound, visits, kind = low, atLow, "floor"
+	}
+	if visits < 3 {
+		return nil
+	}
+
+	// A bound visited on a large share of changes is a clamp, not chance
+	share := float64(visits) / float64(len(values)-1)
+	return &PropertyChangePattern{
+		PropertyName: history.name,
+		PatternType:  "threshold",
+		Confidence:   clamp01(share*3) * sampleWeight(visits*2),
+		Threshold:    bound,
+		Description:  fmt.Sprintf("Returns to a %s of %v (%d times)", kind, bound, visits),
+	}
+}
+
+// detectSequence finds values that cycle through the same sequence, like
+// animation frames or a menu cursor wrapping around
+func detectSequence(history *patternHistory) *PropertyChangePattern {
+	keys := make([]string, len(history.values))
+	for i, value := range history.values {
+		keys[i] = fmt.Sprintf("%v", value)
+	}
+
+	for length := 2; length*3 <= len(keys); length++ {
+		matches := 0
+		for i := length; i < len(keys); i++ {
+			if keys[i] == keys[i-length] {
+				matches++
+			}
+		}
+		share := float64(matches) / float64(len(keys)-length)
+		if share < minPatternConfidence {
+			continue
+		}
+
+		cycle := keys[len(keys)-length:]
+		return &PropertyChangePattern{
+			PropertyName: history.name,
+			PatternType:  "sequence",
+			Confidence:   share * sampleWeight(len(keys)/length*2),
+			Description:  fmt.Sprintf("Cycles through [%s]", strings.Join(cycle, " ")),
+		}
+	}
+	return nil
+}
+
+// detectCorrelation finds two properties that change in the same reads, like
+// a position's X and Y or a counter and the flag it drives
+func detectCorrelation(a, b *patternHistory) *PropertyChangePattern {
+	together := 0
+	j := 0
+	for _, t := range a.times {
+		for j < len(b.times) && b.times[j].Before(t.Add(-coChangeWindow)) {
+			j++
+		}
+		if j < len(b.times) && b.times[j].Sub(t) <= coChangeWindow {
+			together++
+			j++
+		}
+	}
+	if together < minPatternSamples {
+		return nil
+	}
+
+	// Only changes while both histories overlap count, so a short history is not penalized
+	start, end := a.times[0], a.times[len(a.times)-1]
+	if b.times[0].After(start) {
+		start = b.times[0]
+	}
+	if b.times[len(b.times)-1].Before(end) {
+		end = b.times[len(b.times)-1]
+	}
+	changes := max(countBetween(a.times, start, end), countBetween(b.times, start, end))
+	if changes == 0 {
+		return nil
+	}
+
+	return &PropertyChangePattern{
+		PropertyName:    a.name,
+		PatternType:     "correlation",
+		Confidence:      clamp01(float64(together)/float64(changes)) * sampleWeight(together),
+		RelatedProperty: b.name,
+		Description:     fmt.Sprintf("Changes together with %s", b.name),
+	}
+}
+
+// countBetween counts the times within [start, end], widened by the co-change window
+func countBetween(times []time.Time, start, end time.Time) int {
+	count := 0
+	for _, t := range times {
+		if !t.Before(start.Add(-coChangeWindow)) && !t.After(end.Add(coChangeWindow)) {
+			count++
+		}
+	}
+	return count
+}
+
+// constantStep reports whether consecutive values always differ by the same amount
+func constantStep(values []float64) (float64, bool) {
+	if len(values) < 2 {
+		return 0, false
+	}
+	step := values[1] - values[0]
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return 0, false
+		}
+	}
+	return step, true
+}
+
+// meanStddev returns the mean and standard deviation of values
+func meanStddev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// sampleWeight scales confidence down while there are few samples, reaching 1 at 20
+func sampleWeight(samples int) float64 {
+	return clamp01(float64(samples) / 20)
+}
+
+// clamp01 limits value to the range 0 to 1
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnalyzePatternsOrdersHistory(t *testing.T) {
+	m := NewManager()
+
+	// A counter stepping every 100ms whose history was appended with
+	// neighbouring entries swapped
+	start := time.Now().Add(-time.Minute)
+	state := m.propertyStateLocked("frames", 0xC000)
+	for n := 0; n < 30; n++ {
+		i := n ^ 1
+		state.ValueHistory = append(state.ValueHistory, ValueHistoryEntry{
+			Value:     uint8(i),
+			Timestamp: start.Add(time.Duration(i) * 100 * time.Millisecond),
+			Source:    "update",
+		})
+	}
+
+	m.AnalyzePatterns(0.9)
+
+	var periodic *PropertyChangePattern
+	for _, pattern := range m.GetPropertyState("frames").Patterns {
+		if pattern.PatternType == "periodic" {
+			periodic = &pattern
+		}
+	}
+	if periodic == nil {
+		t.Fatal("no periodic pattern detected")
+	}
+	if periodic.Period != 100*time.Millisecond || periodic.Confidence < 0.9 {
+		t.Errorf("periodic pattern every %v with confidence %.2f, want 100ms with at least 0.9", periodic.Period, periodic.Confidence)
+	}
+}
+
+func TestAnalyzePatternsClearsShortHistories(t *testing.T) {
+	m := NewManager()
+
+	state := m.propertyStateLocked("hp", 0xD000)
+	state.Patterns = []PropertyChangePattern{{PropertyName: "hp", PatternType: "threshold", Confidence: 1}}
+	for i := 0; i < minPatternSamples-1; i++ {
+		state.ValueHistory = append(state.ValueHistory, ValueHistoryEntry{Value: uint8(i), Timestamp: time.Now()})
+	}
+
+	m.AnalyzePatterns(0.9)
+
+	if patterns := m.GetPropertyState("hp").Patterns; len(patterns) != 0 {
+		t.Errorf("patterns kept for a history of %d samples: %+v", minPatternSamples-1, patterns)
+	}
+}
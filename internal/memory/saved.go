@@ -0,0 +1,317 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===== SAVED SNAPSHOTS =====
+
+// snapshotNamePattern matches names usable as a saved snapshot's file name
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// SavedSnapshot is a named capture of loaded memory and decoded property values
+type SavedSnapshot struct {
+	Name       string                 `json:"name"`
+	Mapper     string                 `json:"mapper,omitempty"`
+	Sequence   uint64                 `json:"sequence"`
+	Frame      uint64                 `json:"frame"`
+	CreatedAt  time.Time              `json:"created_at"`
+	Blocks     []SavedBlock           `json:"blocks,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SavedBlock is the bytes of one loaded memory block
+type SavedBlock struct {
+	Domain string `json:"domain,omitempty"`
+	Start  uint32 `json:"start"`
+	Data   []byte `json:"data"` // base64 in JSON
+}
+
+// SavedSnapshotInfo describes a saved snapshot without its contents
+type SavedSnapshotInfo struct {
+	Name       string    `json:"name"`
+	Mapper     string    `json:"mapper,omitempty"`
+	Sequence   uint64    `json:"sequence"`
+	Frame      uint64    `json:"frame"`
+	CreatedAt  time.Time `json:"created_at"`
+	Bytes      int       `json:"bytes"`
+	Properties int       `json:"properties"`
+}
+
+// SnapshotDiff is the difference between two snapshots
+type SnapshotDiff struct {
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	ChangedBytes int             `json:"changed_bytes"`
+	Ranges       []ByteRangeDiff `json:"ranges"`
+	Properties   []PropertyDiff  `json:"properties"`
+}
+
+// ByteRangeDiff is a run of consecutive changed bytes
+type ByteRangeDiff struct {
+	Domain     string `json:"domain,omitempty"`
+	Address    uint32 `json:"address"`
+	AddressHex string `json:"address_hex"`
+	Length     int    `json:"length"`
+	Old        string `json:"old"` // hex
+	New        string `json:"new"` // hex
+}
+
+// PropertyDiff is a property whose decoded value differs between two snapshots.
+// A property missing from one side has a nil value there.
+type PropertyDiff struct {
+	Name string      `json:"name"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// Capture copies the loaded blocks of the snapshot into a saved snapshot.
+// Cached bank bytes are not included.
+func (s *Snapshot) Capture(name string) *SavedSnapshot {
+	saved := &SavedSnapshot{
+		Name:      name,
+		Sequence:  s.Sequence,
+		Frame:     s.Frame,
+		CreatedAt: time.Now(),
+	}
+
+	for domain, idx := range s.indexes {
+		for i, start := range idx.starts {
+			saved.Blocks = append(saved.Blocks, SavedBlock{
+				Domain: domain,
+				Start:  start,
+				Data:   append([]byte(nil), idx.blocks[i]...),
+			})
+		}
+	}
+	sort.Slice(saved.Blocks, func(i, j int) bool {
+		if saved.Blocks[i].Domain != saved.Blocks[j].Domain {
+			return saved.Blocks[i].Domain < saved.Blocks[j].Domain
+		}
+		return saved.Blocks[i].Start < saved.Blocks[j].Start
+	})
+
+	return saved
+}
+
+// Info describes the saved snapshot without its contents
+func (s *SavedSnapshot) Info() SavedSnapshotInfo {
+	size := 0
+	for _, block := range s.Blocks {
+		size += len(block.Data)
+	}
+	return SavedSnapshotInfo{
+		Name:       s.Name,
+		Mapper:     s.Mapper,
+		Sequence:   s.Sequence,
+		Frame:      s.Frame,
+		CreatedAt:  s.CreatedAt,
+		Bytes:      size,
+		Properties: len(s.Properties),
+	}
+}
+
+// DiffSnapshots compares two snapshots byte by byte and property by property.
+// Only addresses present in both snapshots are compared.
+func DiffSnapshots(from, to *SavedSnapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{
+		From:       from.Name,
+		To:         to.Name,
+		Ranges:     make([]ByteRangeDiff, 0),
+		Properties: make([]PropertyDiff, 0),
+	}
+
+	for _, oldBlock := range from.Blocks {
+		for _, newBlock := range to.Blocks {
+			if oldBlock.Domain != newBlock.Domain {
+				continue
+			}
+			diff.diffBlocks(oldBlock, newBlock)
+		}
+	}
+	sort.Slice(diff.Ranges, func(i, j int) bool {
+		if diff.Ranges[i].Domain != diff.Ranges[j].Domain {
+			return diff.Ranges[i].Domain < diff.Ranges[j].Domain
+		}
+		return diff.Ranges[i].Address < diff.Ranges[j].Address
+	})
+
+	names := make(map[string]bool)
+	for name := range from.Properties {
+		names[name] = true
+	}
+	for name := range to.Properties {
+		names[name] = true
+	}
+	for name := range names {
+		oldValue, newValue := from.Properties[name], to.Properties[name]
+		// Values loaded from disk have been through JSON, so compare their encodings
+		oldJSON, _ := json.Marshal(oldValue)
+		newJSON, _ := json.Marshal(newValue)
+		if !bytes.Equal(oldJSON, newJSON) {
+			diff.Properties = append(diff.Properties, PropertyDiff{Name: name, Old: oldValue, New: newValue})
+		}
+	}
+	sort.Slice(diff.Properties, func(i, j int) bool { return diff.Properties[i].Name < diff.Properties[j].Name })
+
+	return diff
+}
+
+// diffBlocks adds the runs of changed bytes where two blocks overlap
+func (d *SnapshotDiff) diffBlocks(oldBlock, newBlock SavedBlock) {
+	start := uint64(oldBlock.Start)
+	if uint64(newBlock.Start) > start {
+		start = uint64(newBlock.Start)
+	}
+	end := uint64(oldBlock.Start) + uint64(len(oldBlock.Data))
+	if newEnd := uint64(newBlock.Start) + uint64(len(newBlock.Data)); newEnd < end {
+		end = newEnd
+	}
+
+	runStart := uint64(0)
+	inRun := false
+	flush := func(runEnd uint64) {
+		oldData := oldBlock.Data[runStart-uint64(oldBlock.Start) : runEnd-uint64(oldBlock.Start)]
+		newData := newBlock.Data[runStart-uint64(newBlock.Start) : runEnd-uint64(newBlock.Start)]
+		d.Ranges = append(d.Ranges, ByteRangeDiff{
+			Domain:     oldBlock.Domain,
+			Address:    uint32(runStart),
+			AddressHex: FormatAddress(oldBlock.Domain, uint32(runStart)),
+			Length:     len(oldData),
+			Old:        hex.EncodeToString(oldData),
+			New:        hex.EncodeToString(newData),
+		})
+		d.ChangedBytes += len(oldData)
+	}
+
+	for address := start; address < end; address++ {
+		changed := oldBlock.Data[address-uint64(oldBlock.Start)] != newBlock.Data[address-uint64(newBlock.Start)]
+		switch {
+		case changed && !inRun:
+			runStart, inRun = address, true
+		case !changed && inRun:
+			flush(address)
+			inRun = false
+		}
+	}
+	if inRun {
+		flush(end)
+	}
+}
+
+// SnapshotStore persists saved snapshots as JSON files in a directory
+type SnapshotStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewSnapshotStore creates a store for the snapshots in dir, creating it if needed
+func NewSnapshotStore(dir string) (*SnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return &SnapshotStore{dir: dir}, nil
+}
+
+// Save writes a snapshot, replacing any snapshot with the same name
+func (st *SnapshotStore) Save(snapshot *SavedSnapshot) error {
+	if !snapshotNamePattern.MatchString(snapshot.Name) {
+		return fmt.Errorf("snapshot name %q must start with a letter or digit and contain only letters, digits, '.', '_' and '-'", snapshot.Name)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot %s: %w", snapshot.Name, err)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	// Write to a temporary file first so a crash never leaves a truncated snapshot
+	path := st.path(snapshot.Name)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot %s: %w", snapshot.Name, err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to write snapshot %s: %w", snapshot.Name, err)
+	}
+	return nil
+}
+
+// Load reads a snapshot by name
+func (st *SnapshotStore) Load(name string) (*SavedSnapshot, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("snapshot %s not found", name)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	data, err := os.ReadFile(st.path(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot %s not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", name, err)
+	}
+
+	var snapshot SavedSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", name, err)
+	}
+	return &snapshot, nil
+}
+
+// List describes every saved snapshot, oldest first. Files that fail to load are skipped.
+func (st *SnapshotStore) List() ([]SavedSnapshotInfo, error) {
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	infos := make([]SavedSnapshotInfo, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		if entry.IsDir() || name == entry.Name() {
+			continue
+		}
+		if snapshot, err := st.Load(name); err == nil {
+			infos = append(infos, snapshot.Info())
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.Before(infos[j].CreatedAt) })
+	return infos, nil
+}
+
+// Delete removes a saved snapshot
+func (st *SnapshotStore) Delete(name string) error {
+	if !snapshotNamePattern.MatchString(name) {
+		return fmt.Errorf("snapshot %s not found", name)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if err := os.Remove(st.path(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("snapshot %s not found", name)
+		}
+		return fmt.Errorf("failed to delete snapshot %s: %w", name, err)
+	}
+	return nil
+}
+
+// path returns the file a snapshot is stored in
+func (st *SnapshotStore) path(name string) string {
+	return filepath.Join(st.dir, name+".json")
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCaptureCopiesLoadedBlocks(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xD000: {5, 6}, 0xC000: {1, 2, 3}})
+	m.UpdateDomain("sram", map[uint32][]byte{0x0000: {9}})
+
+	saved := m.Snapshot().Capture("before")
+	m.WriteBytes(0xC000, []byte{0xFF})
+
+	want := []SavedBlock{
+		{Start: 0xC000, Data: []byte{1, 2, 3}},
+		{Start: 0xD000, Data: []byte{5, 6}},
+		{Domain: "sram", Start: 0x0000, Data: []byte{9}},
+	}
+	if len(saved.Blocks) != len(want) {
+		t.Fatalf("captured %d blocks, want %d", len(saved.Blocks), len(want))
+	}
+	for i, block := range saved.Blocks {
+		if block.Domain != want[i].Domain || block.Start != want[i].Start || !bytes.Equal(block.Data, want[i].Data) {
+			t.Errorf("block %d is %+v, want %+v", i, block, want[i])
+		}
+	}
+	if info := saved.Info(); info.Name != "before" || info.Bytes != 6 {
+		t.Errorf("info %+v, want 6 bytes of before", info)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &SavedSnapshot{
+		Name: "before",
+		Blocks: []SavedBlock{
+			{Start: 0xC000, Data: []byte{1, 2, 3, 4, 5, 6}},
+			{Domain: "sram", Start: 0x10, Data: []byte{7, 7}},
+		},
+		Properties: map[string]interface{}{"hp": 10, "name": "RED", "gone": true},
+	}
+	to := &SavedSnapshot{
+		Name: "after",
+		Blocks: []SavedBlock{
+			// Starts inside the old block and runs past it
+			{Start: 0xC002, Data: []byte{9, 9, 5, 8, 0xAA, 0xBB}},
+			{Domain: "sram", Start: 0x10, Data: []byte{7, 8}},
+		},
+		Properties: map[string]interface{}{"hp": 12, "name": "RED", "new": 1},
+	}
+
+	diff := DiffSnapshots(from, to)
+
+	want := []ByteRangeDiff{
+		{Address: 0xC002, AddressHex: "0xC002", Length: 2, Old: "0304", New: "0909"},
+		{Address: 0xC005, AddressHex: "0xC005", Length: 1, Old: "06", New: "08"},
+		{Domain: "sram", Address: 0x11, AddressHex: FormatAddress("sram", 0x11), Length: 1, Old: "07", New: "08"},
+	}
+	if len(diff.Ranges) != len(want) {
+		t.Fatalf("got ranges %+v, want %+v", diff.Ranges, want)
+	}
+	for i, r := range diff.Ranges {
+		if r != want[i] {
+			t.Errorf("range %d is %+v, want %+v", i, r, want[i])
+		}
+	}
+	if diff.ChangedBytes != 4 {
+		t.Errorf("%d changed bytes, want 4", diff.ChangedBytes)
+	}
+
+	var names []string
+	for _, p := range diff.Properties {
+		names = append(names, p.Name)
+	}
+	if len(names) != 3 || names[0] != "gone" || names[1] != "hp" || names[2] != "new" {
+		t.Errorf("changed properties %v, want gone, hp and new", names)
+	}
+	if diff.Properties[0].New != nil || diff.Properties[2].Old != nil {
+		t.Errorf("missing sides are %v and %v, want nil", diff.Properties[0].New, diff.Properties[2].Old)
+	}
+}
+
+func TestSnapshotStoreSaveReplaceAndDelete(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	store, err := NewSnapshotStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := &SavedSnapshot{Name: "key", Blocks: []SavedBlock{{Start: 0xC000, Data: []byte{1}}}, Properties: map[string]interface{}{"hp": 10}}
+	if err := store.Save(first); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving under the same name replaces the snapshot
+	second := &SavedSnapshot{Name: "key", Blocks: []SavedBlock{{Start: 0xC000, Data: []byte{2}}}, Properties: map[string]interface{}{"hp": 10}}
+	if err := store.Save(second); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.Load("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(loaded.Blocks[0].Data, []byte{2}) {
+		t.Errorf("loaded % X, want the replacement", loaded.Blocks[0].Data)
+	}
+
+	// Values that went through JSON still compare equal to live ones
+	if diff := DiffSnapshots(loaded, second); len(diff.Properties) != 0 || diff.ChangedBytes != 0 {
+		t.Errorf("a snapshot differs from its saved copy: %+v", diff)
+	}
+
+	// Leftover files that are not snapshots are skipped
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644)
+	if infos, err := store.List(); err != nil || len(infos) != 1 || infos[0].Name != "key" {
+		t.Errorf("listed %+v (%v), want only key", infos, err)
+	}
+
+	if err := store.Save(&SavedSnapshot{Name: "../escape"}); err == nil {
+		t.Error("saved a snapshot with a path in its name")
+	}
+
+	if err := store.Delete("key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("key"); err == nil {
+		t.Error("loaded a deleted snapshot")
+	}
+	if err := store.Delete("key"); err == nil {
+		t.Error("deleted a snapshot twice")
+	}
+}
@@ -0,0 +1,426 @@
+package memory
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ===== RAM SEARCH =====
+
+// maxSearchSessions bounds how many search sessions are kept at once
+const maxSearchSessions = 16
+
+// SearchOptions selects the memory and value interpretation of a search
+type SearchOptions struct {
+	Domain  string `json:"domain,omitempty"` // Memory domain to search; empty for the system bus
+	Size    int    `json:"size"`             // Value size in bytes: 1, 2 or 4
+	Format  string `json:"format"`           // "unsigned", "signed" or "bcd"
+	Endian  string `json:"endian"`           // "little" or "big"
+	Aligned bool   `json:"aligned"`          // Only consider addresses that are multiples of Size
+	Value   *int64 `json:"value,omitempty"`  // Initial value; nil starts an unknown-value search
+}
+
+// SearchFilter narrows a search's candidates by comparing current values with
+// the values seen at the previous filter
+type SearchFilter struct {
+	Op    string `json:"op"`              // "equal", "changed", "unchanged", "increased", "decreased" or "delta"
+	Value *int64 `json:"value,omitempty"` // For equal
+	Delta *int64 `json:"delta,omitempty"` // For delta: current - previous
+}
+
+// SearchCandidate is an address that still matches every filter of a search
+type SearchCandidate struct {
+	Domain     string `json:"domain,omitempty"`
+	Address    uint32 `json:"address"`
+	AddressHex string `json:"address_hex"`
+	Value      int64  `json:"value"`
+	Previous   int64  `json:"previous"`
+}
+
+// SearchPage is one page of a search's candidates
+type SearchPage struct {
+	ID         string            `json:"id"`
+	Options    SearchOptions     `json:"options"`
+	Filters    []SearchFilter    `json:"filters"`
+	Count      uint64            `json:"count"`
+	Offset     int               `json:"offset"`
+	Limit      int               `json:"limit"`
+	Sequence   uint64            `json:"sequence"` // Snapshot the candidates were last filtered against
+	CreatedAt  time.Time         `json:"created_at"`
+	Candidates []SearchCandidate `json:"candidates"`
+}
+
+// SearchSession is a RAM search narrowing down the addresses holding a value
+type SearchSession struct {
+	ID        string
+	Options   SearchOptions
+	Filters   []SearchFilter
+	Count     uint64
+	Sequence  uint64
+	CreatedAt time.Time
+
+	regions []*searchRegion
+}
+
+// searchRegion holds the candidates of one loaded block as a bitset, one bit
+// per address, alongside the block's bytes as of the last filter
+type searchRegion struct {
+	start    uint32
+	previous []byte
+	bits     []uint64
+}
+
+// StartSearch scans the current snapshot and starts a search session. With a
+// value only addresses holding it are candidates; otherwise every address is.
+func (m *Manager) StartSearch(options SearchOptions) (*SearchPage, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	snap := m.Snapshot()
+	idx := snap.indexes[options.Domain]
+	if idx == nil || len(idx.blocks) == 0 {
+		return nil, fmt.Errorf("no memory loaded in %s", domainName(options.Domain))
+	}
+
+	session := &SearchSession{
+		Options:   options,
+		Sequence:  snap.Sequence,
+		CreatedAt: time.Now(),
+	}
+
+	step := 1
+	if options.Aligned {
+		step = options.Size
+	}
+	for i, start := range idx.starts {
+		data := idx.blocks[i]
+		region := &searchRegion{
+			start:    start,
+			previous: append([]byte(nil), data...),
+			bits:     make([]uint64, (len(data)+63)/64),
+		}
+		first := (step - int(start%uint32(step))) % step
+		for offset := first; offset+options.Size <= len(data); offset += step {
+			value, ok := options.decode(data[offset:])
+			if !ok || (options.Value != nil && value != *options.Value) {
+				continue
+			}
+			region.bits[offset/64] |= 1 << (offset % 64)
+			session.Count++
+		}
+		session.regions = append(session.regions, region)
+	}
+	session.pruneRegions()
+
+	m.searchMu.Lock()
+	defer m.searchMu.Unlock()
+
+	if len(m.searches) >= maxSearchSessions {
+		return nil, fmt.Errorf("too many search sessions (limit %d), delete one first", maxSearchSessions)
+	}
+	m.nextSearchID++
+	session.ID = strconv.FormatUint(m.nextSearchID, 10)
+	m.searches[session.ID] = session
+
+	return session.page(snap, 0, 0), nil
+}
+
+// FilterSearch applies a filter to a search session against the current snapshot
+func (m *Manager) FilterSearch(id string, filter SearchFilter) (*SearchPage, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
+	m.searchMu.Lock()
+	defer m.searchMu.Unlock()
+
+	session, exists := m.searches[id]
+	if !exists {
+		return nil, fmt.Errorf("search %s not found", id)
+	}
+
+	snap := m.Snapshot()
+	idx := snap.indexes[session.Options.Domain]
+	current := make([]byte, session.Options.Size)
+
+	session.Count = 0
+	for _, region := range session.regions {
+		region.forEach(func(offset int) bool {
+			address := region.start + uint32(offset)
+			keep := idx != nil && idx.read(current, address)
+			if keep {
+				previous, _ := session.Options.decode(region.previous[offset:])
+				value, ok := session.Options.decode(current)
+				keep = ok && filter.matches(value, previous)
+			}
+
+			if keep {
+				copy(region.previous[offset:offset+len(current)], current)
+				session.Count++
+			}
+			return keep
+		})
+	}
+	session.pruneRegions()
+	session.Filters = append(session.Filters, filter)
+	session.Sequence = snap.Sequence
+
+	return session.page(snap, 0, 0), nil
+}
+
+// GetSearch returns a page of a search session's candidates with their current values
+func (m *Manager) GetSearch(id string, offset, limit int) (*SearchPage, error) {
+	m.searchMu.Lock()
+	defer m.searchMu.Unlock()
+
+	session, exists := m.searches[id]
+	if !exists {
+		return nil, fmt.Errorf("search %s not found", id)
+	}
+	return session.page(m.Snapshot(), offset, limit), nil
+}
+
+// ListSearches returns the search sessions without their candidates
+func (m *Manager) ListSearches() []*SearchPage {
+	m.searchMu.Lock()
+	defer m.searchMu.Unlock()
+
+	pages := make([]*SearchPage, 0, len(m.searches))
+	for _, session := range m.searches {
+		page := session.page(nil, 0, 0)
+		page.Candidates = nil
+		pages = append(pages, page)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].CreatedAt.Before(pages[j].CreatedAt) })
+	return pages
+}
+
+// DeleteSearch ends a search session
+func (m *Manager) DeleteSearch(id string) error {
+	m.searchMu.Lock()
+	defer m.searchMu.Unlock()
+
+	if _, exists := m.searches[id]; !exists {
+		return fmt.Errorf("search %s not found", id)
+	}
+	delete(m.searches, id)
+	return nil
+}
+
+// SearchCandidateOptions returns the options of a search if address is still
+// one of its candidates
+func (m *Manager) SearchCandidateOptions(id string, address uint32) (SearchOptions, error) {
+	m.searchMu.Lock()
+	defer m.searchMu.Unlock()
+
+	session, exists := m.searches[id]
+	if !exists {
+		return SearchOptions{}, fmt.Errorf("search %s not found", id)
+	}
+	for _, region := range session.regions {
+		offset := int64(address) - int64(region.start)
+		if offset >= 0 && offset < int64(len(region.previous)) && region.bits[offset/64]&(1<<(offset%64)) != 0 {
+			return session.Options, nil
+		}
+	}
+	return SearchOptions{}, fmt.Errorf("%s is not a candidate of search %s",
+		FormatAddress(session.Options.Domain, address), id)
+}
+
+// page lists up to limit candidates starting at offset. Current values come
+// from snap; with a nil snap the previous values are reported instead.
+func (s *SearchSession) page(snap *Snapshot, offset, limit int) *SearchPage {
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	page := &SearchPage{
+		ID:         s.ID,
+		Options:    s.Options,
+		Filters:    append([]SearchFilter{}, s.Filters...),
+		Count:      s.Count,
+		Offset:     offset,
+		Limit:      limit,
+		Sequence:   s.Sequence,
+		CreatedAt:  s.CreatedAt,
+		Candidates: make([]SearchCandidate, 0),
+	}
+
+	var idx *blockIndex
+	if snap != nil {
+		idx = snap.indexes[s.Options.Domain]
+	}
+	current := make([]byte, s.Options.Size)
+	skipped := 0
+
+	for _, region := range s.regions {
+		if len(page.Candidates) >= limit {
+			break
+		}
+		region.forEach(func(i int) bool {
+			if skipped < offset {
+				skipped++
+				return true
+			}
+			if len(page.Candidates) >= limit {
+				return true
+			}
+
+			address := region.start + uint32(i)
+			previous, _ := s.Options.decode(region.previous[i:])
+			candidate := SearchCandidate{
+				Domain:     s.Options.Domain,
+				Address:    address,
+				AddressHex: FormatAddress(s.Options.Domain, address),
+				Value:      previous,
+				Previous:   previous,
+			}
+			if idx != nil && idx.read(current, address) {
+				if value, ok := s.Options.decode(current); ok {
+					candidate.Value = value
+				}
+			}
+			page.Candidates = append(page.Candidates, candidate)
+			return true
+		})
+	}
+
+	return page
+}
+
+// pruneRegions drops regions that no longer hold candidates
+func (s *SearchSession) pruneRegions() {
+	kept := s.regions[:0]
+	for _, region := range s.regions {
+		for _, word := range region.bits {
+			if word != 0 {
+				kept = append(kept, region)
+				break
+			}
+		}
+	}
+	s.regions = kept
+}
+
+// forEach calls fn with the offset of every candidate in the region in
+// ascending order. Candidates for which fn returns false are removed.
+func (r *searchRegion) forEach(fn func(offset int) bool) {
+	for w, word := range r.bits {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			word &^= 1 << bit
+			if !fn(w*64 + bit) {
+				r.bits[w] &^= 1 << bit
+			}
+		}
+	}
+}
+
+// decode interprets the first Size bytes of data, reporting false for bytes
+// that are not valid BCD
+func (o SearchOptions) decode(data []byte) (int64, bool) {
+	if len(data) < o.Size {
+		return 0, false
+	}
+
+	raw := uint64(0)
+	for i := 0; i < o.Size; i++ {
+		b := data[i]
+		if o.Endian == "little" {
+			b = data[o.Size-1-i]
+		}
+		raw = raw<<8 | uint64(b)
+	}
+
+	switch o.Format {
+	case "signed":
+		shift := 64 - 8*o.Size
+		return int64(raw<<shift) >> shift, true
+	case "bcd":
+		value := int64(0)
+		for i := o.Size - 1; i >= 0; i-- {
+			b := byte(raw >> (8 * i))
+			if b>>4 > 9 || b&0x0F > 9 {
+				return 0, false
+			}
+			value = value*100 + int64(b>>4)*10 + int64(b&0x0F)
+		}
+		return value, true
+	}
+	return int64(raw), true
+}
+
+// validate checks that the options describe a supported interpretation
+func (o *SearchOptions) validate() error {
+	if o.Size != 1 && o.Size != 2 && o.Size != 4 {
+		return fmt.Errorf("search size must be 1, 2 or 4 bytes, got %d", o.Size)
+	}
+	switch o.Format {
+	case "":
+		o.Format = "unsigned"
+	case "unsigned", "signed", "bcd":
+	default:
+		return fmt.Errorf("unknown search format %q", o.Format)
+	}
+	switch o.Endian {
+	case "":
+		o.Endian = "little"
+	case "little", "big":
+	default:
+		return fmt.Errorf("unknown endianness %q", o.Endian)
+	}
+	return nil
+}
+
+// validate checks that the filter has the operands its operation needs
+func (f SearchFilter) validate() error {
+	switch f.Op {
+	case "changed", "unchanged", "increased", "decreased":
+	case "equal":
+		if f.Value == nil {
+			return fmt.Errorf("filter %s needs value", f.Op)
+		}
+	case "delta":
+		if f.Delta == nil {
+			return fmt.Errorf("filter %s needs delta", f.Op)
+		}
+	default:
+		return fmt.Errorf("unknown search filter %q", f.Op)
+	}
+	return nil
+}
+
+// matches reports whether a candidate that now reads value and read previous
+// at the last filter passes
+func (f SearchFilter) matches(value, previous int64) bool {
+	switch f.Op {
+	case "equal":
+		return value == *f.Value
+	case "changed":
+		return value != previous
+	case "unchanged":
+		return value == previous
+	case "increased":
+		return value > previous
+	case "decreased":
+		return value < previous
+	case "delta":
+		return value-previous == *f.Delta
+	}
+	return false
+}
+
+// domainName describes a domain for messages
+func domainName(domain string) string {
+	if domain == "" {
+		return "the system bus"
+	}
+	return fmt.Sprintf("domain %s", domain)
+}
@@ -0,0 +1,281 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+// candidateAddresses lists every candidate of a search
+func candidateAddresses(t *testing.T, m *Manager, id string) []uint32 {
+	t.Helper()
+	page, err := m.GetSearch(id, 0, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses := make([]uint32, 0, len(page.Candidates))
+	for _, candidate := range page.Candidates {
+		addresses = append(addresses, candidate.Address)
+	}
+	return addresses
+}
+
+func TestSearchFilters(t *testing.T) {
+	// Eight one-byte values read before and after the filter
+	before := []byte{10, 10, 10, 10, 10, 10, 10, 10}
+	after := []byte{10, 11, 9, 13, 7, 10, 40, 10}
+
+	tests := []struct {
+		filter SearchFilter
+		want   []uint32
+	}{
+		{SearchFilter{Op: "equal", Value: int64Ptr(10)}, []uint32{0xC000, 0xC005, 0xC007}},
+		{SearchFilter{Op: "changed"}, []uint32{0xC001, 0xC002, 0xC003, 0xC004, 0xC006}},
+		{SearchFilter{Op: "unchanged"}, []uint32{0xC000, 0xC005, 0xC007}},
+		{SearchFilter{Op: "increased"}, []uint32{0xC001, 0xC003, 0xC006}},
+		{SearchFilter{Op: "decreased"}, []uint32{0xC002, 0xC004}},
+		{SearchFilter{Op: "delta", Delta: int64Ptr(-3)}, []uint32{0xC004}},
+		{SearchFilter{Op: "delta", Delta: int64Ptr(30)}, []uint32{0xC006}},
+	}
+
+	for _, tt := range tests {
+		m := NewManager()
+		m.Update(map[uint32][]byte{0xC000: before})
+
+		page, err := m.StartSearch(SearchOptions{Size: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Count != uint64(len(before)) {
+			t.Fatalf("unknown-value search started with %d candidates, want %d", page.Count, len(before))
+		}
+
+		m.Update(map[uint32][]byte{0xC000: after})
+		if page, err = m.FilterSearch(page.ID, tt.filter); err != nil {
+			t.Fatal(err)
+		}
+		if got := candidateAddresses(t, m, page.ID); !reflect.DeepEqual(got, tt.want) || page.Count != uint64(len(tt.want)) {
+			t.Errorf("%s: candidates %X (count %d), want %X", tt.filter.Op, got, page.Count, tt.want)
+		}
+	}
+}
+
+func TestSearchFiltersCompareWithTheLastFilter(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: {5, 5, 5}})
+	page, _ := m.StartSearch(SearchOptions{Size: 1, Value: int64Ptr(5)})
+
+	m.Update(map[uint32][]byte{0xC000: {6, 6, 5}})
+	m.FilterSearch(page.ID, SearchFilter{Op: "increased"})
+
+	// Both survivors go up again from 6; the comparison is not with the initial 5
+	m.Update(map[uint32][]byte{0xC000: {7, 6, 5}})
+	page, err := m.FilterSearch(page.ID, SearchFilter{Op: "delta", Delta: int64Ptr(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Candidates) != 1 || page.Candidates[0].Address != 0xC000 || page.Candidates[0].Previous != 7 {
+		t.Errorf("candidates after two filters: %+v", page.Candidates)
+	}
+	if len(page.Filters) != 2 {
+		t.Errorf("search recorded %d filters, want 2", len(page.Filters))
+	}
+}
+
+func TestSearchValueFormats(t *testing.T) {
+	data := []byte{0x00, 0x12, 0x34, 0xFF, 0xFE, 0x99, 0x99, 0x00}
+
+	tests := []struct {
+		options SearchOptions
+		want    []uint32
+	}{
+		{SearchOptions{Size: 2, Endian: "little", Value: int64Ptr(0x3412)}, []uint32{0xC001}},
+		{SearchOptions{Size: 2, Endian: "big", Value: int64Ptr(0x1234)}, []uint32{0xC001}},
+		{SearchOptions{Size: 2, Endian: "little", Format: "signed", Value: int64Ptr(-257)}, []uint32{0xC003}},
+		{SearchOptions{Size: 1, Format: "signed", Value: int64Ptr(-1)}, []uint32{0xC003}},
+		{SearchOptions{Size: 2, Endian: "big", Format: "bcd", Value: int64Ptr(1234)}, []uint32{0xC001}},
+		{SearchOptions{Size: 2, Format: "bcd", Value: int64Ptr(9999)}, []uint32{0xC005}},
+		{SearchOptions{Size: 4, Endian: "big", Value: int64Ptr(0x001234FF)}, []uint32{0xC000}},
+		{SearchOptions{Size: 4, Endian: "big", Format: "signed", Value: int64Ptr(-0x16667)}, []uint32{0xC003}},
+		// Aligned searches skip odd addresses
+		{SearchOptions{Size: 2, Endian: "big", Aligned: true, Value: int64Ptr(0x1234)}, []uint32{}},
+		{SearchOptions{Size: 2, Endian: "big", Aligned: true, Value: int64Ptr(0x0012)}, []uint32{0xC000}},
+	}
+
+	for _, tt := range tests {
+		m := NewManager()
+		m.Update(map[uint32][]byte{0xC000: data})
+		page, err := m.StartSearch(tt.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := candidateAddresses(t, m, page.ID); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: candidates %X, want %X", tt.options, got, tt.want)
+		}
+	}
+
+	// Invalid BCD bytes are never candidates of an unknown-value BCD search
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: {0x12, 0x3A, 0x45}})
+	if page, _ := m.StartSearch(SearchOptions{Size: 1, Format: "bcd"}); page.Count != 2 {
+		t.Errorf("BCD search over an invalid byte kept %d candidates, want 2", page.Count)
+	}
+}
+
+func TestSearchRejectsInvalidOptionsAndFilters(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: {1, 2, 3, 4}})
+
+	for _, options := range []SearchOptions{{Size: 3}, {Size: 1, Format: "float"}, {Size: 2, Endian: "middle"}} {
+		if _, err := m.StartSearch(options); err == nil {
+			t.Errorf("%+v started a search", options)
+		}
+	}
+	if _, err := m.StartSearch(SearchOptions{Size: 1, Domain: "sram"}); err == nil {
+		t.Error("search over an unloaded domain started")
+	}
+
+	page, _ := m.StartSearch(SearchOptions{Size: 1})
+	for _, filter := range []SearchFilter{{Op: "equal"}, {Op: "delta"}, {Op: "bigger"}} {
+		if _, err := m.FilterSearch(page.ID, filter); err == nil {
+			t.Errorf("filter %+v applied", filter)
+		}
+	}
+	if _, err := m.FilterSearch("missing", SearchFilter{Op: "changed"}); err == nil {
+		t.Error("filter applied to a missing search")
+	}
+}
+
+func TestSearchPagesAcrossWordsAndRegions(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{
+		0xC000: make([]byte, 200), // Four bitset words
+		0xD000: make([]byte, 10),
+	})
+	page, err := m.StartSearch(SearchOptions{Size: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Count != 210 || len(page.Candidates) != 100 {
+		t.Fatalf("search has %d candidates and a first page of %d, want 210 and 100", page.Count, len(page.Candidates))
+	}
+
+	tests := []struct {
+		offset, limit int
+		first, last   uint32
+		length        int
+	}{
+		{60, 10, 0xC03C, 0xC045, 10},  // Crosses the first word boundary
+		{195, 10, 0xC0C3, 0xD004, 10}, // Crosses into the second block
+		{205, 100, 0xD005, 0xD009, 5}, // Last page is short
+		{-5, 3, 0xC000, 0xC002, 3},    // A negative offset starts at the beginning
+		{210, 10, 0, 0, 0},            // Past the end
+	}
+	for _, tt := range tests {
+		page, err := m.GetSearch(page.ID, tt.offset, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Candidates) != tt.length {
+			t.Errorf("offset %d limit %d returned %d candidates, want %d", tt.offset, tt.limit, len(page.Candidates), tt.length)
+			continue
+		}
+		if tt.length > 0 && (page.Candidates[0].Address != tt.first || page.Candidates[tt.length-1].Address != tt.last) {
+			t.Errorf("offset %d limit %d covers 0x%X to 0x%X, want 0x%X to 0x%X", tt.offset, tt.limit,
+				page.Candidates[0].Address, page.Candidates[tt.length-1].Address, tt.first, tt.last)
+		}
+	}
+
+	// Narrowing drops whole regions and words; paging still walks what is left
+	data := make([]byte, 200)
+	data[63], data[64], data[199] = 1, 1, 1
+	m.Update(map[uint32][]byte{0xC000: data, 0xD000: make([]byte, 10)})
+	if page, err = m.FilterSearch(page.ID, SearchFilter{Op: "changed"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := candidateAddresses(t, m, page.ID); !reflect.DeepEqual(got, []uint32{0xC03F, 0xC040, 0xC0C7}) {
+		t.Errorf("candidates after narrowing %X", got)
+	}
+	if page, _ = m.GetSearch(page.ID, 1, 1); len(page.Candidates) != 1 || page.Candidates[0].Address != 0xC040 {
+		t.Errorf("second candidate page %+v", page.Candidates)
+	}
+}
+
+func TestSearchAfterLayoutChange(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: make([]byte, 0x100)})
+	page, err := m.StartSearch(SearchOptions{Size: 2, Aligned: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Count != 0x80 {
+		t.Fatalf("search started with %d candidates, want 128", page.Count)
+	}
+
+	// Read planning replaces the whole block with the ranges properties use
+	m.RetainBlocks("", map[uint32]uint32{})
+	m.Update(map[uint32][]byte{0xC010: make([]byte, 8), 0xC081: make([]byte, 4)})
+
+	// Candidates outside the new ranges can't be read and are dropped; the
+	// value straddling 0xC084 runs off the end of its range
+	if page, err = m.FilterSearch(page.ID, SearchFilter{Op: "unchanged"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []uint32{0xC010, 0xC012, 0xC014, 0xC016, 0xC082}
+	if got := candidateAddresses(t, m, page.ID); !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates after the layout change %X, want %X", got, want)
+	}
+
+	// A search started again covers only the new layout, aligned by address
+	restarted, err := m.StartSearch(SearchOptions{Size: 2, Aligned: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := candidateAddresses(t, m, restarted.ID); !reflect.DeepEqual(got, want) {
+		t.Errorf("restarted search candidates %X, want %X", got, want)
+	}
+
+	// Going back to the whole block keeps the narrowed candidates and their values
+	m.RetainBlocks("", map[uint32]uint32{})
+	whole := make([]byte, 0x100)
+	whole[0x12] = 9
+	m.Update(map[uint32][]byte{0xC000: whole})
+	if page, err = m.FilterSearch(page.ID, SearchFilter{Op: "changed"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Candidates) != 1 || page.Candidates[0].Address != 0xC012 || page.Candidates[0].Value != 9 {
+		t.Errorf("candidates back on the whole block %+v", page.Candidates)
+	}
+}
+
+func TestSearchSessionLimit(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: {1}})
+
+	var first string
+	for i := 0; i < maxSearchSessions; i++ {
+		page, err := m.StartSearch(SearchOptions{Size: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = page.ID
+		}
+	}
+	if _, err := m.StartSearch(SearchOptions{Size: 1}); err == nil {
+		t.Fatalf("started more than %d searches", maxSearchSessions)
+	}
+
+	if err := m.DeleteSearch(first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.StartSearch(SearchOptions{Size: 1}); err != nil {
+		t.Errorf("search after deleting one: %v", err)
+	}
+	if n := len(m.ListSearches()); n != maxSearchSessions {
+		t.Errorf("listed %d searches, want %d", n, maxSearchSessions)
+	}
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+)
+
+// ===== SNAPSHOTS =====
+
+// Snapshot is an immutable view of loaded memory. Every update publishes a new
+// snapshot with the next sequence number, so reads from one snapshot never mix
+// bytes from different updates.
+type Snapshot struct {
+	Sequence  uint64
+	Frame     uint64 // Number of system bus reads applied so far
+	CreatedAt time.Time
+
+	indexes     map[string]*blockIndex
+	bankWindows []BankedWindow
+	bankCaches  map[bankKey]*bankCache
+}
+
+// Snapshot returns the most recently published snapshot. Writes made since
+// the last update are published first, once for all of them.
+func (m *Manager) Snapshot() *Snapshot {
+	if m.unpublished.Load() {
+		m.mu.Lock()
+		if m.unpublished.Load() {
+			m.publishLocked()
+		}
+		m.mu.Unlock()
+	}
+	return m.snapshot.Load()
+}
+
+// publishLocked swaps in a new snapshot of the loaded memory. Block data is
+// shared with the live indexes, which copy a block before writing to it, so
+// publishing costs one pointer per block rather than a copy of memory.
+func (m *Manager) publishLocked() {
+	m.unpublished.Store(false)
+	m.sequence++
+	snap := &Snapshot{
+		Sequence:    m.sequence,
+		Frame:       m.frame,
+		CreatedAt:   time.Now(),
+		indexes:     make(map[string]*blockIndex, len(m.indexes)),
+		bankWindows: m.bankWindows,
+		bankCaches:  make(map[bankKey]*bankCache, len(m.bankCaches)),
+	}
+
+	for domain, idx := range m.indexes {
+		snap.indexes[domain] = idx.share()
+	}
+
+	// Cached bank bytes are replaced rather than modified, so only the maps are copied
+	for key, cache := range m.bankCaches {
+		fragments := make(map[uint32][]byte, len(cache.fragments))
+		for start, data := range cache.fragments {
+			fragments[start] = data
+		}
+		snap.bankCaches[key] = &bankCache{fragments: fragments, updated: cache.updated}
+	}
+
+	m.snapshot.Store(snap)
+}
+
+// ReadBytes reads bytes from the system bus
+func (s *Snapshot) ReadBytes(address uint32, length uint32) ([]byte, error) {
+	return s.ReadDomainBytes("", address, length)
+}
+
+// ReadDomainBytes reads bytes from an address inside a named memory domain.
+// The empty domain is the system bus.
+func (s *Snapshot) ReadDomainBytes(domain string, address uint32, length uint32) ([]byte, error) {
+	idx := s.indexes[domain]
+	result := make([]byte, length)
+	if idx != nil && idx.read(result, address) {
+		return result, nil
+	}
+
+	var available uint64
+	if idx != nil {
+		available = idx.available(address)
+	}
+	if available == 0 {
+		return nil, fmt.Errorf("address %s not found in loaded memory", FormatAddress(domain, address))
+	}
+	return nil, fmt.Errorf("not enough data at address %s (requested %d bytes, available %d)",
+		FormatAddress(domain, address), length, available)
+}
+
+// ReadBankedBytes reads bytes from bank of the window containing the address.
+// The live bytes are returned while the bank is mapped in; otherwise the bytes
+// cached the last time it was mapped in are returned and marked stale.
+func (s *Snapshot) ReadBankedBytes(domain string, address uint32, length uint32, bank uint32) (*BankedRead, error) {
+	window, ok := findBankedWindow(s.bankWindows, domain, address, length)
+	if !ok {
+		return nil, fmt.Errorf("address %s is not inside a banked window", FormatAddress(domain, address))
+	}
+
+	active, err := activeBank(s.indexes, s.bankWindows[window].Register)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BankedRead{Bank: bank, ActiveBank: active}
+	cache := s.bankCaches[bankKey{window: window, bank: bank}]
+	if cache != nil {
+		result.CachedAt = cache.updated
+	}
+
+	if active == bank {
+		data, err := s.ReadDomainBytes(domain, address, length)
+		if err != nil {
+			return nil, err
+		}
+		result.Data = data
+		return result, nil
+	}
+
+	result.Stale = true
+	var data []byte
+	if cache != nil {
+		data, ok = lookupFragment(cache.fragments, address, length)
+	}
+	if cache == nil || !ok {
+		return result, fmt.Errorf("bank %d at %s has not been mapped in yet", bank, FormatAddress(domain, address))
+	}
+	result.Data = data
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSnapshotIsolatedFromWrites(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: {1, 2, 3, 4}, 0xD000: {5, 6, 7, 8}})
+	m.UpdateDomain("sram", map[uint32][]byte{0x0000: {9, 9}})
+
+	before := m.Snapshot()
+
+	m.WriteBytes(0xC001, []byte{0xAA})
+	m.WriteBytes(0xC002, []byte{0xBB})
+	m.WriteDomainBytes("sram", 0x0001, []byte{0xCC})
+
+	if got, _ := before.ReadBytes(0xC000, 4); !bytes.Equal(got, []byte{1, 2, 3, 4}) {
+		t.Errorf("published snapshot changed by a write: % X", got)
+	}
+	if got, _ := before.ReadDomainBytes("sram", 0, 2); !bytes.Equal(got, []byte{9, 9}) {
+		t.Errorf("published domain snapshot changed by a write: % X", got)
+	}
+
+	after := m.Snapshot()
+	if after.Sequence != before.Sequence+1 {
+		t.Errorf("writes published %d snapshots, want 1", after.Sequence-before.Sequence)
+	}
+	if got, _ := after.ReadBytes(0xC000, 4); !bytes.Equal(got, []byte{1, 0xAA, 0xBB, 4}) {
+		t.Errorf("writes not visible in the next snapshot: % X", got)
+	}
+	if got, _ := after.ReadDomainBytes("sram", 0, 2); !bytes.Equal(got, []byte{9, 0xCC}) {
+		t.Errorf("domain write not visible in the next snapshot: % X", got)
+	}
+
+	// Untouched blocks are shared, not copied
+	if &before.indexes[""].blocks[1][0] != &after.indexes[""].blocks[1][0] {
+		t.Error("unwritten block was copied on publish")
+	}
+
+	// The next update replaces the written bytes and leaves older snapshots alone
+	m.Update(map[uint32][]byte{0xC000: {4, 3, 2, 1}, 0xD000: {5, 6, 7, 8}})
+	if got, _ := after.ReadBytes(0xC000, 4); !bytes.Equal(got, []byte{1, 0xAA, 0xBB, 4}) {
+		t.Errorf("published snapshot changed by an update: % X", got)
+	}
+	if got, _ := m.Snapshot().ReadBytes(0xC000, 4); !bytes.Equal(got, []byte{4, 3, 2, 1}) {
+		t.Errorf("update not visible: % X", got)
+	}
+}
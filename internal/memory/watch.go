@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ===== WATCHPOINTS =====
+
+const (
+	maxWatchLength = 4096 // Largest range one watchpoint may cover
+	maxWatchHits   = 1000 // Hits kept per watchpoint, oldest dropped first
+)
+
+// Watchpoint records every observed change to a range of memory
+type Watchpoint struct {
+	ID         string     `json:"id"`
+	Domain     string     `json:"domain,omitempty"`
+	Address    uint32     `json:"address"`
+	AddressHex string     `json:"address_hex"`
+	Length     uint32     `json:"length"`
+	Condition  string     `json:"condition,omitempty"` // Only record changes while this holds
+	Property   string     `json:"property,omitempty"`  // Set when the watchpoint watches a property
+	Hits       uint64     `json:"hits"`
+	LastHit    *time.Time `json:"last_hit,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	last []byte // Bytes seen at the last check, nil until first read
+	log  []WatchHit
+}
+
+// WatchHit is one observed change to a watched range
+type WatchHit struct {
+	Watchpoint string    `json:"watchpoint"`
+	Property   string    `json:"property,omitempty"`
+	Domain     string    `json:"domain,omitempty"`
+	Address    uint32    `json:"address"`
+	AddressHex string    `json:"address_hex"`
+	Old        string    `json:"old"` // hex
+	New        string    `json:"new"` // hex
+	Timestamp  time.Time `json:"timestamp"`
+	Frame      uint64    `json:"frame"`
+	Sequence   uint64    `json:"sequence"`
+}
+
+// AddWatchpoint starts watching the range described by wp and returns the
+// watchpoint with its assigned ID
+func (m *Manager) AddWatchpoint(wp Watchpoint) (Watchpoint, error) {
+	if wp.Length == 0 || wp.Length > maxWatchLength {
+		return Watchpoint{}, fmt.Errorf("watch length must be between 1 and %d bytes", maxWatchLength)
+	}
+	if uint64(wp.Address)+uint64(wp.Length) > 1<<32 {
+		return Watchpoint{}, fmt.Errorf("watch range %s+%d runs past the end of the address space",
+			FormatAddress(wp.Domain, wp.Address), wp.Length)
+	}
+
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+
+	m.nextWatchID++
+	wp.ID = strconv.FormatUint(m.nextWatchID, 10)
+	wp.AddressHex = FormatAddress(wp.Domain, wp.Address)
+	wp.Hits = 0
+	wp.LastHit = nil
+	wp.LastError = ""
+	wp.CreatedAt = time.Now()
+	wp.last = nil
+	wp.log = nil
+
+	m.watchpoints[wp.ID] = &wp
+	return wp, nil
+}
+
+// RemoveWatchpoint stops a watchpoint and returns it
+func (m *Manager) RemoveWatchpoint(id string) (Watchpoint, error) {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+
+	wp, exists := m.watchpoints[id]
+	if !exists {
+		return Watchpoint{}, fmt.Errorf("watchpoint %s not found", id)
+	}
+	delete(m.watchpoints, id)
+
+	removed := *wp
+	removed.last, removed.log = nil, nil
+	return removed, nil
+}
+
+// ListWatchpoints returns the active watchpoints, oldest first
+func (m *Manager) ListWatchpoints() []Watchpoint {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+
+	watchpoints := make([]Watchpoint, 0, len(m.watchpoints))
+	for _, wp := range m.watchpoints {
+		listed := *wp
+		listed.last, listed.log = nil, nil
+		watchpoints = append(watchpoints, listed)
+	}
+	sort.Slice(watchpoints, func(i, j int) bool {
+		a, _ := strconv.ParseUint(watchpoints[i].ID, 10, 64)
+		b, _ := strconv.ParseUint(watchpoints[j].ID, 10, 64)
+		return a < b
+	})
+	return watchpoints
+}
+
+// WatchHits returns up to limit of a watchpoint's most recent hits, oldest
+// first. A limit of 0 returns every hit kept.
+func (m *Manager) WatchHits(id string, limit int) ([]WatchHit, error) {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+
+	wp, exists := m.watchpoints[id]
+	if !exists {
+		return nil, fmt.Errorf("watchpoint %s not found", id)
+	}
+
+	hits := wp.log
+	if limit > 0 && len(hits) > limit {
+		hits = hits[len(hits)-limit:]
+	}
+	return append([]WatchHit(nil), hits...), nil
+}
+
+// CheckWatchpoints compares every watched range in snap with the bytes seen at
+// the previous check and records a hit for each range that changed. holds
+// evaluates a watchpoint's condition and is only called when its range
+// changed. It returns the new hits.
+func (m *Manager) CheckWatchpoints(snap *Snapshot, holds func(condition string) (bool, error)) []WatchHit {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+
+	var hits []WatchHit
+	for _, wp := range m.watchpoints {
+		current, err := snap.ReadDomainBytes(wp.Domain, wp.Address, wp.Length)
+		if err != nil {
+			// Not read yet; a read plan picks new ranges up on the next tick
+			continue
+		}
+
+		previous := wp.last
+		wp.last = current
+		if previous == nil || bytes.Equal(previous, current) {
+			continue
+		}
+
+		if wp.Condition != "" {
+			ok, err := holds(wp.Condition)
+			wp.LastError = ""
+			if err != nil {
+				wp.LastError = err.Error()
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		hit := WatchHit{
+			Watchpoint: wp.ID,
+			Property:   wp.Property,
+			Domain:     wp.Domain,
+			Address:    wp.Address,
+			AddressHex: wp.AddressHex,
+			Old:        hex.EncodeToString(previous),
+			New:        hex.EncodeToString(current),
+			Timestamp:  time.Now(),
+			Frame:      snap.Frame,
+			Sequence:   snap.Sequence,
+		}
+
+		wp.Hits++
+		wp.LastHit = &hit.Timestamp
+		wp.log = append(wp.log, hit)
+		if len(wp.log) > maxWatchHits {
+			wp.log = wp.log[len(wp.log)-maxWatchHits:]
+		}
+		hits = append(hits, hit)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		a, _ := strconv.ParseUint(hits[i].Watchpoint, 10, 64)
+		b, _ := strconv.ParseUint(hits[j].Watchpoint, 10, 64)
+		return a < b
+	})
+	return hits
+}
+
+// SetPropertyWatch marks whether a property is watched and under which condition
+func (m *Manager) SetPropertyWatch(name string, address uint32, enabled bool, condition string) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	state := m.propertyStateLocked(name, address)
+	state.WatchEnabled = enabled
+	state.WatchCondition = ""
+	if enabled {
+		state.WatchCondition = condition
+	}
+}
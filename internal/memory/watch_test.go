@@ -0,0 +1,190 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWatchpointRecordsChanges(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: {1, 2, 3, 4}})
+	m.UpdateDomain("sram", map[uint32][]byte{0x0000: {9, 9}})
+
+	hp, err := m.AddWatchpoint(Watchpoint{Address: 0xC001, Length: 2, Property: "hp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	save, err := m.AddWatchpoint(Watchpoint{Domain: "sram", Address: 0x0001, Length: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unloaded, err := m.AddWatchpoint(Watchpoint{Address: 0xD000, Length: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	always := func(string) (bool, error) { return true, nil }
+
+	// The first check only records the bytes
+	if hits := m.CheckWatchpoints(m.Snapshot(), always); len(hits) != 0 {
+		t.Fatalf("first check recorded %d hits", len(hits))
+	}
+
+	// A change outside the range is not a hit
+	m.Update(map[uint32][]byte{0xC000: {7, 2, 3, 7}})
+	if hits := m.CheckWatchpoints(m.Snapshot(), always); len(hits) != 0 {
+		t.Fatalf("change outside the range recorded %+v", hits)
+	}
+
+	m.Update(map[uint32][]byte{0xC000: {7, 2, 5, 7}})
+	m.UpdateDomain("sram", map[uint32][]byte{0x0000: {9, 8}})
+	snap := m.Snapshot()
+	hits := m.CheckWatchpoints(snap, always)
+	if len(hits) != 2 || hits[0].Watchpoint != hp.ID || hits[1].Watchpoint != save.ID {
+		t.Fatalf("hits %+v, want one for each watchpoint in ID order", hits)
+	}
+	if hit := hits[0]; hit.Old != "0203" || hit.New != "0205" || hit.Property != "hp" || hit.AddressHex != "0xC001" || hit.Sequence != snap.Sequence {
+		t.Errorf("hp hit %+v", hit)
+	}
+	if hit := hits[1]; hit.Domain != "sram" || hit.Old != "09" || hit.New != "08" {
+		t.Errorf("sram hit %+v", hit)
+	}
+
+	// Checking the same snapshot again records nothing new
+	if hits := m.CheckWatchpoints(snap, always); len(hits) != 0 {
+		t.Errorf("unchanged memory recorded %+v", hits)
+	}
+
+	// Bytes read later for the first time are a baseline, not a hit
+	m.Update(map[uint32][]byte{0xD000: {1}})
+	if hits := m.CheckWatchpoints(m.Snapshot(), always); len(hits) != 0 {
+		t.Errorf("first read of %s recorded %+v", unloaded.AddressHex, hits)
+	}
+
+	listed := m.ListWatchpoints()
+	if len(listed) != 3 || listed[0].ID != hp.ID || listed[0].Hits != 1 || listed[0].LastHit == nil || listed[2].Hits != 0 {
+		t.Errorf("listed watchpoints %+v", listed)
+	}
+	if logged, err := m.WatchHits(hp.ID, 0); err != nil || len(logged) != 1 || logged[0].New != "0205" {
+		t.Errorf("hp hits %+v (%v)", logged, err)
+	}
+}
+
+func TestWatchpointConditions(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: {0}})
+	wp, err := m.AddWatchpoint(Watchpoint{Address: 0xC000, Length: 1, Condition: "inBattle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	holds, evalErr := false, error(nil)
+	var evaluated []string
+	condition := func(expr string) (bool, error) {
+		evaluated = append(evaluated, expr)
+		return holds, evalErr
+	}
+
+	m.CheckWatchpoints(m.Snapshot(), condition)
+	if len(evaluated) != 0 {
+		t.Errorf("condition evaluated without a change: %v", evaluated)
+	}
+
+	// A change while the condition is false is skipped but still becomes the baseline
+	m.Update(map[uint32][]byte{0xC000: {1}})
+	if hits := m.CheckWatchpoints(m.Snapshot(), condition); len(hits) != 0 {
+		t.Errorf("change with a false condition recorded %+v", hits)
+	}
+	if len(evaluated) != 1 || evaluated[0] != "inBattle" {
+		t.Errorf("evaluated %v, want inBattle once", evaluated)
+	}
+
+	holds = true
+	m.Update(map[uint32][]byte{0xC000: {2}})
+	hits := m.CheckWatchpoints(m.Snapshot(), condition)
+	if len(hits) != 1 || hits[0].Old != "01" || hits[0].New != "02" {
+		t.Errorf("change with a true condition recorded %+v", hits)
+	}
+
+	// Evaluation errors skip the hit and are reported on the watchpoint until the next evaluation
+	holds, evalErr = false, errors.New("reference to unknown property")
+	m.Update(map[uint32][]byte{0xC000: {3}})
+	if hits := m.CheckWatchpoints(m.Snapshot(), condition); len(hits) != 0 {
+		t.Errorf("failed condition recorded %+v", hits)
+	}
+	if listed := m.ListWatchpoints(); listed[0].LastError != evalErr.Error() || listed[0].Hits != 1 {
+		t.Errorf("watchpoint after a failed condition %+v", listed[0])
+	}
+
+	holds, evalErr = true, nil
+	m.Update(map[uint32][]byte{0xC000: {4}})
+	m.CheckWatchpoints(m.Snapshot(), condition)
+	if listed := m.ListWatchpoints(); listed[0].LastError != "" || listed[0].Hits != 2 {
+		t.Errorf("watchpoint %s after the condition recovered %+v", wp.ID, listed[0])
+	}
+}
+
+func TestWatchpointRemovalAndLimits(t *testing.T) {
+	m := NewManager()
+	m.Update(map[uint32][]byte{0xC000: {0}})
+
+	for _, wp := range []Watchpoint{
+		{Address: 0xC000, Length: 0},
+		{Address: 0xC000, Length: maxWatchLength + 1},
+		{Address: 0xFFFFFFFF, Length: 2},
+	} {
+		if _, err := m.AddWatchpoint(wp); err == nil {
+			t.Errorf("added watchpoint at 0x%X of %d bytes", wp.Address, wp.Length)
+		}
+	}
+
+	wp, err := m.AddWatchpoint(Watchpoint{Address: 0xC000, Length: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	always := func(string) (bool, error) { return true, nil }
+	m.CheckWatchpoints(m.Snapshot(), always)
+
+	// Only the most recent hits are kept
+	for i := 1; i <= maxWatchHits+5; i++ {
+		m.Update(map[uint32][]byte{0xC000: {byte(i)}})
+		m.CheckWatchpoints(m.Snapshot(), always)
+	}
+	hits, err := m.WatchHits(wp.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != maxWatchHits || hits[len(hits)-1].New != fmt.Sprintf("%02x", (maxWatchHits+5)%256) {
+		t.Errorf("kept %d hits ending with %+v", len(hits), hits[len(hits)-1])
+	}
+	if recent, _ := m.WatchHits(wp.ID, 3); len(recent) != 3 || recent[2] != hits[len(hits)-1] {
+		t.Errorf("last 3 hits %+v", recent)
+	}
+
+	removed, err := m.RemoveWatchpoint(wp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed.Hits != maxWatchHits+5 {
+		t.Errorf("removed watchpoint reports %d hits, want %d", removed.Hits, maxWatchHits+5)
+	}
+
+	m.Update(map[uint32][]byte{0xC000: {0xEE}})
+	if hits := m.CheckWatchpoints(m.Snapshot(), always); len(hits) != 0 {
+		t.Errorf("removed watchpoint recorded %+v", hits)
+	}
+	if _, err := m.RemoveWatchpoint(wp.ID); err == nil {
+		t.Error("removed a watchpoint twice")
+	}
+	if _, err := m.WatchHits(wp.ID, 0); err == nil {
+		t.Error("hits listed for a removed watchpoint")
+	}
+	if listed := m.ListWatchpoints(); len(listed) != 0 {
+		t.Errorf("watchpoints left after removal: %+v", listed)
+	}
+
+	// IDs are not reused
+	if next, _ := m.AddWatchpoint(Watchpoint{Address: 0xC000, Length: 1}); next.ID == wp.ID {
+		t.Errorf("watchpoint ID %s reused", next.ID)
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"gamehook/internal/mappers"
+	"gamehook/internal/memory"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -23,10 +26,13 @@ type GameHookAPI interface {
 	SetPropertyValue(name string, value interface{}) error
 	SetPropertyBytes(name string, data []byte) error
 	FreezeProperty(name string, freeze bool) error
+	FreezePropertyWithOptions(name string, options *mappers.FreezeOptions) error
 	ListMappers() []string
 	GetPropertyState(name string) interface{}
+	GetPropertyBankStatus(name string) *mappers.BankStatus
 	GetAllPropertyStates() map[string]interface{}
-	GetPropertyChanges() map[string]interface{}
+	GetPropertyChanges() (map[string]interface{}, uint64)
+	GetSnapshotSequence() uint64
 	GetMapperMeta() interface{}
 	GetMapperGlossary() interface{}
 
@@ -35,6 +41,52 @@ type GameHookAPI interface {
 	GetRecentlyTriggeredEvents() []string
 	TriggerEvent(name string, force bool) error
 	GetValidationErrors() map[string]interface{}
+
+	// Session recording and replay
+	GetRecordingStatus() map[string]interface{}
+	GetReplayStatus() (map[string]interface{}, error)
+	StepReplay(frames int) error
+
+	// Driver connection supervision
+	GetDriverStatus() interface{}
+
+	// Memory read planning
+	GetReadPlan() interface{}
+
+	// Automatic game detection
+	GetDetection() interface{}
+
+	// Emulator control (drivers implementing drivers.EmulatorController)
+	GetEmulatorStatus() (interface{}, error)
+	ControlEmulator(action string, frames int) error
+
+	// RAM search
+	StartSearch(options memory.SearchOptions) (interface{}, error)
+	FilterSearch(id string, filter memory.SearchFilter) (interface{}, error)
+	GetSearch(id string, offset, limit int) (interface{}, error)
+	ListSearches() interface{}
+	DeleteSearch(id string) error
+	PromoteSearchCandidate(id string, address uint32, name string) (string, error)
+
+	// Saved memory snapshots
+	CaptureSnapshot(name string) (interface{}, error)
+	ListSnapshots() (interface{}, error)
+	GetSnapshot(name string) (interface{}, error)
+	DeleteSnapshot(name string) error
+	DiffSnapshots(from, to string) (interface{}, error)
+
+	// Address watchpoints
+	AddWatchpoint(wp memory.Watchpoint) (interface{}, error)
+	RemoveWatchpoint(id string) error
+	ListWatchpoints() interface{}
+	GetWatchHits(id string, limit int) (interface{}, error)
+
+	// Memory change heatmap
+	GetMemoryHeatmap(domain string, bucketSize uint32) (*memory.Heatmap, error)
+	ResetMemoryHeatmap()
+
+	// Property history
+	GetPropertyHistory(mapper, name string, from, to time.Time, step time.Duration) (interface{}, error)
 }
 
 // NOTE: Current mappers.Mapper struct has:
@@ -53,6 +105,10 @@ type Server struct {
 	upgrader websocket.Upgrader
 	clients  map[*websocket.Conn]bool
 
+	// clientsMu guards clients and serializes writes, since gorilla
+	// connections allow only one concurrent writer
+	clientsMu sync.Mutex
+
 	// Property monitoring
 	propertyMonitor *PropertyMonitor
 	lastSnapshot    map[string]interface{}
@@ -82,6 +138,8 @@ type PropertyResponse struct {
 	Description string      `json:"description,omitempty"`
 	Frozen      bool        `json:"frozen"`
 	ReadOnly    bool        `json:"read_only"`
+	Stale       bool        `json:"stale,omitempty"` // Banked value whose bank is not mapped in
+	Bank        interface{} `json:"bank,omitempty"`
 	Validation  interface{} `json:"validation,omitempty"`
 	LastChanged time.Time   `json:"last_changed,omitempty"`
 	ReadCount   uint64      `json:"read_count,omitempty"`
@@ -235,9 +293,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	s.propertyMonitor.Stop()
 
 	// Close all WebSocket connections
+	s.clientsMu.Lock()
 	for client := range s.clients {
 		client.Close()
 	}
+	s.clientsMu.Unlock()
 	return nil
 }
 
@@ -251,6 +311,7 @@ func (s *Server) setupRoutes() {
 	// Mapper management
 	api.HandleFunc("/mappers", s.handleListMappers).Methods("GET")
 	api.HandleFunc("/mappers/{name}/load", s.handleLoadMapper).Methods("POST")
+	api.HandleFunc("/mappers/detection", s.handleGetDetection).Methods("GET")
 	api.HandleFunc("/mapper", s.handleGetCurrentMapper).Methods("GET")
 	api.HandleFunc("/mapper/meta", s.handleGetMapperMeta).Methods("GET")
 	api.HandleFunc("/mapper/glossary", s.handleGetGlossary).Methods("GET")
@@ -273,15 +334,52 @@ func (s *Server) setupRoutes() {
 	api.HandleFunc("/events/{name}/trigger", s.handleTriggerEvent).Methods("POST")
 	api.HandleFunc("/properties/{name}/metadata", s.handleGetPropertyMetadata).Methods("GET")
 	api.HandleFunc("/properties/{name}/ui-hints", s.handleGetPropertyUIHints).Methods("GET")
+	api.HandleFunc("/properties/{name}/history", s.handleGetPropertyHistory).Methods("GET")
 	api.HandleFunc("/properties/by-group/{group}", s.handleGetPropertiesByGroup).Methods("GET")
 	api.HandleFunc("/ui/themes", s.handleGetUIThemes).Methods("GET")
 	api.HandleFunc("/ui/layout", s.handleGetUILayout).Methods("GET")
 	api.HandleFunc("/validation/rules", s.handleGetValidationRules).Methods("GET")
 	api.HandleFunc("/validation/errors", s.handleGetValidationErrors).Methods("GET")
 
+	// Session recording and replay
+	api.HandleFunc("/recording", s.handleGetRecordingStatus).Methods("GET")
+	api.HandleFunc("/replay", s.handleGetReplayStatus).Methods("GET")
+	api.HandleFunc("/replay/step", s.handleStepReplay).Methods("POST")
+
+	// Driver connection status
+	api.HandleFunc("/driver/status", s.handleGetDriverStatus).Methods("GET")
+
+	// Emulator control
+	api.HandleFunc("/emulator/status", s.handleGetEmulatorStatus).Methods("GET")
+	api.HandleFunc("/emulator/{action}", s.handleControlEmulator).Methods("POST")
+
 	// Raw memory access
+	api.HandleFunc("/memory/plan", s.handleGetReadPlan).Methods("GET")
+	api.HandleFunc("/memory/heatmap", s.handleGetMemoryHeatmap).Methods("GET")
+	api.HandleFunc("/memory/heatmap", s.handleResetMemoryHeatmap).Methods("DELETE")
 	api.HandleFunc("/memory/{address}/{length}", s.handleReadMemory).Methods("GET")
 
+	// RAM search
+	api.HandleFunc("/search", s.handleListSearches).Methods("GET")
+	api.HandleFunc("/search", s.handleStartSearch).Methods("POST")
+	api.HandleFunc("/search/{id}", s.handleGetSearch).Methods("GET")
+	api.HandleFunc("/search/{id}", s.handleDeleteSearch).Methods("DELETE")
+	api.HandleFunc("/search/{id}/filter", s.handleFilterSearch).Methods("POST")
+	api.HandleFunc("/search/{id}/promote", s.handlePromoteSearchCandidate).Methods("POST")
+
+	// Saved memory snapshots
+	api.HandleFunc("/snapshots", s.handleListSnapshots).Methods("GET")
+	api.HandleFunc("/snapshots", s.handleCaptureSnapshot).Methods("POST")
+	api.HandleFunc("/snapshots/{name}", s.handleGetSnapshot).Methods("GET")
+	api.HandleFunc("/snapshots/{name}", s.handleDeleteSnapshot).Methods("DELETE")
+	api.HandleFunc("/snapshots/{name}/diff", s.handleDiffSnapshots).Methods("GET")
+
+	// Address watchpoints
+	api.HandleFunc("/watchpoints", s.handleListWatchpoints).Methods("GET")
+	api.HandleFunc("/watchpoints", s.handleAddWatchpoint).Methods("POST")
+	api.HandleFunc("/watchpoints/{id}", s.handleRemoveWatchpoint).Methods("DELETE")
+	api.HandleFunc("/watchpoints/{id}/hits", s.handleGetWatchHits).Methods("GET")
+
 	// WebSocket for real-time updates
 	api.HandleFunc("/stream", s.handleWebSocket)
 
@@ -331,7 +429,7 @@ func (pm *PropertyMonitor) monitorLoop() {
 
 // checkForChanges checks for property changes and broadcasts them
 func (pm *PropertyMonitor) checkForChanges() {
-	changes := pm.server.gameHook.GetPropertyChanges()
+	changes, sequence := pm.server.gameHook.GetPropertyChanges()
 	if len(changes) == 0 {
 		return
 	}
@@ -347,6 +445,7 @@ func (pm *PropertyMonitor) checkForChanges() {
 			"property":  propertyName,
 			"value":     newValue,
 			"old_value": oldValue,
+			"sequence":  sequence,
 			"timestamp": time.Now(),
 		})
 
@@ -421,6 +520,7 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
             <h3>Mapper Management</h3>
             <div class="endpoint">GET <a href="/api/mappers">/api/mappers</a> - List available mappers</div>
             <div class="endpoint">POST /api/mappers/{name}/load - Load a mapper</div>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/mappers/detection">/api/mappers/detection</a> - Get the detected game and matching mapper</div>
             <div class="endpoint">GET <a href="/api/mapper">/api/mapper</a> - Get current mapper info</div>
             <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/mapper/meta">/api/mapper/meta</a> - Get mapper metadata</div>
             <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/mapper/glossary">/api/mapper/glossary</a> - Get property glossary</div>
@@ -432,11 +532,12 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
             <div class="endpoint">PUT /api/properties/{name} - Set property (legacy)</div>
             <div class="endpoint"><span class="new">NEW</span> PUT /api/properties/{name}/value - Set property value</div>
             <div class="endpoint"><span class="new">NEW</span> PUT /api/properties/{name}/bytes - Set property bytes</div>
-            <div class="endpoint"><span class="new">NEW</span> POST /api/properties/{name}/freeze - Freeze/unfreeze property</div>
+            <div class="endpoint"><span class="new">NEW</span> POST /api/properties/{name}/freeze - Freeze/unfreeze property, optionally conditional, expiring or bounded</div>
             <div class="endpoint"><span class="new">NEW</span> GET /api/properties/{name}/state - Get property state</div>
             <div class="endpoint"><span class="new">NEW</span> PUT /api/properties/batch - Batch property updates</div>
             <div class="endpoint"><span class="new">NEW</span> GET /api/properties/{name}/metadata - Get property metadata</div>
             <div class="endpoint"><span class="new">NEW</span> GET /api/properties/{name}/ui-hints - Get property UI hints</div>
+            <div class="endpoint"><span class="new">NEW</span> GET /api/properties/{name}/history?from=&amp;to=&amp;step= - Get recorded values, downsampled to min/max/avg/last per step</div>
             <div class="endpoint"><span class="new">NEW</span> GET /api/properties/by-group/{group} - Get properties by group</div>
             
             <h3>Enhanced Features</h3>
@@ -449,6 +550,42 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
             <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/validation/rules">/api/validation/rules</a> - Get validation rules</div>
             <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/validation/errors">/api/validation/errors</a> - Get validation errors</div>
             
+            <h3>Recording &amp; Replay</h3>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/recording">/api/recording</a> - Get session recording status</div>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/replay">/api/replay</a> - Get replay position</div>
+            <div class="endpoint"><span class="new">NEW</span> POST /api/replay/step - Advance a stepwise replay</div>
+            
+            <h3>Driver</h3>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/driver/status">/api/driver/status</a> - Get emulator connection state</div>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/memory/plan">/api/memory/plan</a> - Get the memory ranges read each tick</div>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/memory/heatmap?unmapped=true&amp;sort=changes">/api/memory/heatmap</a> - Get how often each memory range changes</div>
+            <div class="endpoint"><span class="new">NEW</span> DELETE /api/memory/heatmap - Reset change counts</div>
+            
+            <h3>Emulator Control</h3>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/emulator/status">/api/emulator/status</a> - Get paused/playing state and loaded content</div>
+            <div class="endpoint"><span class="new">NEW</span> POST /api/emulator/{action} - pause, resume, toggle-pause, frame-advance, reset, save-state, load-state, state-slot-plus, state-slot-minus</div>
+            
+            <h3>RAM Search</h3>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/search">/api/search</a> - List active searches</div>
+            <div class="endpoint"><span class="new">NEW</span> POST /api/search - Start a search by value or unknown value</div>
+            <div class="endpoint"><span class="new">NEW</span> GET /api/search/{id}?offset=&amp;limit= - Page through candidates</div>
+            <div class="endpoint"><span class="new">NEW</span> POST /api/search/{id}/filter - equal, changed, unchanged, increased, decreased, delta</div>
+            <div class="endpoint"><span class="new">NEW</span> POST /api/search/{id}/promote - Get a CUE property snippet for a candidate</div>
+            <div class="endpoint"><span class="new">NEW</span> DELETE /api/search/{id} - End a search</div>
+            
+            <h3>Memory Snapshots</h3>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/snapshots">/api/snapshots</a> - List saved snapshots</div>
+            <div class="endpoint"><span class="new">NEW</span> POST /api/snapshots - Capture memory and property values</div>
+            <div class="endpoint"><span class="new">NEW</span> GET /api/snapshots/{name} - Get a saved snapshot</div>
+            <div class="endpoint"><span class="new">NEW</span> GET /api/snapshots/{name}/diff?to= - Diff against another snapshot or live memory</div>
+            <div class="endpoint"><span class="new">NEW</span> DELETE /api/snapshots/{name} - Delete a saved snapshot</div>
+            
+            <h3>Watchpoints</h3>
+            <div class="endpoint"><span class="new">NEW</span> GET <a href="/api/watchpoints">/api/watchpoints</a> - List watchpoints</div>
+            <div class="endpoint"><span class="new">NEW</span> POST /api/watchpoints - Watch an address range or property</div>
+            <div class="endpoint"><span class="new">NEW</span> GET /api/watchpoints/{id}/hits?limit= - Get recorded changes</div>
+            <div class="endpoint"><span class="new">NEW</span> DELETE /api/watchpoints/{id} - Remove a watchpoint</div>
+            
             <h3>Real-time Communication</h3>
             <div class="endpoint">WS /api/stream - Enhanced WebSocket for real-time updates</div>
         </div>
@@ -643,7 +780,7 @@ func (s *Server) handleGetPropertyMetadata(w http.ResponseWriter, r *http.Reques
 	metadata := PropertyMetadataResponse{
 		Name:         name,
 		Type:         string(prop.Type),
-		Address:      fmt.Sprintf("0x%X", prop.Address),
+		Address:      memory.FormatAddress(prop.Domain, prop.Address),
 		Description:  prop.Description,
 		UIHints:      prop.UIHints,
 		Advanced:     prop.Advanced,
@@ -707,9 +844,9 @@ func (s *Server) handleGetPropertiesByGroup(w http.ResponseWriter, r *http.Reque
 				Name:        propName,
 				Value:       value,
 				Type:        string(prop.Type),
-				Address:     fmt.Sprintf("0x%X", prop.Address),
+				Address:     memory.FormatAddress(prop.Domain, prop.Address),
 				Description: prop.Description,
-				Frozen:      prop.Frozen,
+				Frozen:      mapper.IsFrozen(propName),
 				ReadOnly:    prop.ReadOnly,
 			}
 
@@ -903,6 +1040,7 @@ func (s *Server) handleSetPropertyValue(w http.ResponseWriter, r *http.Request)
 		"type":      "property_changed",
 		"property":  name,
 		"value":     request.Value,
+		"sequence":  s.gameHook.GetSnapshotSequence(),
 		"timestamp": time.Now(),
 		"source":    "api_set_value",
 	})
@@ -936,6 +1074,7 @@ func (s *Server) handleSetPropertyBytes(w http.ResponseWriter, r *http.Request)
 		"type":      "property_changed",
 		"property":  name,
 		"bytes":     request.Bytes,
+		"sequence":  s.gameHook.GetSnapshotSequence(),
 		"timestamp": time.Now(),
 		"source":    "api_set_bytes",
 	})
@@ -946,7 +1085,15 @@ func (s *Server) handleFreezeProperty(w http.ResponseWriter, r *http.Request) {
 	name := vars["name"]
 
 	var request struct {
-		Freeze bool `json:"freeze"`
+		Freeze    bool   `json:"freeze"`
+		Condition string `json:"condition,omitempty"` // Only hold the value while this expression is true
+		Duration  string `json:"duration,omitempty"`  // Unfreeze after this long, e.g. "30s"
+		Frames    uint64 `json:"frames,omitempty"`    // Unfreeze after this many frames
+
+		// Bounded modes: min, max, range, increment-only, decrement-only
+		Mode string   `json:"mode,omitempty"`
+		Min  *float64 `json:"min,omitempty"`
+		Max  *float64 `json:"max,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -954,7 +1101,31 @@ func (s *Server) handleFreezeProperty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.gameHook.FreezeProperty(name, request.Freeze); err != nil {
+	var err error
+	hasOptions := request.Condition != "" || request.Duration != "" || request.Frames > 0 ||
+		request.Mode != "" || request.Min != nil || request.Max != nil
+	if request.Freeze && hasOptions {
+		options := &mappers.FreezeOptions{
+			Condition: request.Condition,
+			Frames:    request.Frames,
+			Source:    "api",
+			Mode:      mappers.FreezeMode(request.Mode),
+			Min:       request.Min,
+			Max:       request.Max,
+		}
+		if request.Duration != "" {
+			duration, parseErr := time.ParseDuration(request.Duration)
+			if parseErr != nil || duration <= 0 {
+				s.writeError(w, http.StatusBadRequest, "INVALID_DURATION", fmt.Sprintf("invalid duration %q", request.Duration))
+				return
+			}
+			options.Duration = duration
+		}
+		err = s.gameHook.FreezePropertyWithOptions(name, options)
+	} else {
+		err = s.gameHook.FreezeProperty(name, request.Freeze)
+	}
+	if err != nil {
 		s.writeError(w, http.StatusBadRequest, "FREEZE_FAILED", err.Error())
 		return
 	}
@@ -1077,6 +1248,493 @@ func (s *Server) handleBatchPropertyUpdate(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+func (s *Server) handleGetRecordingStatus(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(s.gameHook.GetRecordingStatus())
+}
+
+func (s *Server) handleGetReplayStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.gameHook.GetReplayStatus()
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, "NO_REPLAY", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(status)
+}
+
+func (s *Server) handleStepReplay(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Frames int `json:"frames"`
+	}
+
+	// An empty body steps a single frame
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			s.writeError(w, http.StatusBadRequest, "INVALID_JSON", err.Error())
+			return
+		}
+	}
+
+	if err := s.gameHook.StepReplay(request.Frames); err != nil {
+		s.writeError(w, http.StatusBadRequest, "STEP_FAILED", err.Error())
+		return
+	}
+
+	status, _ := s.gameHook.GetReplayStatus()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"replay":  status,
+	})
+}
+
+func (s *Server) handleGetDriverStatus(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(s.gameHook.GetDriverStatus())
+}
+
+func (s *Server) handleGetEmulatorStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.gameHook.GetEmulatorStatus()
+	if err != nil {
+		s.writeError(w, http.StatusServiceUnavailable, "EMULATOR_UNAVAILABLE", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(status)
+}
+
+func (s *Server) handleControlEmulator(w http.ResponseWriter, r *http.Request) {
+	action := mux.Vars(r)["action"]
+
+	var request struct {
+		Frames int `json:"frames"`
+	}
+
+	// Only frame-advance takes a body; an empty body advances one frame
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			s.writeError(w, http.StatusBadRequest, "INVALID_JSON", err.Error())
+			return
+		}
+	}
+
+	if err := s.gameHook.ControlEmulator(action, request.Frames); err != nil {
+		s.writeError(w, http.StatusBadRequest, "EMULATOR_CONTROL_FAILED", err.Error())
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"action":  action,
+	}
+	if status, err := s.gameHook.GetEmulatorStatus(); err == nil {
+		response["status"] = status
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) handleGetReadPlan(w http.ResponseWriter, r *http.Request) {
+	plan := s.gameHook.GetReadPlan()
+	if plan == nil {
+		s.writeError(w, http.StatusNotFound, "NO_MAPPER", "No mapper currently loaded")
+		return
+	}
+
+	json.NewEncoder(w).Encode(plan)
+}
+
+func (s *Server) handleListSearches(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"searches": s.gameHook.ListSearches(),
+	})
+}
+
+func (s *Server) handleStartSearch(w http.ResponseWriter, r *http.Request) {
+	var options memory.SearchOptions
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+		s.writeError(w, http.StatusBadRequest, "INVALID_JSON", err.Error())
+		return
+	}
+
+	page, err := s.gameHook.StartSearch(options)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "SEARCH_FAILED", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(page)
+}
+
+func (s *Server) handleGetSearch(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	offset, limit := 0, 0
+	if value := r.URL.Query().Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			s.writeError(w, http.StatusBadRequest, "INVALID_OFFSET", "offset must be a non-negative integer")
+			return
+		}
+		offset = parsed
+	}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			s.writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := s.gameHook.GetSearch(id, offset, limit)
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, "SEARCH_NOT_FOUND", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(page)
+}
+
+func (s *Server) handleDeleteSearch(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := s.gameHook.DeleteSearch(id); err != nil {
+		s.writeError(w, http.StatusNotFound, "SEARCH_NOT_FOUND", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"id":      id,
+	})
+}
+
+func (s *Server) handleFilterSearch(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var filter memory.SearchFilter
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+		s.writeError(w, http.StatusBadRequest, "INVALID_JSON", err.Error())
+		return
+	}
+
+	page, err := s.gameHook.FilterSearch(id, filter)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "SEARCH_FILTER_FAILED", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(page)
+}
+
+func (s *Server) handlePromoteSearchCandidate(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var request struct {
+		Address string `json:"address"`
+		Name    string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.writeError(w, http.StatusBadRequest, "INVALID_JSON", err.Error())
+		return
+	}
+
+	address, err := strconv.ParseUint(request.Address, 0, 32)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "INVALID_ADDRESS", "Invalid memory address")
+		return
+	}
+
+	snippet, err := s.gameHook.PromoteSearchCandidate(id, uint32(address), request.Name)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "PROMOTE_FAILED", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"name":    request.Name,
+		"snippet": snippet,
+	})
+}
+
+func (s *Server) handleListSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := s.gameHook.ListSnapshots()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "SNAPSHOT_LIST_FAILED", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"snapshots": snapshots,
+	})
+}
+
+func (s *Server) handleCaptureSnapshot(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Name string `json:"name"`
+	}
+
+	// The name is optional, so an empty body captures under a generated name
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			s.writeError(w, http.StatusBadRequest, "INVALID_JSON", err.Error())
+			return
+		}
+	}
+
+	info, err := s.gameHook.CaptureSnapshot(request.Name)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "SNAPSHOT_FAILED", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"snapshot": info,
+	})
+}
+
+func (s *Server) handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.gameHook.GetSnapshot(mux.Vars(r)["name"])
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, "SNAPSHOT_NOT_FOUND", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+func (s *Server) handleDeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if err := s.gameHook.DeleteSnapshot(name); err != nil {
+		s.writeError(w, http.StatusNotFound, "SNAPSHOT_NOT_FOUND", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"name":    name,
+	})
+}
+
+func (s *Server) handleDiffSnapshots(w http.ResponseWriter, r *http.Request) {
+	diff, err := s.gameHook.DiffSnapshots(mux.Vars(r)["name"], r.URL.Query().Get("to"))
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, "SNAPSHOT_NOT_FOUND", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(diff)
+}
+
+func (s *Server) handleListWatchpoints(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"watchpoints": s.gameHook.ListWatchpoints(),
+	})
+}
+
+func (s *Server) handleAddWatchpoint(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Domain    string `json:"domain"`
+		Address   string `json:"address"`
+		Length    uint32 `json:"length"`
+		Condition string `json:"condition"`
+		Property  string `json:"property"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.writeError(w, http.StatusBadRequest, "INVALID_JSON", err.Error())
+		return
+	}
+
+	wp := memory.Watchpoint{
+		Domain:    request.Domain,
+		Length:    request.Length,
+		Condition: request.Condition,
+		Property:  request.Property,
+	}
+
+	// A property supplies its own address and length
+	if request.Property == "" {
+		address, err := strconv.ParseUint(request.Address, 0, 32)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "INVALID_ADDRESS", "Invalid memory address")
+			return
+		}
+		wp.Address = uint32(address)
+		if wp.Length == 0 {
+			wp.Length = 1
+		}
+	}
+
+	added, err := s.gameHook.AddWatchpoint(wp)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "WATCHPOINT_FAILED", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"watchpoint": added,
+	})
+}
+
+func (s *Server) handleRemoveWatchpoint(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := s.gameHook.RemoveWatchpoint(id); err != nil {
+		s.writeError(w, http.StatusNotFound, "WATCHPOINT_NOT_FOUND", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"id":      id,
+	})
+}
+
+func (s *Server) handleGetWatchHits(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			s.writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	hits, err := s.gameHook.GetWatchHits(id, limit)
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, "WATCHPOINT_NOT_FOUND", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":   id,
+		"hits": hits,
+	})
+}
+
+func (s *Server) handleGetMemoryHeatmap(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	bucketSize := uint64(16)
+	if value := query.Get("bucket"); value != "" {
+		parsed, err := strconv.ParseUint(value, 0, 32)
+		if err != nil || parsed == 0 {
+			s.writeError(w, http.StatusBadRequest, "INVALID_BUCKET", "bucket must be a positive number of bytes")
+			return
+		}
+		bucketSize = parsed
+	}
+
+	minChanges := uint64(0)
+	if value := query.Get("min_changes"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "INVALID_MIN_CHANGES", "min_changes must be a non-negative integer")
+			return
+		}
+		minChanges = parsed
+	}
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			s.writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	heatmap, err := s.gameHook.GetMemoryHeatmap(query.Get("domain"), uint32(bucketSize))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "HEATMAP_FAILED", err.Error())
+		return
+	}
+
+	// Unmapped buckets that change often are the best leads for new properties
+	unmapped := query.Get("unmapped") == "true"
+	buckets := heatmap.Buckets[:0]
+	for _, bucket := range heatmap.Buckets {
+		if bucket.Changes < minChanges || (unmapped && len(bucket.Properties) > 0) {
+			continue
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	if query.Get("sort") == "changes" {
+		sort.SliceStable(buckets, func(i, j int) bool { return buckets[i].Changes > buckets[j].Changes })
+	}
+	if limit > 0 && len(buckets) > limit {
+		buckets = buckets[:limit]
+	}
+	heatmap.Buckets = buckets
+
+	json.NewEncoder(w).Encode(heatmap)
+}
+
+func (s *Server) handleResetMemoryHeatmap(w http.ResponseWriter, r *http.Request) {
+	s.gameHook.ResetMemoryHeatmap()
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+func (s *Server) handleGetPropertyHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	now := time.Now()
+
+	to, err := parseHistoryTime(query.Get("to"), now, now)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "INVALID_TO", err.Error())
+		return
+	}
+	from, err := parseHistoryTime(query.Get("from"), to.Add(-time.Hour), now)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "INVALID_FROM", err.Error())
+		return
+	}
+
+	step := time.Duration(0)
+	if value := query.Get("step"); value != "" {
+		if step, err = time.ParseDuration(value); err != nil || step <= 0 {
+			s.writeError(w, http.StatusBadRequest, "INVALID_STEP", "step must be a positive duration like 1s or 5m")
+			return
+		}
+	}
+
+	history, err := s.gameHook.GetPropertyHistory(query.Get("mapper"), mux.Vars(r)["name"], from, to, step)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "HISTORY_QUERY_FAILED", err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(history)
+}
+
+// parseHistoryTime parses a history query bound given as RFC 3339, unix
+// seconds or a duration relative to now like -15m. An empty value gives fallback.
+func parseHistoryTime(value string, fallback, now time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	if offset, err := time.ParseDuration(value); err == nil {
+		return now.Add(offset), nil
+	}
+	return time.Time{}, fmt.Errorf("time %q must be RFC 3339, unix seconds or a duration like -15m", value)
+}
+
 // Legacy handlers (enhanced)
 
 func (s *Server) handleListMappers(w http.ResponseWriter, r *http.Request) {
@@ -1110,6 +1768,10 @@ func (s *Server) handleLoadMapper(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *Server) handleGetDetection(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(s.gameHook.GetDetection())
+}
+
 func (s *Server) handleGetCurrentMapper(w http.ResponseWriter, r *http.Request) {
 	mapper := s.gameHook.GetCurrentMapper()
 	if mapper == nil {
@@ -1142,9 +1804,9 @@ func (s *Server) handleListProperties(w http.ResponseWriter, r *http.Request) {
 			Name:        name,
 			Value:       value,
 			Type:        string(prop.Type),
-			Address:     fmt.Sprintf("0x%X", prop.Address),
+			Address:     memory.FormatAddress(prop.Domain, prop.Address),
 			Description: prop.Description,
-			Frozen:      prop.Frozen,
+			Frozen:      mapper.IsFrozen(name),
 			ReadOnly:    prop.ReadOnly,
 		}
 
@@ -1163,11 +1825,16 @@ func (s *Server) handleListProperties(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		if bank := s.gameHook.GetPropertyBankStatus(name); bank != nil {
+			propResponse.Bank = bank
+			propResponse.Stale = bank.Stale
+		}
+
 		if prop.Validation != nil {
 			propResponse.Validation = prop.Validation
 		}
 
-		if prop.Frozen {
+		if propResponse.Frozen {
 			frozenCount++
 		}
 
@@ -1213,6 +1880,11 @@ func (s *Server) handleGetProperty(w http.ResponseWriter, r *http.Request) {
 		response["state"] = state
 	}
 
+	if bank := s.gameHook.GetPropertyBankStatus(name); bank != nil {
+		response["bank"] = bank
+		response["stale"] = bank.Stale
+	}
+
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -1244,6 +1916,7 @@ func (s *Server) handleSetProperty(w http.ResponseWriter, r *http.Request) {
 		"type":      "property_changed",
 		"property":  name,
 		"value":     request.Value,
+		"sequence":  s.gameHook.GetSnapshotSequence(),
 		"timestamp": time.Now(),
 		"source":    "api_legacy",
 	})
@@ -1281,13 +1954,19 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	defer conn.Close()
 
 	// Register client
+	s.clientsMu.Lock()
 	s.clients[conn] = true
-	defer delete(s.clients, conn)
+	s.clientsMu.Unlock()
+	defer func() {
+		s.clientsMu.Lock()
+		delete(s.clients, conn)
+		s.clientsMu.Unlock()
+	}()
 
 	log.Printf("Enhanced WebSocket client connected")
 
 	// Send welcome message with capabilities
-	conn.WriteJSON(map[string]interface{}{
+	s.sendToClient(conn, map[string]interface{}{
 		"type":    "connected",
 		"message": "Enhanced WebSocket connection established",
 		"features": []string{
@@ -1299,8 +1978,9 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			"references",
 			"ui_hints",
 		},
-		"update_rate": "60fps",
-		"timestamp":   time.Now(),
+		"update_rate":   "60fps",
+		"driver_status": s.gameHook.GetDriverStatus(),
+		"timestamp":     time.Now(),
 	})
 
 	// Listen for client messages
@@ -1332,7 +2012,7 @@ func (s *Server) handleWebSocketMessage(conn *websocket.Conn, message map[string
 	case "subscribe_property":
 		// Subscribe to specific property changes
 		if propertyName, ok := message["property"].(string); ok {
-			conn.WriteJSON(map[string]interface{}{
+			s.sendToClient(conn, map[string]interface{}{
 				"type":      "subscription_confirmed",
 				"property":  propertyName,
 				"timestamp": time.Now(),
@@ -1340,7 +2020,7 @@ func (s *Server) handleWebSocketMessage(conn *websocket.Conn, message map[string
 		}
 
 	case "subscribe_events":
-		conn.WriteJSON(map[string]interface{}{
+		s.sendToClient(conn, map[string]interface{}{
 			"type":      "event_subscription_confirmed",
 			"timestamp": time.Now(),
 		})
@@ -1350,7 +2030,7 @@ func (s *Server) handleWebSocketMessage(conn *websocket.Conn, message map[string
 			mapper := s.gameHook.GetCurrentMapperFull()
 			if mapper != nil && mapper.Properties[propertyName] != nil {
 				prop := mapper.Properties[propertyName]
-				conn.WriteJSON(map[string]interface{}{
+				s.sendToClient(conn, map[string]interface{}{
 					"type":       "property_metadata",
 					"property":   propertyName,
 					"ui_hints":   prop.UIHints,
@@ -1365,14 +2045,14 @@ func (s *Server) handleWebSocketMessage(conn *websocket.Conn, message map[string
 		if eventName, ok := message["event"].(string); ok {
 			force, _ := message["force"].(bool)
 			if err := s.gameHook.TriggerEvent(eventName, force); err == nil {
-				conn.WriteJSON(map[string]interface{}{
+				s.sendToClient(conn, map[string]interface{}{
 					"type":      "event_triggered",
 					"event":     eventName,
 					"success":   true,
 					"timestamp": time.Now(),
 				})
 			} else {
-				conn.WriteJSON(map[string]interface{}{
+				s.sendToClient(conn, map[string]interface{}{
 					"type":      "event_trigger_failed",
 					"event":     eventName,
 					"error":     err.Error(),
@@ -1384,7 +2064,7 @@ func (s *Server) handleWebSocketMessage(conn *websocket.Conn, message map[string
 	case "get_ui_layout":
 		mapper := s.gameHook.GetCurrentMapperFull()
 		if mapper != nil {
-			conn.WriteJSON(map[string]interface{}{
+			s.sendToClient(conn, map[string]interface{}{
 				"type":       "ui_layout",
 				"groups":     mapper.Groups,
 				"computed":   mapper.Computed,
@@ -1397,7 +2077,7 @@ func (s *Server) handleWebSocketMessage(conn *websocket.Conn, message map[string
 		// Get current state of a property
 		if propertyName, ok := message["property"].(string); ok {
 			state := s.gameHook.GetPropertyState(propertyName)
-			conn.WriteJSON(map[string]interface{}{
+			s.sendToClient(conn, map[string]interface{}{
 				"type":      "property_state",
 				"property":  propertyName,
 				"state":     state,
@@ -1405,9 +2085,45 @@ func (s *Server) handleWebSocketMessage(conn *websocket.Conn, message map[string
 			})
 		}
 
+	case "get_driver_status":
+		s.sendToClient(conn, map[string]interface{}{
+			"type":      "driver_status",
+			"status":    s.gameHook.GetDriverStatus(),
+			"timestamp": time.Now(),
+		})
+
+	case "get_emulator_status":
+		status, err := s.gameHook.GetEmulatorStatus()
+		response := map[string]interface{}{
+			"type":      "emulator_status",
+			"status":    status,
+			"timestamp": time.Now(),
+		}
+		if err != nil {
+			response["error"] = err.Error()
+		}
+		s.sendToClient(conn, response)
+
+	case "emulator_control":
+		// {"type": "emulator_control", "action": "frame-advance", "frames": 3}
+		if action, ok := message["action"].(string); ok {
+			frames := 0
+			if f, ok := message["frames"].(float64); ok {
+				frames = int(f)
+			}
+			if err := s.gameHook.ControlEmulator(action, frames); err != nil {
+				s.sendToClient(conn, map[string]interface{}{
+					"type":      "emulator_control_failed",
+					"action":    action,
+					"error":     err.Error(),
+					"timestamp": time.Now(),
+				})
+			}
+		}
+
 	case "ping":
 		// Respond to ping
-		conn.WriteJSON(map[string]interface{}{
+		s.sendToClient(conn, map[string]interface{}{
 			"type":      "pong",
 			"timestamp": time.Now(),
 		})
@@ -1427,7 +2143,23 @@ func (s *Server) writeError(w http.ResponseWriter, status int, errorType, messag
 	})
 }
 
+// Broadcast sends a message to every connected WebSocket client. It is safe
+// to call from outside the server's goroutines.
+func (s *Server) Broadcast(message interface{}) {
+	s.broadcastMessage(message)
+}
+
+// sendToClient writes a message to a single WebSocket client
+func (s *Server) sendToClient(conn *websocket.Conn, message interface{}) error {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	return conn.WriteJSON(message)
+}
+
 func (s *Server) broadcastMessage(message interface{}) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
 	for client := range s.clients {
 		err := client.WriteJSON(message)
 		if err != nil {
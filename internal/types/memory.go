@@ -2,7 +2,15 @@ package types
 
 // MemoryBlock represents a contiguous block of memory
 type MemoryBlock struct {
-	Name  string
-	Start uint32
-	End   uint32
+	Name   string
+	Domain string // Named memory domain such as "sram"; empty for the system bus
+	Start  uint32 // Start address within Domain
+	End    uint32
+
+	Unwatchable bool // Mapper set watchable: false, so watchpoints may not cover the block
+
+	// Set when the block is a planned range inside a declared block, so
+	// drivers that load whole blocks can find the one it belongs to
+	Parent      string
+	ParentStart uint32
 }
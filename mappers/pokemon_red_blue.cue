@@ -21,6 +21,12 @@ metadata: {
     revision: "1.0"
 }
 
+// Used to select this mapper automatically when the game is running
+identification: {
+    crc32: ["9f7fdd53", "d6da8a1a"] // Red (UE), Blue (UE)
+    titles: ["POKEMON RED", "POKEMON BLUE"]
+}
+
 // ===== PLATFORM CONFIGURATION =====
 platform: {
     name: "Game Boy"